@@ -1,16 +1,15 @@
 package main
 
 import (
-	"io"
 	"log"
 	"net/http"
 
-	"github.com/jsightapi/jsight-schema-core/fs"
-
 	"github.com/jsightapi/jsight-api-core/kit"
 )
 
 func convertJSight(w http.ResponseWriter, r *http.Request) {
+	limitProjectBody(w, r)
+
 	to := r.FormValue("to")
 	format := r.FormValue("format")
 	log.Printf("%s %s %s %s", r.Method, r.URL.Path, to, format)
@@ -29,7 +28,7 @@ func convertJSight(w http.ResponseWriter, r *http.Request) {
 		return
 
 	default:
-		wr.errorStr("HTTP POST request required")
+		wr.errorStr(errorCodePOSTRequired, "HTTP POST request required")
 		return
 	}
 }
@@ -38,21 +37,25 @@ func convertJSightPOST(wr httpResponseWriter, r *http.Request) {
 	to := r.FormValue("to")
 	format := r.FormValue("format")
 
-	body, err := io.ReadAll(r.Body)
+	p, err := readProject(wr.writer, r)
 	if err != nil {
 		wr.error(err)
 		return
 	}
 
-	jAPI, jErr := kit.NewJApiFromFile(fs.NewFile("root", body))
+	jAPI, jErr := p.build()
 
 	if getBoolEnv("JSIGHT_SERVER_STATISTICS") {
 		clientID := r.Header.Get("X-Browser-UUID")
 		clientIP := getIP(r)
-		sendDatagram(clientID, clientIP, len(body), jAPI, jErr)
+		sendDatagram(clientID, clientIP, p.size(), jAPI, jErr)
 	}
 
 	if jErr != nil {
+		if r.FormValue("errors") == "all" {
+			wr.error(p.collectErrors(jErr))
+			return
+		}
 		wr.error(jErr)
 		return
 	}
@@ -64,23 +67,107 @@ func convertJSightPOST(wr httpResponseWriter, r *http.Request) {
 			writeJDocJSON(wr, jAPI)
 			return
 		default:
-			wr.errorStr("not supported format")
+			wr.errorStr(errorCodeUnsupportedFormat, "not supported format")
 			return
 		}
 	case "openapi-3.0.3":
+		o, err := newOpenapiOptions(r)
+		if err != nil {
+			wr.error(err)
+			return
+		}
+		switch format {
+		case "json", "":
+			writeOpenapiJSON(wr, jAPI, o)
+			return
+		case "yaml":
+			writeOpenapiYAML(wr, jAPI, o)
+			return
+		default:
+			wr.errorStr(errorCodeUnsupportedFormat, "not supported format")
+			return
+		}
+	case "openapi-3.1.0":
+		o, err := newOpenapiOptions(r)
+		if err != nil {
+			wr.error(err)
+			return
+		}
+		switch format {
+		case "json", "":
+			writeOpenapi31JSON(wr, jAPI, o)
+			return
+		case "yaml":
+			writeOpenapi31YAML(wr, jAPI, o)
+			return
+		default:
+			wr.errorStr(errorCodeUnsupportedFormat, "not supported format")
+			return
+		}
+	case "openrpc-1.2":
+		switch format {
+		case "json", "":
+			writeOpenrpcJSON(wr, jAPI)
+			return
+		case "yaml":
+			writeOpenrpcYAML(wr, jAPI)
+			return
+		default:
+			wr.errorStr(errorCodeUnsupportedFormat, "not supported format")
+			return
+		}
+	case "json-schema":
+		bodies := r.FormValue("bodies") == "true"
 		switch format {
 		case "json", "":
-			writeOpenapiJSON(wr, jAPI)
+			writeJSONSchemaJSON(wr, jAPI, bodies)
 			return
 		case "yaml":
-			writeOpenapiYAML(wr, jAPI)
+			writeJSONSchemaYAML(wr, jAPI, bodies)
 			return
 		default:
-			wr.errorStr("not supported format")
+			wr.errorStr(errorCodeUnsupportedFormat, "not supported format")
+			return
+		}
+	case "typescript":
+		client := r.FormValue("client") == "true"
+		switch format {
+		case "ts", "":
+			writeTypeScript(wr, jAPI, client)
+			return
+		default:
+			wr.errorStr(errorCodeUnsupportedFormat, "not supported format")
+			return
+		}
+	case "go":
+		switch format {
+		case "zip", "":
+			writeGoServerZip(wr, jAPI, r.FormValue("package"))
+			return
+		default:
+			wr.errorStr(errorCodeUnsupportedFormat, "not supported format")
+			return
+		}
+	case "html":
+		switch format {
+		case "html", "":
+			writeHTMLDoc(wr, jAPI)
+			return
+		default:
+			wr.errorStr(errorCodeUnsupportedFormat, "not supported format")
+			return
+		}
+	case "markdown":
+		switch format {
+		case "md", "":
+			writeMarkdownDoc(wr, jAPI)
+			return
+		default:
+			wr.errorStr(errorCodeUnsupportedFormat, "not supported format")
 			return
 		}
 	default:
-		wr.errorStr(`you must specify the "to" parameter`)
+		wr.errorStr(errorCodeParameterRequired, `you must specify the "to" parameter`)
 		return
 	}
 }
@@ -95,8 +182,68 @@ func writeJDocJSON(wr httpResponseWriter, jAPI kit.JApi) {
 	wr.jdocJSON(resp)
 }
 
-func writeOpenapiJSON(wr httpResponseWriter, jAPI kit.JApi) {
-	resp, err := openapiJSON(jAPI)
+func writeOpenapiJSON(wr httpResponseWriter, jAPI kit.JApi, o openapiOptions) {
+	resp, err := openapiJSON(jAPI, o)
+	if err != nil {
+		wr.error(err)
+		return
+	}
+
+	wr.json(resp)
+}
+
+func writeOpenapiYAML(wr httpResponseWriter, jAPI kit.JApi, o openapiOptions) {
+	resp, err := openapiYAML(jAPI, o)
+	if err != nil {
+		wr.error(err)
+		return
+	}
+
+	wr.yaml(resp)
+}
+
+func writeOpenapi31JSON(wr httpResponseWriter, jAPI kit.JApi, o openapiOptions) {
+	resp, err := openapi31JSON(jAPI, o)
+	if err != nil {
+		wr.error(err)
+		return
+	}
+
+	wr.json(resp)
+}
+
+func writeOpenapi31YAML(wr httpResponseWriter, jAPI kit.JApi, o openapiOptions) {
+	resp, err := openapi31YAML(jAPI, o)
+	if err != nil {
+		wr.error(err)
+		return
+	}
+
+	wr.yaml(resp)
+}
+
+func writeOpenrpcJSON(wr httpResponseWriter, jAPI kit.JApi) {
+	resp, err := openrpcJSON(jAPI)
+	if err != nil {
+		wr.error(err)
+		return
+	}
+
+	wr.json(resp)
+}
+
+func writeOpenrpcYAML(wr httpResponseWriter, jAPI kit.JApi) {
+	resp, err := openrpcYAML(jAPI)
+	if err != nil {
+		wr.error(err)
+		return
+	}
+
+	wr.yaml(resp)
+}
+
+func writeJSONSchemaJSON(wr httpResponseWriter, jAPI kit.JApi, bodies bool) {
+	resp, err := jsonSchemaJSON(jAPI, bodies)
 	if err != nil {
 		wr.error(err)
 		return
@@ -105,8 +252,8 @@ func writeOpenapiJSON(wr httpResponseWriter, jAPI kit.JApi) {
 	wr.json(resp)
 }
 
-func writeOpenapiYAML(wr httpResponseWriter, jAPI kit.JApi) {
-	resp, err := openapiYAML(jAPI)
+func writeJSONSchemaYAML(wr httpResponseWriter, jAPI kit.JApi, bodies bool) {
+	resp, err := jsonSchemaYAML(jAPI, bodies)
 	if err != nil {
 		wr.error(err)
 		return
@@ -114,3 +261,43 @@ func writeOpenapiYAML(wr httpResponseWriter, jAPI kit.JApi) {
 
 	wr.yaml(resp)
 }
+
+func writeTypeScript(wr httpResponseWriter, jAPI kit.JApi, client bool) {
+	resp, err := typescriptCode(jAPI, client)
+	if err != nil {
+		wr.error(err)
+		return
+	}
+
+	wr.typescript(resp)
+}
+
+func writeGoServerZip(wr httpResponseWriter, jAPI kit.JApi, pkg string) {
+	resp, err := goServerZip(jAPI, pkg)
+	if err != nil {
+		wr.error(err)
+		return
+	}
+
+	wr.zip(resp)
+}
+
+func writeHTMLDoc(wr httpResponseWriter, jAPI kit.JApi) {
+	resp, err := htmlDoc(jAPI)
+	if err != nil {
+		wr.error(err)
+		return
+	}
+
+	wr.html(resp)
+}
+
+func writeMarkdownDoc(wr httpResponseWriter, jAPI kit.JApi) {
+	resp, err := markdownDoc(jAPI)
+	if err != nil {
+		wr.error(err)
+		return
+	}
+
+	wr.markdown(resp)
+}
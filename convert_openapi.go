@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+)
+
+type convertOpenAPIResponse struct {
+	// JSight the JSight code of the API.
+	JSight string `json:"jsight"`
+
+	// Warnings constructs of the OpenAPI document which are skipped or changed,
+	// because they can't be expressed in JSight.
+	Warnings []importWarning `json:"warnings"`
+}
+
+func convertOpenAPI(w http.ResponseWriter, r *http.Request) {
+	to := r.FormValue("to")
+	log.Printf("%s %s %s", r.Method, r.URL.Path, to)
+
+	if getBoolEnv("JSIGHT_SERVER_CORS") {
+		cors(w)
+	}
+
+	wr := httpResponseWriter{writer: w}
+
+	switch r.Method {
+	case http.MethodOptions:
+
+	case http.MethodPost:
+		convertOpenAPIPOST(wr, r)
+		return
+
+	default:
+		wr.errorStr(errorCodePOSTRequired, "HTTP POST request required")
+		return
+	}
+}
+
+func convertOpenAPIPOST(wr httpResponseWriter, r *http.Request) {
+	if to := r.FormValue("to"); to != "jsight" {
+		wr.errorStr(errorCodeParameterRequired, `you must specify the "to" parameter`)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxProjectSize+1))
+	if err != nil {
+		wr.error(err)
+		return
+	}
+	if len(body) > maxProjectSize {
+		wr.error(newRequestError(errorCodeTooLarge, "the OpenAPI document size exceeds %d bytes", maxProjectSize))
+		return
+	}
+
+	code, warnings, err := importOpenAPI(body)
+	if err != nil {
+		wr.error(err)
+		return
+	}
+
+	if warnings == nil {
+		warnings = []importWarning{}
+	}
+
+	b, err := json.Marshal(convertOpenAPIResponse{JSight: code, Warnings: warnings})
+	if err != nil {
+		wr.internalServerError(err)
+		return
+	}
+
+	wr.json(b)
+}
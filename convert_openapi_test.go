@@ -0,0 +1,84 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_convertOpenAPI(t *testing.T) {
+	cc := map[string]testCase{
+		http.MethodOptions: {
+			func(t *testing.T) *http.Request {
+				r, err := http.NewRequest(http.MethodOptions, "/?to=jsight", http.NoBody)
+				require.NoError(t, err)
+				return r
+			},
+			func(t *testing.T, r *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, r.Code)
+			},
+		},
+
+		"POST": {
+			newConvertOpenAPIRequest("/?to=jsight", "openapi: 3.0.3\ninfo:\n  title: Cats\n"),
+			func(t *testing.T, r *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, r.Code)
+				assert.Equal(t, "application/json; charset=utf-8", r.Header().Get("Content-Type"))
+				assert.JSONEq(t, `{"jsight": "JSIGHT 0.3\n\nINFO\n  Title \"Cats\"\n", "warnings": []}`, r.Body.String())
+			},
+		},
+
+		"POST, with warnings": {
+			newConvertOpenAPIRequest("/?to=jsight", `{"openapi": "3.0.3", "security": []}`),
+			func(t *testing.T, r *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, r.Code)
+				assert.JSONEq(t, `{
+					"jsight": "JSIGHT 0.3\n",
+					"warnings": [{"pointer": "/security", "message": "the \"security\" field is not supported and is skipped"}]
+				}`, r.Body.String())
+			},
+		},
+
+		"POST, without the target": {
+			newConvertOpenAPIRequest("/", "openapi: 3.0.3"),
+			func(t *testing.T, r *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, r.Code)
+				assert.Equal(t, `{"Status":"Error","Message":"you must specify the \"to\" parameter","Line":0,"Index":0,"Code":30002,"Layer":"server"}`, r.Body.String())
+			},
+		},
+
+		"POST, invalid document": {
+			newConvertOpenAPIRequest("/?to=jsight", `swagger: "2.0"`),
+			func(t *testing.T, r *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, r.Code)
+				assert.Equal(t, `{"Status":"Error","Message":"only OpenAPI 3.0 documents are supported","Line":0,"Index":0,"Code":30015,"Layer":"server"}`, r.Body.String())
+			},
+		},
+
+		http.MethodGet: {
+			func(t *testing.T) *http.Request {
+				r, err := http.NewRequest(http.MethodGet, "/?to=jsight", http.NoBody)
+				require.NoError(t, err)
+				return r
+			},
+			func(t *testing.T, r *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, r.Code)
+				assert.Equal(t, `{"Status":"Error","Message":"HTTP POST request required","Line":0,"Index":0,"Code":30001,"Layer":"server"}`, r.Body.String())
+			},
+		},
+	}
+
+	assertHandler(t, convertOpenAPI, cc)
+}
+
+func newConvertOpenAPIRequest(url, body string) func(*testing.T) *http.Request {
+	return func(t *testing.T) *http.Request {
+		r, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+		require.NoError(t, err)
+		return r
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/jsightapi/jsight-api-core/kit"
+)
+
+// diffJSightRequest is a body of the /diff-jsight request.
+type diffJSightRequest struct {
+	// Old the JSight code of the previous version of the API.
+	Old string `json:"old"`
+
+	// New the JSight code of the next version of the API.
+	New string `json:"new"`
+}
+
+type diffJSightResponse struct {
+	// Breaking is true if at least one change can break existing clients.
+	Breaking bool `json:"breaking"`
+
+	Changes []specChange `json:"changes"`
+
+	// Markdown a human-readable summary of the changes.
+	Markdown string `json:"markdown"`
+}
+
+func diffJSight(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s", r.Method, r.URL.Path)
+
+	if getBoolEnv("JSIGHT_SERVER_CORS") {
+		cors(w)
+	}
+
+	wr := httpResponseWriter{writer: w}
+
+	switch r.Method {
+	case http.MethodOptions:
+
+	case http.MethodPost:
+		diffJSightPOST(wr, r)
+		return
+
+	default:
+		wr.errorStr(errorCodePOSTRequired, "HTTP POST request required")
+		return
+	}
+}
+
+func diffJSightPOST(wr httpResponseWriter, r *http.Request) {
+	var req diffJSightRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, 2*maxProjectSize)).Decode(&req); err != nil {
+		wr.error(newRequestError(errorCodeInvalidRequest, "invalid request: %w", err))
+		return
+	}
+
+	oldAPI, jErr := newSingleFileProject([]byte(req.Old)).build()
+	if jErr != nil {
+		wr.error(fmt.Errorf("old: %w", jErr))
+		return
+	}
+
+	newAPI, jErr := newSingleFileProject([]byte(req.New)).build()
+	if jErr != nil {
+		wr.error(fmt.Errorf("new: %w", jErr))
+		return
+	}
+
+	res, err := newDiffJSightResponse(oldAPI, newAPI)
+	if err != nil {
+		wr.error(err)
+		return
+	}
+
+	b, err := json.Marshal(res)
+	if err != nil {
+		wr.internalServerError(err)
+		return
+	}
+
+	wr.json(b)
+}
+
+func newDiffJSightResponse(oldAPI, newAPI kit.JApi) (diffJSightResponse, error) {
+	cc, err := diffCatalogs(oldAPI.Catalog(), newAPI.Catalog())
+	if err != nil {
+		return diffJSightResponse{}, err
+	}
+
+	res := diffJSightResponse{
+		Changes:  []specChange{},
+		Markdown: specChangesMarkdown(cc),
+	}
+	for _, c := range cc {
+		res.Changes = append(res.Changes, c)
+		res.Breaking = res.Breaking || c.Breaking
+	}
+	return res, nil
+}
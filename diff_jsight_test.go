@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_diffJSight(t *testing.T) {
+	cc := map[string]testCase{
+		http.MethodOptions: {
+			func(t *testing.T) *http.Request {
+				r, err := http.NewRequest(http.MethodOptions, "/", http.NoBody)
+				require.NoError(t, err)
+				return r
+			},
+			func(t *testing.T, r *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, r.Code)
+			},
+		},
+
+		"POST, breaking changes": {
+			newDiffJSightRequest(diffJSightRequest{
+				Old: "JSIGHT 0.3\n\nGET /cats\n  200 any\n\nGET /dogs\n  200 any\n",
+				New: "JSIGHT 0.3\n\nGET /cats\n  200 any\n\nGET /birds\n  200 any\n",
+			}),
+			func(t *testing.T, r *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, r.Code)
+				assert.Equal(t, "application/json; charset=utf-8", r.Header().Get("Content-Type"))
+				assert.JSONEq(t, `{
+					"breaking": true,
+					"changes": [
+						{"interaction": "http GET /dogs", "kind": "removed", "breaking": true, "message": "the interaction is removed"},
+						{"interaction": "http GET /birds", "kind": "added", "breaking": false, "message": "the interaction is added"}
+					],
+					"markdown": "# API changes\n\nBreaking changes: 1, non-breaking changes: 1.\n\n## Breaking changes\n\n- `+"`http GET /dogs`"+`: the interaction is removed.\n\n## Non-breaking changes\n\n- `+"`http GET /birds`"+`: the interaction is added.\n"
+				}`, r.Body.String())
+			},
+		},
+
+		"POST, no changes": {
+			newDiffJSightRequest(diffJSightRequest{
+				Old: "JSIGHT 0.3\n\nGET /cats\n  200 any\n",
+				New: "JSIGHT 0.3\n\nGET /cats\n  200 any\n",
+			}),
+			func(t *testing.T, r *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, r.Code)
+				assert.Equal(t, `{"breaking":false,"changes":[],"markdown":"# API changes\n\nNo changes.\n"}`, r.Body.String())
+			},
+		},
+
+		"POST, invalid new JSight": {
+			newDiffJSightRequest(diffJSightRequest{
+				Old: "JSIGHT 0.3\n",
+				New: "JSIGHT 0.3\n\nGET /cats\n  200 @cat\n",
+			}),
+			func(t *testing.T, r *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, r.Code)
+				assert.Equal(t, `{"Status":"Error","Message":"new: Type \"@cat\" not found","Line":4,"Index":24,"Code":1302,"Layer":"schema","File":"root","Column":3}`, r.Body.String())
+			},
+		},
+
+		"POST, invalid request": {
+			func(t *testing.T) *http.Request {
+				r, err := http.NewRequest(http.MethodPost, "/", strings.NewReader(`JSIGHT 0.3`))
+				require.NoError(t, err)
+				return r
+			},
+			func(t *testing.T, r *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, r.Code)
+				assert.Equal(t, `{"Status":"Error","Message":"invalid request: invalid character 'J' looking for beginning of value","Line":0,"Index":0,"Code":30005,"Layer":"server"}`, r.Body.String())
+			},
+		},
+	}
+
+	appendUnhandledMethod(cc)
+	assertHandler(t, diffJSight, cc)
+}
+
+func newDiffJSightRequest(req diffJSightRequest) func(*testing.T) *http.Request {
+	return func(t *testing.T) *http.Request {
+		b, err := json.Marshal(req)
+		require.NoError(t, err)
+
+		r, err := http.NewRequest(http.MethodPost, "/", strings.NewReader(string(b)))
+		require.NoError(t, err)
+		return r
+	}
+}
@@ -0,0 +1,528 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	schema "github.com/jsightapi/jsight-schema-core"
+	"github.com/jsightapi/jsight-schema-core/panics"
+
+	"github.com/jsightapi/jsight-api-core/catalog"
+	"github.com/jsightapi/jsight-api-core/notation"
+)
+
+// docPage is the documentation of the API rendered into HTML and Markdown.
+// Interactions are grouped by tags. An interaction of several tags is described in the first
+// one and is referred to from the others.
+type docPage struct {
+	Title       string
+	Version     string
+	Description string
+	Servers     []docServer
+	Tags        []*docTag
+	Types       []docUserType
+	Enums       []docUserType
+}
+
+type docServer struct {
+	Name       string
+	BaseURL    string
+	Annotation string
+}
+
+type docTag struct {
+	Anchor       string
+	Title        string
+	Description  string
+	Interactions []*docInteraction
+	Children     []*docTag
+}
+
+type docInteraction struct {
+	Anchor      string
+	Method      string
+	Path        string
+	Annotation  string
+	Description string
+
+	// Ref is true if the interaction is described in another tag.
+	Ref bool
+
+	Parts     []docSchema
+	Responses []docResponse
+}
+
+type docResponse struct {
+	Code       string
+	Annotation string
+	Parts      []docSchema
+}
+
+// docSchema is a part of the interaction, e.g. "Query" or "Body".
+type docSchema struct {
+	Title    string
+	Notation notation.SchemaNotation
+
+	// Code the schema in its notation: JSight, a regular expression, "any"
+	// or "empty".
+	Code string
+
+	// Example an example of the payload. It's empty for "any" and "empty".
+	Example string
+}
+
+type docUserType struct {
+	Anchor      string
+	Name        string
+	Description string
+	Schema      docSchema
+}
+
+func newDocPage(c *catalog.Catalog) (page docPage, err error) {
+	defer func() {
+		err = panics.Handle(recover(), err)
+	}()
+
+	b := docBuilder{
+		catalog:   c,
+		anchors:   map[string]int{},
+		described: map[string]*docInteraction{},
+	}
+
+	page.Title = "API"
+	if c.Info != nil {
+		if c.Info.Title != "" {
+			page.Title = c.Info.Title
+		}
+		page.Version = c.Info.Version
+		if c.Info.Description != nil {
+			page.Description = *c.Info.Description
+		}
+	}
+
+	c.Servers.EachSafe(func(k string, v *catalog.Server) {
+		page.Servers = append(page.Servers, docServer{Name: k, BaseURL: v.BaseUrl, Annotation: v.Annotation})
+	})
+
+	if page.Tags, err = b.tags(c.Tags); err != nil {
+		return docPage{}, err
+	}
+
+	err = c.UserTypes.Each(func(k string, v *catalog.UserType) error {
+		s, err := newDocSchema("", v.Schema)
+		if err != nil {
+			return fmt.Errorf("type %q: %w", k, err)
+		}
+
+		page.Types = append(page.Types, docUserType{
+			Anchor:      b.anchor("type", k),
+			Name:        k,
+			Description: description(v.Description, v.Annotation),
+			Schema:      s,
+		})
+		return nil
+	})
+	if err != nil {
+		return docPage{}, err
+	}
+
+	c.UserEnums.EachSafe(func(k string, v *catalog.UserRule) {
+		page.Enums = append(page.Enums, docUserType{
+			Anchor:      b.anchor("enum", k),
+			Name:        k,
+			Description: description(v.Description, v.Annotation),
+			Schema:      docSchema{Notation: notation.SchemaNotationJSight, Code: jsightRuleCode(v.Value)},
+		})
+	})
+
+	return page, nil
+}
+
+type docBuilder struct {
+	catalog *catalog.Catalog
+
+	// anchors the number of uses of every anchor, to make them unique.
+	anchors map[string]int
+
+	// described interactions by their IDs.
+	described map[string]*docInteraction
+}
+
+// anchor returns the unique ID of the documentation section.
+func (b docBuilder) anchor(prefix, name string) string {
+	var sb strings.Builder
+	sb.WriteString(prefix)
+	dash := true
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash {
+				sb.WriteByte('-')
+				dash = false
+			}
+			sb.WriteRune(r)
+			continue
+		}
+		dash = true
+	}
+
+	a := sb.String()
+	b.anchors[a]++
+	if n := b.anchors[a]; n > 1 {
+		a += "-" + strconv.Itoa(n)
+	}
+	return a
+}
+
+func (b docBuilder) tags(tt *catalog.Tags) ([]*docTag, error) {
+	var res []*docTag
+	err := tt.Each(func(_ catalog.TagName, t *catalog.Tag) error {
+		ht := &docTag{
+			Anchor: b.anchor("tag", string(t.Name)),
+			Title:  t.Title,
+		}
+		if t.Description != nil {
+			ht.Description = *t.Description
+		}
+
+		// HTTP interactions go first, as in JDoc.
+		for _, p := range []catalog.Protocol{catalog.HTTP, catalog.JsonRpc} {
+			for _, id := range tagInteractionIDs(t.InteractionGroups[p]) {
+				hi, err := b.interaction(id)
+				if err != nil {
+					return err
+				}
+				ht.Interactions = append(ht.Interactions, hi)
+			}
+		}
+
+		var err error
+		if ht.Children, err = b.tags(t.Children); err != nil {
+			return err
+		}
+
+		res = append(res, ht)
+		return nil
+	})
+	return res, err
+}
+
+func tagInteractionIDs(g catalog.TagInteractionGroup) []catalog.InteractionID {
+	switch gg := g.(type) {
+	case *catalog.TagHTTPInteractionGroup:
+		return gg.Interactions
+	case *catalog.TagJsonRpcInteractionGroup:
+		return gg.Interactions
+	default:
+		return nil
+	}
+}
+
+func (b docBuilder) interaction(id catalog.InteractionID) (*docInteraction, error) {
+	if hi, ok := b.described[id.String()]; ok {
+		ref := *hi
+		ref.Ref = true
+		return &ref, nil
+	}
+
+	i, ok := b.catalog.Interactions.Get(id)
+	if !ok {
+		return nil, newRequestError(errorCodeInteractionNotFound, "the interaction %q not found", id.String())
+	}
+
+	var (
+		hi  *docInteraction
+		err error
+	)
+	switch ii := i.(type) {
+	case *catalog.HTTPInteraction:
+		hi, err = newDocHTTPInteraction(ii)
+	case *catalog.JsonRpcInteraction:
+		hi, err = newDocJsonRpcInteraction(ii)
+	default:
+		return nil, fmt.Errorf("unsupported interaction %T", i)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", id.String(), err)
+	}
+
+	hi.Anchor = b.anchor("interaction", id.String())
+	b.described[id.String()] = hi
+	return hi, nil
+}
+
+func newDocHTTPInteraction(i *catalog.HTTPInteraction) (*docInteraction, error) {
+	hi := &docInteraction{
+		Method:      i.HttpMethod.String(),
+		Path:        i.Path().String(),
+		Annotation:  stringValue(i.Annotation),
+		Description: stringValue(i.Description),
+	}
+
+	var pp []docPartSchema
+	if i.PathVariables != nil {
+		pp = append(pp, docPartSchema{"Path variables", jsightSchemaOrNil(i.PathVariables.Schema)})
+	}
+	if i.Query != nil {
+		pp = append(pp, docPartSchema{"Query", jsightSchemaOrNil(i.Query.Schema)})
+	}
+	if i.Request != nil {
+		if i.Request.HTTPRequestHeaders != nil {
+			pp = append(pp, docPartSchema{"Request headers", jsightSchemaOrNil(i.Request.HTTPRequestHeaders.Schema)})
+		}
+		if i.Request.HTTPRequestBody != nil {
+			pp = append(pp, docPartSchema{"Request body", i.Request.HTTPRequestBody.Schema})
+		}
+	}
+
+	var err error
+	if hi.Parts, err = newDocSchemas(pp); err != nil {
+		return nil, err
+	}
+
+	for _, r := range i.Responses {
+		pp = pp[:0]
+		if r.Headers != nil {
+			pp = append(pp, docPartSchema{"Headers", jsightSchemaOrNil(r.Headers.Schema)})
+		}
+		if r.Body != nil {
+			pp = append(pp, docPartSchema{"Body", r.Body.Schema})
+		}
+
+		hr := docResponse{Code: r.Code, Annotation: r.Annotation}
+		if hr.Parts, err = newDocSchemas(pp); err != nil {
+			return nil, fmt.Errorf("response %s: %w", r.Code, err)
+		}
+		hi.Responses = append(hi.Responses, hr)
+	}
+	return hi, nil
+}
+
+func newDocJsonRpcInteraction(i *catalog.JsonRpcInteraction) (*docInteraction, error) {
+	hi := &docInteraction{
+		Method:      "JSON-RPC",
+		Path:        fmt.Sprintf("%s %s", i.Path().String(), i.Method),
+		Annotation:  stringValue(i.Annotation),
+		Description: stringValue(i.Description),
+	}
+
+	var err error
+	hi.Parts, err = newDocSchemas([]docPartSchema{
+		{"Params", jsightSchemaOrNil(rpcParamsSchema(i))},
+		{"Result", jsightSchemaOrNil(rpcResultSchema(i))},
+	})
+	return hi, err
+}
+
+// jsightSchemaOrNil avoids a non-nil interface holding a nil pointer.
+func jsightSchemaOrNil(s *catalog.ExchangeJSightSchema) catalog.ExchangeSchema {
+	if s == nil {
+		return nil
+	}
+	return s
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+type docPartSchema struct {
+	title  string
+	schema catalog.ExchangeSchema
+}
+
+// newDocSchemas returns the described parts. Parts without a schema are
+// skipped.
+func newDocSchemas(pp []docPartSchema) ([]docSchema, error) {
+	var res []docSchema
+	for _, p := range pp {
+		if p.schema == nil {
+			continue
+		}
+
+		s, err := newDocSchema(p.title, p.schema)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", strings.ToLower(p.title), err)
+		}
+		res = append(res, s)
+	}
+	return res, nil
+}
+
+func newDocSchema(title string, es catalog.ExchangeSchema) (docSchema, error) {
+	hs := docSchema{Title: title, Notation: es.Notation()}
+
+	switch s := es.(type) {
+	case *catalog.ExchangeJSightSchema:
+		node, err := s.GetAST()
+		if err != nil {
+			return docSchema{}, err
+		}
+		hs.Code = jsightCode(node)
+
+		e, err := s.Example()
+		if err != nil {
+			return docSchema{}, err
+		}
+
+		var b bytes.Buffer
+		if err := json.Indent(&b, e, "", "  "); err != nil {
+			return docSchema{}, err
+		}
+		hs.Example = b.String()
+
+	case *catalog.ExchangeRegexSchema:
+		pattern, err := s.Pattern()
+		if err != nil {
+			return docSchema{}, err
+		}
+		hs.Code = "/" + pattern + "/"
+
+		e, err := s.Example()
+		if err != nil {
+			return docSchema{}, err
+		}
+		hs.Example = string(e)
+
+	default:
+		hs.Code = string(es.Notation())
+	}
+	return hs, nil
+}
+
+// jsightCode prints the JSight schema of the AST. Rules and comments are
+// printed in annotations at the end of lines.
+func jsightCode(node schema.ASTNode) string {
+	var b strings.Builder
+	writeJSightNode(&b, node, "", false, "")
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// writeJSightNode writes the node and its children. The suffix, e.g. a comma,
+// is written right after the value.
+func writeJSightNode(b *strings.Builder, node schema.ASTNode, indent string, property bool, suffix string) {
+	b.WriteString(indent)
+	if property {
+		if node.IsKeyShortcut {
+			b.WriteString(node.Key)
+		} else {
+			b.WriteString(strconv.Quote(node.Key))
+		}
+		b.WriteString(": ")
+	}
+
+	var open, closing string
+	switch node.TokenType { //nolint:exhaustive // Other types are printed as is.
+	case schema.TokenTypeObject:
+		open, closing = "{", "}"
+	case schema.TokenTypeArray:
+		open, closing = "[", "]"
+	case schema.TokenTypeString:
+		b.WriteString(strconv.Quote(node.Value) + suffix + jsightAnnotation(node) + "\n")
+		return
+	default:
+		b.WriteString(node.Value + suffix + jsightAnnotation(node) + "\n")
+		return
+	}
+
+	if len(node.Children) == 0 {
+		b.WriteString(open + closing + suffix + jsightAnnotation(node) + "\n")
+		return
+	}
+
+	b.WriteString(open + jsightAnnotation(node) + "\n")
+	for i, c := range node.Children {
+		s := ","
+		if i == len(node.Children)-1 {
+			s = ""
+		}
+		writeJSightNode(b, c, indent+"  ", node.TokenType == schema.TokenTypeObject, s)
+	}
+	b.WriteString(indent + closing + suffix + "\n")
+}
+
+// jsightAnnotation returns the annotation of the node with the rules specified
+// by the user and the comment, e.g. ` // {min: 1} - The ID.`.
+func jsightAnnotation(node schema.ASTNode) string {
+	var rr []string
+	if node.Rules != nil {
+		node.Rules.EachSafe(func(k string, v schema.RuleASTNode) {
+			if v.Source != schema.RuleASTNodeSourceGenerated {
+				rr = append(rr, k+": "+jsightRuleString(k, v))
+			}
+		})
+	}
+
+	switch {
+	case len(rr) != 0 && node.Comment != "":
+		return " // {" + strings.Join(rr, ", ") + "} - " + node.Comment
+	case len(rr) != 0:
+		return " // {" + strings.Join(rr, ", ") + "}"
+	case node.Comment != "":
+		return " // " + node.Comment
+	default:
+		return ""
+	}
+}
+
+func jsightRuleString(name string, r schema.RuleASTNode) string {
+	switch r.TokenType { //nolint:exhaustive // Other types are printed as is.
+	case schema.TokenTypeString:
+		return strconv.Quote(r.Value)
+
+	case schema.TokenTypeShortcut:
+		// Enums are referred to without quotes, types are type names in
+		// strings.
+		if name == "enum" {
+			return r.Value
+		}
+		return strconv.Quote(r.Value)
+
+	case schema.TokenTypeArray:
+		ss := make([]string, 0, len(r.Items))
+		for _, i := range r.Items {
+			ss = append(ss, jsightRuleString(name, i))
+		}
+		return "[" + strings.Join(ss, ", ") + "]"
+
+	case schema.TokenTypeObject:
+		var ss []string
+		if r.Properties != nil {
+			r.Properties.EachSafe(func(k string, v schema.RuleASTNode) {
+				ss = append(ss, k+": "+jsightRuleString(k, v))
+			})
+		}
+		return "{" + strings.Join(ss, ", ") + "}"
+
+	default:
+		return r.Value
+	}
+}
+
+// jsightRuleCode prints the value of the user enum.
+func jsightRuleCode(r catalog.Rule) string {
+	var b strings.Builder
+	b.WriteString("[\n")
+	for i, c := range r.Children {
+		b.WriteString("  ")
+		if c.TokenType == catalog.RuleTokenTypeString {
+			b.WriteString(strconv.Quote(c.ScalarValue))
+		} else {
+			b.WriteString(c.ScalarValue)
+		}
+		if i != len(r.Children)-1 {
+			b.WriteString(",")
+		}
+		if c.Note != "" {
+			b.WriteString(" // " + c.Note)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("]")
+	return b.String()
+}
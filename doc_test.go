@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsightapi/jsight-schema-core/fs"
+
+	"github.com/jsightapi/jsight-api-core/kit"
+)
+
+func Test_jsightCode(t *testing.T) {
+	jAPI, je := kit.NewJApiFromFile(fs.NewFile("root", []byte(testHTMLAPI)))
+	require.Nil(t, je)
+
+	ut, ok := jAPI.Catalog().UserTypes.Get("@cat")
+	require.True(t, ok)
+
+	s, err := newDocSchema("", ut.Schema)
+	require.NoError(t, err)
+
+	assert.Equal(t, `{
+  "id": 1, // {min: 1} - The ID.
+  "tags": [ // {optional: true}
+    "a"
+  ],
+  "color": "red", // {enum: @color}
+  @name: "x" // {optional: true}
+}`, s.Code)
+	assert.Equal(t, `{
+  "id": 1,
+  "tags": [
+    "a"
+  ],
+  "color": "red",
+  "x": "x"
+}`, s.Example)
+}
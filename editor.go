@@ -0,0 +1,491 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	jbytes "github.com/jsightapi/jsight-schema-core/bytes"
+	jfs "github.com/jsightapi/jsight-schema-core/fs"
+
+	"github.com/jsightapi/jsight-api-core/directive"
+	"github.com/jsightapi/jsight-api-core/scanner"
+)
+
+// Kinds of editor symbols and completion items.
+const (
+	editorKindDirective = "directive"
+	editorKindType      = "type"
+	editorKindEnum      = "enum"
+	editorKindMacro     = "macro"
+)
+
+// editorRange is a part of a project file. Lines and columns start from 1 and
+// columns are counted in bytes, the same as in errors. The end is exclusive.
+type editorRange struct {
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	EndLine   int    `json:"endLine"`
+	EndColumn int    `json:"endColumn"`
+}
+
+type editorHover struct {
+	// Range the hovered word.
+	Range editorRange `json:"range"`
+
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+
+	// Annotation, Schema and Definition are known for user types, enums and
+	// macros defined in the project. Schema is the source of the type or enum
+	// body.
+	Annotation string       `json:"annotation,omitempty"`
+	Schema     string       `json:"schema,omitempty"`
+	Definition *editorRange `json:"definition,omitempty"`
+}
+
+type editorCompletionItem struct {
+	Label string `json:"label"`
+	Kind  string `json:"kind"`
+}
+
+// editorPosition is a position in a project file as editors send it.
+type editorPosition struct {
+	file   string
+	line   int
+	column int
+}
+
+func newEditorPosition(file, line, column string) (editorPosition, error) {
+	l, err := strconv.Atoi(line)
+	if err != nil || l < 1 {
+		return editorPosition{}, newRequestError(errorCodeInvalidPosition, "invalid line %q", line)
+	}
+	c, err := strconv.Atoi(column)
+	if err != nil || c < 1 {
+		return editorPosition{}, newRequestError(errorCodeInvalidPosition, "invalid column %q", column)
+	}
+	return editorPosition{file: file, line: l, column: c}, nil
+}
+
+// editorSymbol is a user type, an enum or a macro defined in the project.
+type editorSymbol struct {
+	kind       string
+	name       editorOccurrence
+	annotation string
+	body       string
+}
+
+// editorOccurrence is a user type, enum or macro name in the code.
+type editorOccurrence struct {
+	file       string
+	begin, end int
+
+	// definition is true for the name in the TYPE, ENUM or MACRO directive.
+	definition bool
+}
+
+// editorIndex knows the lexemes of all the files reachable from the project
+// root. It's built by the scanner only, so it works for code which can't be
+// built yet, e.g. while the user is typing. Lexemes after a scanner error are
+// unknown.
+type editorIndex struct {
+	files   memFS
+	lexemes map[string][]*scanner.Lexeme
+
+	symbols     map[string]editorSymbol
+	occurrences []editorOccurrence
+}
+
+func newEditorIndex(p project) (*editorIndex, error) {
+	files, err := includedFiles(p.files, p.root)
+	if err != nil {
+		return nil, err
+	}
+
+	ix := &editorIndex{
+		files:   files,
+		lexemes: make(map[string][]*scanner.Lexeme, len(files)),
+		symbols: map[string]editorSymbol{},
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		ix.scan(name)
+	}
+	return ix, nil
+}
+
+func (ix *editorIndex) scan(file string) {
+	s := scanner.NewJApiScanner(jfs.NewFile(file, ix.files[file]))
+	for {
+		lex, je := s.Next()
+		if je != nil || lex == nil {
+			break
+		}
+		ix.lexemes[file] = append(ix.lexemes[file], lex)
+	}
+
+	ll := ix.lexemes[file]
+	for i, lex := range ll {
+		switch lex.Type() { //nolint:exhaustive // Names are only in these lexemes.
+		case scanner.Parameter, scanner.Schema:
+			definition := i != 0 && isDefinitionKeyword(ll[i-1])
+			for _, o := range userNames(file, lex) {
+				o.definition = definition && o.begin == int(lex.Begin())
+				ix.occurrences = append(ix.occurrences, o)
+				if o.definition {
+					ix.define(o, ll[i-1], ll[i+1:])
+				}
+			}
+		}
+	}
+}
+
+func isDefinitionKeyword(lex *scanner.Lexeme) bool {
+	if lex.Type() != scanner.Keyword {
+		return false
+	}
+	switch lex.Value().String() {
+	case directive.Type.String(), directive.Enum.String(), directive.Macro.String():
+		return true
+	default:
+		return false
+	}
+}
+
+// define adds the symbol defined by the keyword and the name. The annotation
+// and the body are the following lexemes before the next keyword.
+func (ix *editorIndex) define(name editorOccurrence, keyword *scanner.Lexeme, rest []*scanner.Lexeme) {
+	n := ix.text(name)
+	if _, ok := ix.symbols[n]; ok {
+		// The core reports duplicates, the first definition wins here.
+		return
+	}
+
+	sym := editorSymbol{name: name}
+	switch keyword.Value().String() {
+	case directive.Type.String():
+		sym.kind = editorKindType
+	case directive.Enum.String():
+		sym.kind = editorKindEnum
+	default:
+		sym.kind = editorKindMacro
+	}
+
+	for _, lex := range rest {
+		switch lex.Type() { //nolint:exhaustive // Other lexemes don't describe the symbol.
+		case scanner.Keyword:
+			ix.symbols[n] = sym
+			return
+		case scanner.Annotation:
+			sym.annotation = strings.TrimSpace(lex.Value().String())
+		case scanner.Schema, scanner.Enum:
+			sym.body = lex.Value().String()
+		}
+	}
+	ix.symbols[n] = sym
+}
+
+// userNames finds names which start with "@" in the lexeme. A name inside a
+// word, e.g. in an email, isn't a name.
+func userNames(file string, lex *scanner.Lexeme) []editorOccurrence {
+	var oo []editorOccurrence
+	v := lex.Value().Data()
+	for i := 0; i < len(v); i++ {
+		if v[i] != '@' || i != 0 && jbytes.IsValidUserTypeNameByte(v[i-1]) {
+			continue
+		}
+
+		j := i + 1
+		for j < len(v) && jbytes.IsValidUserTypeNameByte(v[j]) {
+			j++
+		}
+		if j == i+1 {
+			continue
+		}
+
+		begin := int(lex.Begin()) + i
+		oo = append(oo, editorOccurrence{file: file, begin: begin, end: begin + j - i})
+		i = j - 1
+	}
+	return oo
+}
+
+func (ix *editorIndex) text(o editorOccurrence) string {
+	return string(ix.files[o.file][o.begin:o.end])
+}
+
+func (ix *editorIndex) rangeOf(file string, begin, end int) editorRange {
+	r := editorRange{File: file}
+	r.Line, r.Column = lineColumn(ix.files[file], begin)
+	r.EndLine, r.EndColumn = lineColumn(ix.files[file], end)
+	return r
+}
+
+func (ix *editorIndex) occurrenceRange(o editorOccurrence) editorRange {
+	return ix.rangeOf(o.file, o.begin, o.end)
+}
+
+// index returns the byte index of the position.
+func (ix *editorIndex) index(p editorPosition) (int, error) {
+	content, ok := ix.files[p.file]
+	if !ok {
+		return 0, newRequestError(errorCodeFileNotFound, "the file %q not found in the project", p.file)
+	}
+
+	if p.line > lineCount(content) {
+		return 0, newRequestError(errorCodeInvalidPosition, "the line %d is out of the file %q", p.line, p.file)
+	}
+
+	i := int(lineIndex(content, p.line))
+	if p.column-1 > len(lineAt(content, i)) {
+		return 0, newRequestError(errorCodeInvalidPosition, "the column %d is out of the line %d", p.column, p.line)
+	}
+	return i + p.column - 1, nil
+}
+
+// occurrenceAt returns the name at the index. The index right after the name
+// counts too, editors put the cursor there.
+func (ix *editorIndex) occurrenceAt(file string, i int) (editorOccurrence, bool) {
+	for _, o := range ix.occurrences {
+		if o.file == file && o.begin <= i && i <= o.end {
+			return o, true
+		}
+	}
+	return editorOccurrence{}, false
+}
+
+// lexemeAt returns the lexeme which contains the index.
+func (ix *editorIndex) lexemeAt(file string, i int) (*scanner.Lexeme, bool) {
+	for _, lex := range ix.lexemes[file] {
+		if int(lex.Begin()) <= i && i <= int(lex.End()) {
+			return lex, true
+		}
+	}
+	return nil, false
+}
+
+func (ix *editorIndex) hover(p editorPosition) (*editorHover, error) {
+	i, err := ix.index(p)
+	if err != nil {
+		return nil, err
+	}
+
+	if o, ok := ix.occurrenceAt(p.file, i); ok {
+		name := ix.text(o)
+		h := &editorHover{
+			Range: ix.occurrenceRange(o),
+			Name:  name,
+		}
+		if sym, ok := ix.symbols[name]; ok {
+			h.Kind = sym.kind
+			h.Annotation = sym.annotation
+			h.Schema = sym.body
+			d := ix.occurrenceRange(sym.name)
+			h.Definition = &d
+		} else {
+			// Not defined in the project, e.g. a typo. It's still a type
+			// reference for the schema.
+			h.Kind = editorKindType
+		}
+		return h, nil
+	}
+
+	if lex, ok := ix.lexemeAt(p.file, i); ok && lex.Type() == scanner.Keyword {
+		return &editorHover{
+			Range: ix.rangeOf(p.file, int(lex.Begin()), int(lex.End())+1),
+			Kind:  editorKindDirective,
+			Name:  lex.Value().String(),
+		}, nil
+	}
+	return nil, nil
+}
+
+func (ix *editorIndex) definitions(p editorPosition) ([]editorRange, error) {
+	i, err := ix.index(p)
+	if err != nil {
+		return nil, err
+	}
+
+	rr := []editorRange{}
+	if o, ok := ix.occurrenceAt(p.file, i); ok {
+		if sym, ok := ix.symbols[ix.text(o)]; ok {
+			rr = append(rr, ix.occurrenceRange(sym.name))
+		}
+	}
+	return rr, nil
+}
+
+// references returns all the occurrences of the name at the position. The
+// definition is included on request.
+func (ix *editorIndex) references(p editorPosition, declaration bool) ([]editorRange, error) {
+	i, err := ix.index(p)
+	if err != nil {
+		return nil, err
+	}
+
+	rr := []editorRange{}
+	at, ok := ix.occurrenceAt(p.file, i)
+	if !ok {
+		return rr, nil
+	}
+
+	name := ix.text(at)
+	for _, o := range ix.occurrences {
+		if ix.text(o) != name || o.definition && !declaration {
+			continue
+		}
+		rr = append(rr, ix.occurrenceRange(o))
+	}
+	return rr, nil
+}
+
+// completion returns names of user types, enums and macros after "@", and
+// keywords of the directives allowed at the beginning of a line.
+func (ix *editorIndex) completion(p editorPosition) ([]editorCompletionItem, error) {
+	i, err := ix.index(p)
+	if err != nil {
+		return nil, err
+	}
+
+	content := ix.files[p.file]
+	begin := i
+	for begin > 0 && jbytes.IsValidUserTypeNameByte(content[begin-1]) {
+		begin--
+	}
+	prefix := string(content[begin:i])
+
+	items := []editorCompletionItem{}
+	if begin > 0 && content[begin-1] == '@' {
+		if begin > 1 && jbytes.IsValidUserTypeNameByte(content[begin-2]) {
+			return items, nil
+		}
+
+		for _, name := range ix.symbolNames() {
+			if strings.HasPrefix(name, "@"+prefix) {
+				items = append(items, editorCompletionItem{Label: name, Kind: ix.symbols[name].kind})
+			}
+		}
+		return items, nil
+	}
+
+	if !isLineBeginning(content, begin) {
+		return items, nil
+	}
+	if lex, ok := ix.lexemeAt(p.file, begin); ok && lex.Type() != scanner.Keyword {
+		return items, nil
+	}
+
+	for _, k := range ix.allowedKeywords(p.file, begin) {
+		if strings.HasPrefix(strings.ToLower(k), strings.ToLower(prefix)) {
+			items = append(items, editorCompletionItem{Label: k, Kind: editorKindDirective})
+		}
+	}
+	return items, nil
+}
+
+// symbolNames returns the names of all the symbols in the order of their
+// definitions.
+func (ix *editorIndex) symbolNames() []string {
+	nn := make([]string, 0, len(ix.symbols))
+	for _, o := range ix.occurrences {
+		if o.definition && ix.symbols[ix.text(o)].name == o {
+			nn = append(nn, ix.text(o))
+		}
+	}
+	return nn
+}
+
+func isLineBeginning(content []byte, i int) bool {
+	for i > 0 {
+		i--
+		switch content[i] {
+		case '\n':
+			return true
+		case ' ', '\t':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// allowedKeywords returns the keywords of directives which can be placed
+// before the index: the children of the directives the index is in, from the
+// nearest one, and then the top-level directives.
+//
+// The directives the index is in are found by keywords before it: a directive
+// is closed by the first keyword which isn't allowed inside it.
+func (ix *editorIndex) allowedKeywords(file string, i int) []string {
+	var stack []directive.Enumeration
+	for _, lex := range ix.lexemes[file] {
+		if int(lex.Begin()) >= i {
+			break
+		}
+		if lex.Type() != scanner.Keyword {
+			continue
+		}
+
+		e, err := directive.NewDirectiveType(lex.Value().String())
+		if err != nil {
+			continue
+		}
+		for len(stack) != 0 && !stack[len(stack)-1].IsAllowedForDirectiveContext(e) {
+			stack = stack[:len(stack)-1]
+		}
+		stack = append(stack, e)
+	}
+
+	var kk []string
+	seen := map[directive.Enumeration]bool{}
+	add := func(allowed func(directive.Enumeration) bool) {
+		for e := directive.Jsight; e <= directive.OperationID; e++ {
+			if e == directive.HTTPResponseCode || seen[e] || !allowed(e) {
+				// Response codes are numbers, not keywords.
+				continue
+			}
+			seen[e] = true
+			kk = append(kk, e.String())
+		}
+	}
+
+	for j := len(stack) - 1; j >= 0; j-- {
+		add(stack[j].IsAllowedForDirectiveContext)
+	}
+	add(func(e directive.Enumeration) bool {
+		return e.IsAllowedForRootContext() || e == directive.Include
+	})
+	return kk
+}
+
+// lineColumn returns the line and the column of the byte index. Unlike
+// jbytes.Bytes.LineAndColumn, the end of the content has them too.
+func lineColumn(content []byte, i int) (line, column int) {
+	line, column = 1, 1
+	for _, c := range content[:i] {
+		if c == '\n' {
+			line++
+			column = 1
+		} else {
+			column++
+		}
+	}
+	return line, column
+}
+
+func lineCount(content []byte) int {
+	n := 1
+	for _, c := range content {
+		if c == '\n' {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+// editorFeature answers the editor request about the position in the project.
+type editorFeature func(ix *editorIndex, p editorPosition, r *http.Request) (any, error)
+
+type editorHoverResponse struct {
+	// Hover is null if there is nothing to tell about the position.
+	Hover *editorHover `json:"hover"`
+}
+
+type editorDefinitionResponse struct {
+	Definitions []editorRange `json:"definitions"`
+}
+
+type editorReferencesResponse struct {
+	References []editorRange `json:"references"`
+}
+
+type editorCompletionResponse struct {
+	Items []editorCompletionItem `json:"items"`
+}
+
+// editorHandler returns the handler of the editor feature. The project is sent
+// the same way as to /convert-jsight, the position is in the query: the file
+// (the root file by default), the line and the column.
+func editorHandler(feature editorFeature) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limitProjectBody(w, r)
+
+		log.Printf("%s %s", r.Method, r.URL.Path)
+
+		if getBoolEnv("JSIGHT_SERVER_CORS") {
+			cors(w)
+		}
+
+		wr := httpResponseWriter{writer: w}
+
+		switch r.Method {
+		case http.MethodOptions:
+
+		case http.MethodPost:
+			editorPOST(wr, r, feature)
+			return
+
+		default:
+			wr.errorStr(errorCodePOSTRequired, "HTTP POST request required")
+			return
+		}
+	}
+}
+
+func editorPOST(wr httpResponseWriter, r *http.Request, feature editorFeature) {
+	p, err := readProject(wr.writer, r)
+	if err != nil {
+		wr.error(err)
+		return
+	}
+
+	file := r.FormValue("file")
+	if file == "" {
+		file = p.root
+	}
+
+	pos, err := newEditorPosition(file, r.FormValue("line"), r.FormValue("column"))
+	if err != nil {
+		wr.error(err)
+		return
+	}
+
+	ix, err := newEditorIndex(p)
+	if err != nil {
+		wr.error(err)
+		return
+	}
+
+	res, err := feature(ix, pos, r)
+	if err != nil {
+		wr.error(err)
+		return
+	}
+
+	b, err := json.Marshal(res)
+	if err != nil {
+		wr.internalServerError(err)
+		return
+	}
+
+	wr.json(b)
+}
+
+func editorHoverFeature(ix *editorIndex, p editorPosition, _ *http.Request) (any, error) {
+	h, err := ix.hover(p)
+	return editorHoverResponse{Hover: h}, err
+}
+
+func editorDefinitionFeature(ix *editorIndex, p editorPosition, _ *http.Request) (any, error) {
+	rr, err := ix.definitions(p)
+	return editorDefinitionResponse{Definitions: rr}, err
+}
+
+func editorReferencesFeature(ix *editorIndex, p editorPosition, r *http.Request) (any, error) {
+	rr, err := ix.references(p, r.FormValue("declaration") == "true")
+	return editorReferencesResponse{References: rr}, err
+}
+
+func editorCompletionFeature(ix *editorIndex, p editorPosition, _ *http.Request) (any, error) {
+	ii, err := ix.completion(p)
+	return editorCompletionResponse{Items: ii}, err
+}
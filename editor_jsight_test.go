@@ -0,0 +1,125 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_editorHandler(t *testing.T) {
+	const jsight = "JSIGHT 0.3\n\nTYPE @cat // A cat.\n{\n  \"id\": 1\n}\n\nGET /cats\n  200 [@cat]\n"
+
+	newRequest := func(url string) func(*testing.T) *http.Request {
+		return func(t *testing.T) *http.Request {
+			r, err := http.NewRequest(http.MethodPost, url, strings.NewReader(jsight))
+			require.NoError(t, err)
+			return r
+		}
+	}
+
+	t.Run("hover", func(t *testing.T) {
+		cc := map[string]testCase{
+			http.MethodOptions: {
+				func(t *testing.T) *http.Request {
+					r, err := http.NewRequest(http.MethodOptions, "/", http.NoBody)
+					require.NoError(t, err)
+					return r
+				},
+				func(t *testing.T, r *httptest.ResponseRecorder) {
+					assert.Equal(t, http.StatusOK, r.Code)
+				},
+			},
+
+			"POST, user type": {
+				newRequest("/?line=9&column=9"),
+				func(t *testing.T, r *httptest.ResponseRecorder) {
+					assert.Equal(t, http.StatusOK, r.Code)
+					assert.Equal(t, "application/json; charset=utf-8", r.Header().Get("Content-Type"))
+					assert.JSONEq(t, `{"hover": {
+						"range": {"file": "root", "line": 9, "column": 8, "endLine": 9, "endColumn": 12},
+						"kind": "type",
+						"name": "@cat",
+						"annotation": "A cat.",
+						"schema": "{\n  \"id\": 1\n}",
+						"definition": {"file": "root", "line": 3, "column": 6, "endLine": 3, "endColumn": 10}
+					}}`, r.Body.String())
+				},
+			},
+
+			"POST, nothing": {
+				newRequest("/?line=2&column=1"),
+				func(t *testing.T, r *httptest.ResponseRecorder) {
+					assert.Equal(t, http.StatusOK, r.Code)
+					assert.Equal(t, `{"hover":null}`, r.Body.String())
+				},
+			},
+
+			"POST, invalid position": {
+				newRequest("/?line=0&column=1"),
+				func(t *testing.T, r *httptest.ResponseRecorder) {
+					assert.Equal(t, http.StatusConflict, r.Code)
+					assert.Equal(t, `{"Status":"Error","Message":"invalid line \"0\"","Line":0,"Index":0,"Code":30009,"Layer":"server"}`, r.Body.String())
+				},
+			},
+
+			"POST, unknown file": {
+				newRequest("/?file=types.jst&line=1&column=1"),
+				func(t *testing.T, r *httptest.ResponseRecorder) {
+					assert.Equal(t, http.StatusConflict, r.Code)
+					assert.Equal(t, `{"Status":"Error","Message":"the file \"types.jst\" not found in the project","Line":0,"Index":0,"Code":30008,"Layer":"server"}`, r.Body.String())
+				},
+			},
+		}
+
+		appendUnhandledMethod(cc)
+		assertHandler(t, editorHandler(editorHoverFeature), cc)
+	})
+
+	t.Run("definition", func(t *testing.T) {
+		assertHandler(t, editorHandler(editorDefinitionFeature), map[string]testCase{
+			"POST": {
+				newRequest("/?line=9&column=8"),
+				func(t *testing.T, r *httptest.ResponseRecorder) {
+					assert.Equal(t, http.StatusOK, r.Code)
+					assert.Equal(t, `{"definitions":[{"file":"root","line":3,"column":6,"endLine":3,"endColumn":10}]}`, r.Body.String())
+				},
+			},
+		})
+	})
+
+	t.Run("references", func(t *testing.T) {
+		assertHandler(t, editorHandler(editorReferencesFeature), map[string]testCase{
+			"POST": {
+				newRequest("/?line=3&column=7"),
+				func(t *testing.T, r *httptest.ResponseRecorder) {
+					assert.Equal(t, http.StatusOK, r.Code)
+					assert.Equal(t, `{"references":[{"file":"root","line":9,"column":8,"endLine":9,"endColumn":12}]}`, r.Body.String())
+				},
+			},
+
+			"POST, with declaration": {
+				newRequest("/?line=3&column=7&declaration=true"),
+				func(t *testing.T, r *httptest.ResponseRecorder) {
+					assert.Equal(t, http.StatusOK, r.Code)
+					assert.Equal(t, `{"references":[{"file":"root","line":3,"column":6,"endLine":3,"endColumn":10},{"file":"root","line":9,"column":8,"endLine":9,"endColumn":12}]}`, r.Body.String())
+				},
+			},
+		})
+	})
+
+	t.Run("completion", func(t *testing.T) {
+		assertHandler(t, editorHandler(editorCompletionFeature), map[string]testCase{
+			"POST": {
+				newRequest("/?line=9&column=10"),
+				func(t *testing.T, r *httptest.ResponseRecorder) {
+					assert.Equal(t, http.StatusOK, r.Code)
+					assert.Equal(t, `{"items":[{"label":"@cat","kind":"type"}]}`, r.Body.String())
+				},
+			},
+		})
+	})
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// editorTestProject is used by the editor tests, they refer to its lines and
+// columns.
+var editorTestProject = project{
+	root: "main.jst",
+	files: memFS{
+		"main.jst": []byte(`JSIGHT 0.3
+
+INCLUDE types.jst
+
+GET /cats
+  200 [@cat] // {maxItems: 10}
+  404 @error
+
+MACRO @pagination
+  Query
+  {
+    "page": 1
+  }
+
+URL /dogs
+  GET
+    PASTE @pagination
+    200 any
+`),
+		"types.jst": []byte(`TYPE @cat // A cat.
+{
+  "id": 1,
+  "email": "cat@example.com",
+  "color": "red" // {enum: @color}
+}
+
+ENUM @color
+[
+  "red",
+  "black"
+]
+`),
+	},
+}
+
+func newEditorTestIndex(t *testing.T) *editorIndex {
+	ix, err := newEditorIndex(editorTestProject)
+	require.NoError(t, err)
+	return ix
+}
+
+func Test_editorIndex_hover(t *testing.T) {
+	ix := newEditorTestIndex(t)
+
+	t.Run("positive", func(t *testing.T) {
+		t.Run("user type", func(t *testing.T) {
+			h, err := ix.hover(editorPosition{file: "main.jst", line: 6, column: 9})
+			require.NoError(t, err)
+
+			assert.Equal(t, &editorHover{
+				Range:      editorRange{File: "main.jst", Line: 6, Column: 8, EndLine: 6, EndColumn: 12},
+				Kind:       editorKindType,
+				Name:       "@cat",
+				Annotation: "A cat.",
+				Schema:     "{\n  \"id\": 1,\n  \"email\": \"cat@example.com\",\n  \"color\": \"red\" // {enum: @color}\n}",
+				Definition: &editorRange{File: "types.jst", Line: 1, Column: 6, EndLine: 1, EndColumn: 10},
+			}, h)
+		})
+
+		t.Run("enum in a rule", func(t *testing.T) {
+			h, err := ix.hover(editorPosition{file: "types.jst", line: 5, column: 30})
+			require.NoError(t, err)
+
+			assert.Equal(t, &editorHover{
+				Range:      editorRange{File: "types.jst", Line: 5, Column: 28, EndLine: 5, EndColumn: 34},
+				Kind:       editorKindEnum,
+				Name:       "@color",
+				Schema:     "[\n  \"red\",\n  \"black\"\n]",
+				Definition: &editorRange{File: "types.jst", Line: 8, Column: 6, EndLine: 8, EndColumn: 12},
+			}, h)
+		})
+
+		t.Run("undefined type", func(t *testing.T) {
+			h, err := ix.hover(editorPosition{file: "main.jst", line: 7, column: 7})
+			require.NoError(t, err)
+
+			assert.Equal(t, &editorHover{
+				Range: editorRange{File: "main.jst", Line: 7, Column: 7, EndLine: 7, EndColumn: 13},
+				Kind:  editorKindType,
+				Name:  "@error",
+			}, h)
+		})
+
+		t.Run("directive", func(t *testing.T) {
+			h, err := ix.hover(editorPosition{file: "main.jst", line: 10, column: 4})
+			require.NoError(t, err)
+
+			assert.Equal(t, &editorHover{
+				Range: editorRange{File: "main.jst", Line: 10, Column: 3, EndLine: 10, EndColumn: 8},
+				Kind:  editorKindDirective,
+				Name:  "Query",
+			}, h)
+		})
+
+		t.Run("nothing", func(t *testing.T) {
+			h, err := ix.hover(editorPosition{file: "types.jst", line: 4, column: 17})
+			require.NoError(t, err)
+			assert.Nil(t, h)
+		})
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		cc := map[editorPosition]string{
+			{file: "other.jst", line: 1, column: 1}: `the file "other.jst" not found in the project`,
+			{file: "main.jst", line: 20, column: 1}: `the line 20 is out of the file "main.jst"`,
+			{file: "main.jst", line: 1, column: 12}: `the column 12 is out of the line 1`,
+		}
+
+		for p, expected := range cc {
+			_, err := ix.hover(p)
+			assert.EqualError(t, err, expected)
+		}
+	})
+}
+
+func Test_editorIndex_definitions(t *testing.T) {
+	ix := newEditorTestIndex(t)
+
+	cc := map[editorPosition][]editorRange{
+		{file: "main.jst", line: 17, column: 11}: {{File: "main.jst", Line: 9, Column: 7, EndLine: 9, EndColumn: 18}},
+		{file: "types.jst", line: 1, column: 6}:  {{File: "types.jst", Line: 1, Column: 6, EndLine: 1, EndColumn: 10}},
+		{file: "main.jst", line: 7, column: 8}:   {},
+		{file: "main.jst", line: 5, column: 6}:   {},
+	}
+
+	for p, expected := range cc {
+		rr, err := ix.definitions(p)
+		require.NoError(t, err)
+		assert.Equal(t, expected, rr, p)
+	}
+}
+
+func Test_editorIndex_references(t *testing.T) {
+	ix := newEditorTestIndex(t)
+
+	t.Run("without declaration", func(t *testing.T) {
+		rr, err := ix.references(editorPosition{file: "types.jst", line: 8, column: 8}, false)
+		require.NoError(t, err)
+
+		assert.Equal(t, []editorRange{
+			{File: "types.jst", Line: 5, Column: 28, EndLine: 5, EndColumn: 34},
+		}, rr)
+	})
+
+	t.Run("with declaration", func(t *testing.T) {
+		rr, err := ix.references(editorPosition{file: "main.jst", line: 6, column: 8}, true)
+		require.NoError(t, err)
+
+		assert.Equal(t, []editorRange{
+			{File: "main.jst", Line: 6, Column: 8, EndLine: 6, EndColumn: 12},
+			{File: "types.jst", Line: 1, Column: 6, EndLine: 1, EndColumn: 10},
+		}, rr)
+	})
+
+	t.Run("not a name", func(t *testing.T) {
+		rr, err := ix.references(editorPosition{file: "types.jst", line: 4, column: 17}, true)
+		require.NoError(t, err)
+		assert.Equal(t, []editorRange{}, rr)
+	})
+}
+
+func Test_editorIndex_completion(t *testing.T) {
+	complete := func(t *testing.T, content string, line, column int) []editorCompletionItem {
+		p := project{root: "main.jst", files: memFS{
+			"main.jst":  []byte(content),
+			"types.jst": editorTestProject.files["types.jst"],
+		}}
+
+		ix, err := newEditorIndex(p)
+		require.NoError(t, err)
+
+		ii, err := ix.completion(editorPosition{file: "main.jst", line: line, column: column})
+		require.NoError(t, err)
+		return ii
+	}
+
+	t.Run("user names", func(t *testing.T) {
+		assert.Equal(t, []editorCompletionItem{
+			{Label: "@cat", Kind: editorKindType},
+			{Label: "@color", Kind: editorKindEnum},
+		}, complete(t, "JSIGHT 0.3\n\nINCLUDE types.jst\n\nGET /cats\n  200 @c", 6, 9))
+
+		assert.Equal(t, []editorCompletionItem{
+			{Label: "@pagination", Kind: editorKindMacro},
+			{Label: "@cat", Kind: editorKindType},
+			{Label: "@color", Kind: editorKindEnum},
+		}, complete(t, "JSIGHT 0.3\n\nINCLUDE types.jst\n\nMACRO @pagination\n  200 any\n\nGET /cats\n  PASTE @", 9, 10))
+	})
+
+	t.Run("keywords inside a directive", func(t *testing.T) {
+		assert.Equal(t, []editorCompletionItem{
+			{Label: "PASTE", Kind: editorKindDirective},
+			{Label: "Path", Kind: editorKindDirective},
+			{Label: "POST", Kind: editorKindDirective},
+			{Label: "PUT", Kind: editorKindDirective},
+			{Label: "PATCH", Kind: editorKindDirective},
+		}, complete(t, "JSIGHT 0.3\n\nGET /cats\n  200 any\n  P", 5, 4))
+	})
+
+	t.Run("top-level keywords", func(t *testing.T) {
+		assert.Equal(t, []editorCompletionItem{
+			{Label: "TYPE", Kind: editorKindDirective},
+			{Label: "TAG", Kind: editorKindDirective},
+		}, complete(t, "JSIGHT 0.3\n\nT", 3, 2))
+	})
+
+	t.Run("nothing", func(t *testing.T) {
+		cc := map[string][2]int{
+			"JSIGHT 0.3\n\nTYPE @cat\n{\n  \"id\": 1\n}\n":        {5, 5},
+			"JSIGHT 0.3\n\nGET /cats\n  200 any\n":                {4, 8},
+			"JSIGHT 0.3\n\nTYPE @cat\n{\n  \"email\": \"a@b\"\n}": {5, 16},
+		}
+
+		for content, pos := range cc {
+			assert.Equal(t, []editorCompletionItem{}, complete(t, content, pos[0], pos[1]), content)
+		}
+	})
+}
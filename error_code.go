@@ -0,0 +1,448 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jsightapi/jsight-schema-core/errs"
+
+	"github.com/jsightapi/jsight-api-core/jerr"
+)
+
+// Layers where errors occur.
+const (
+	// errorLayerServer the request to the server is invalid.
+	errorLayerServer = "server"
+
+	// errorLayerScanner the JSight code can't be split into directives.
+	errorLayerScanner = "scanner"
+
+	// errorLayerCore the directives are invalid or inconsistent.
+	errorLayerCore = "core"
+
+	// errorLayerSchema the JSight schema is invalid.
+	errorLayerSchema = "schema"
+
+	// errorLayerExport the document generated by the server is invalid.
+	errorLayerExport = "export"
+)
+
+// Codes of errors which aren't recognized. Schema errors keep their JSight
+// Schema Core codes, which are below 10000.
+const (
+	errorCodeServer = 30000
+	errorCodeCore   = 20000
+)
+
+// Codes of errors in documents generated by the server.
+const (
+	// errorCodeInvalidOpenAPI the generated OpenAPI document violates the
+	// OpenAPI meta-schema.
+	errorCodeInvalidOpenAPI = 40001
+)
+
+// Codes of errors in requests to the server. The codes are stable: a code is
+// never reused for another error.
+const (
+	errorCodePOSTRequired        = 30001
+	errorCodeParameterRequired   = 30002
+	errorCodeUnsupportedFormat   = 30003
+	errorCodeInvalidParameter    = 30004
+	errorCodeInvalidRequest      = 30005
+	errorCodeTooLarge            = 30006
+	errorCodeInvalidProject      = 30007
+	errorCodeFileNotFound        = 30008
+	errorCodeInvalidPosition     = 30009
+	errorCodeUnknownLintRule     = 30010
+	errorCodeTagNotFound         = 30011
+	errorCodeServerNotFound      = 30012
+	errorCodeInteractionNotFound = 30013
+	errorCodeInvalidTarget       = 30014
+	errorCodeInvalidOpenAPIInput = 30015
+)
+
+// requestError is an error in the request to the server with its code.
+type requestError struct {
+	code int
+	err  error
+}
+
+func newRequestError(code int, format string, args ...any) error {
+	return &requestError{code: code, err: fmt.Errorf(format, args...)}
+}
+
+func (e *requestError) Error() string {
+	return e.err.Error()
+}
+
+func (e *requestError) Unwrap() error {
+	return e.err
+}
+
+// apiErrorFormats are messages of JSight API Core errors with their codes. The
+// codes are stable: a code is never reused for another error.
+var apiErrorFormats = []struct {
+	code   int
+	layer  string
+	format string
+}{
+	{10001, errorLayerScanner, "invalid character %s"},
+	{10002, errorLayerScanner, "invalid end of file %s"},
+	{10003, errorLayerScanner, "File cannot contain byte zero"},
+	{10004, errorLayerScanner, "Ending lexeme event does not match beginning event"},
+	{10005, errorLayerScanner, "Unsupported lexeme event type"},
+	{10006, errorLayerScanner, jerr.ApartFromTheOpeningParenthesis},
+
+	{20001, errorLayerCore, jerr.RuntimeFailure},
+	{20002, errorLayerCore, jerr.HTTPResourceNotFound},
+	{20003, errorLayerCore, jerr.HTTPMethodNotFound},
+	{20004, errorLayerCore, jerr.PathNotFound},
+	{20005, errorLayerCore, jerr.TagNotFound},
+	{20006, errorLayerCore, jerr.UserTypeNotFound},
+	{20007, errorLayerCore, jerr.ParentNotFound},
+	{20008, errorLayerCore, jerr.MacroNotFound},
+	{20009, errorLayerCore, jerr.ServerNotFound},
+	{20010, errorLayerCore, jerr.JsonRpcMethodNotFound},
+	{20011, errorLayerCore, jerr.ProtocolNotFound},
+	{20012, errorLayerCore, jerr.ProtocolParameterErr},
+	{20013, errorLayerCore, jerr.InfoIsEmpty},
+	{20014, errorLayerCore, jerr.ResponsesIsEmpty},
+	{20015, errorLayerCore, jerr.RequestIsEmpty},
+	{20016, errorLayerCore, jerr.DescriptionIsEmpty},
+	{20017, errorLayerCore, jerr.BodyIsEmpty},
+	{20018, errorLayerCore, jerr.MacroIsEmpty},
+	{20019, errorLayerCore, jerr.IncorrectPath},
+	{20020, errorLayerCore, jerr.IncorrectRequest},
+	{20021, errorLayerCore, jerr.IncorrectDirectiveContext},
+	{20022, errorLayerCore, jerr.IncorrectParameter},
+	{20023, errorLayerCore, jerr.PathOrErr},
+	{20024, errorLayerCore, jerr.PathObjectErr},
+	{20025, errorLayerCore, jerr.PathAdditionalPropertiesErr},
+	{20026, errorLayerCore, jerr.PathNullableErr},
+	{20027, errorLayerCore, jerr.PathEmptyErr},
+	{20028, errorLayerCore, jerr.PathMultiLevelPropertyErr},
+	{20029, errorLayerCore, jerr.PathEmptyParameter},
+	{20030, errorLayerCore, jerr.PathParameterIsDuplicatedInThePath},
+	{20031, errorLayerCore, jerr.PathsAreSimilar},
+	{20032, errorLayerCore, jerr.PathParameterAlreadyDefined},
+	{20033, errorLayerCore, jerr.IncludeRootErr},
+	{20034, errorLayerCore, jerr.IncludeUpErr},
+	{20035, errorLayerCore, jerr.IncludeSeparatorErr},
+	{20036, errorLayerCore, jerr.IncludeDirectiveErr},
+	{20037, errorLayerCore, jerr.UnsupportedVersion},
+	{20038, errorLayerCore, jerr.DirectiveJSIGHTShouldBeTheFirst},
+	{20039, errorLayerCore, jerr.DirectiveJSIGHTGottaBeOnlyOneTime},
+	{20040, errorLayerCore, jerr.DirectiveINFOGottaBeOnlyOneTime},
+	{20041, errorLayerCore, jerr.DirectiveBaseURLAlreadyDefined},
+	{20042, errorLayerCore, jerr.UnknownDirective},
+	{20043, errorLayerCore, jerr.UnknownNotation},
+	{20044, errorLayerCore, jerr.RequiredParameterNotSpecified},
+	{20045, errorLayerCore, jerr.ParametersAreForbiddenForTheDirective},
+	{20046, errorLayerCore, jerr.ParametersIsAlreadyDefined},
+	{20047, errorLayerCore, jerr.AnnotationIsForbiddenForTheDirective},
+	{20048, errorLayerCore, jerr.NotUniqueDirective},
+	{20049, errorLayerCore, jerr.NotUniquePath},
+	{20050, errorLayerCore, jerr.NotUniqueOperationID},
+	{20051, errorLayerCore, jerr.BodyMustBeObject},
+	{20052, errorLayerCore, jerr.CannotUseTheTypeAndSchemaNotationParametersTogether},
+	{20053, errorLayerCore, jerr.ThereIsNoExplicitContextForClosure},
+	{20054, errorLayerCore, jerr.DirectiveNotAllowed},
+	{20055, errorLayerCore, jerr.DuplicateNames},
+	{20056, errorLayerCore, jerr.NotAllowedToOverrideTheProperty},
+	{20057, errorLayerCore, jerr.ContextNotClosed},
+	{20058, errorLayerCore, jerr.WrongDescriptionContext},
+	{20059, errorLayerCore, jerr.MethodIsAlreadyDefinedInResource},
+	{20060, errorLayerCore, jerr.UndefinedRequestBodyForResource},
+	{20061, errorLayerCore, jerr.RecursionIsProhibited},
+	{20062, errorLayerCore, jerr.UserTypeIsNotAnObject},
+	{20063, errorLayerCore, jerr.ProcessTypeErr},
+	{20064, errorLayerCore, jerr.FailedToComputeScannersHash},
+}
+
+// schemaErrorFormats are JSight Schema Core errors with the number of
+// arguments of their messages. The message formats aren't exported, so the
+// messages are formatted with placeholder arguments. The generic errors (0 and
+// 1) aren't listed, they would match any message.
+var schemaErrorFormats = []struct {
+	code errs.Code
+	args int
+}{
+	{errs.ErrUserTypeFound, 0},
+	{errs.ErrUnknownValueOfTheTypeRule, 1},
+	{errs.ErrUnknownJSchemaType, 1},
+	{errs.ErrInfiniteRecursionDetected, 1},
+	{errs.ErrNodeTypeCantBeGuessed, 1},
+	{errs.ErrUnableToDetermineTheTypeOfJsonValue, 0},
+	{errs.ErrValidator, 0},
+	{errs.ErrEmptySchema, 0},
+	{errs.ErrEmptyJson, 0},
+	{errs.ErrOrRuleSetValidation, 0},
+	{errs.ErrRequiredKeyNotFound, 1},
+	{errs.ErrSchemaDoesNotSupportKey, 1},
+	{errs.ErrUnexpectedLexInLiteralValidator, 0},
+	{errs.ErrUnexpectedLexInObjectValidator, 0},
+	{errs.ErrUnexpectedLexInArrayValidator, 0},
+	{errs.ErrInvalidValueType, 2},
+	{errs.ErrInvalidKeyType, 1},
+	{errs.ErrUnexpectedLexInMixedValidator, 0},
+	{errs.ErrObjectExpected, 0},
+	{errs.ErrPropertyNotFound, 1},
+	{errs.ErrInvalidCharacter, 2},
+	{errs.ErrInvalidCharacterInAnnotationObjectKey, 1},
+	{errs.ErrUnexpectedEOF, 0},
+	{errs.ErrAnnotationNotAllowed, 0},
+	{errs.ErrEmptySetOfLexicalEvents, 0},
+	{errs.ErrIncorrectEndingOfTheLexicalEvent, 0},
+	{errs.ErrNodeGrow, 0},
+	{errs.ErrDuplicateKeysInSchema, 1},
+	{errs.ErrDuplicationOfNameOfTypes, 1},
+	{errs.ErrDuplicateRule, 1},
+	{errs.ErrUnexpectedLexicalEvent, 2},
+	{errs.ErrUnknownRule, 1},
+	{errs.ErrConstraintValidation, 3},
+	{errs.ErrConstraintStringLengthValidation, 2},
+	{errs.ErrInvalidValueOfConstraint, 1},
+	{errs.ErrZeroPrecision, 0},
+	{errs.ErrEmptyEmail, 0},
+	{errs.ErrInvalidEmail, 1},
+	{errs.ErrConstraintMinItemsValidation, 0},
+	{errs.ErrConstraintMaxItemsValidation, 0},
+	{errs.ErrDoesNotMatchAnyOfTheEnumValues, 0},
+	{errs.ErrDoesNotMatchRegularExpression, 0},
+	{errs.ErrInvalidURI, 1},
+	{errs.ErrInvalidDateTime, 0},
+	{errs.ErrInvalidUUID, 1},
+	{errs.ErrInvalidConst, 1},
+	{errs.ErrInvalidDate, 1},
+	{errs.ErrValueOfOneConstraintGreaterOrEqualToAnother, 2},
+	{errs.ErrInvalidSchemaName, 1},
+	{errs.ErrInvalidSchemaNameInAllOfRule, 1},
+	{errs.ErrUnacceptableRecursionInAllOfRule, 0},
+	{errs.ErrUnacceptableUserTypeInAllOfRule, 1},
+	{errs.ErrConflictAdditionalProperties, 0},
+	{errs.ErrLoadError, 1},
+	{errs.ErrIncorrectRuleValueType, 0},
+	{errs.ErrIncorrectRuleWithoutExample, 0},
+	{errs.ErrLiteralValueExpected, 0},
+	{errs.ErrInvalidValueInEnumRule, 0},
+	{errs.ErrIncorrectArrayItemTypeInEnumRule, 0},
+	{errs.ErrTypeNameNotFoundInAllOfRule, 0},
+	{errs.ErrDuplicationInEnumRule, 1},
+	{errs.ErrRuleIsAlreadyCompiled, 0},
+	{errs.ErrRuleIsNil, 0},
+	{errs.ErrArrayWasExpectedInOrRule, 0},
+	{errs.ErrEmptyArrayInOrRule, 0},
+	{errs.ErrOneElementInArrayInOrRule, 0},
+	{errs.ErrIncorrectArrayItemTypeInOrRule, 0},
+	{errs.ErrEmptyRuleSet, 0},
+	{errs.ErrTypIsRequiredInsideOr, 0},
+	{errs.ErrRuleOptionalAppliesOnlyToObjectProperties, 0},
+	{errs.ErrCannotSpecifyOtherRulesWithTypeReference, 0},
+	{errs.ErrShouldBeNoOtherRulesInSetWithOr, 0},
+	{errs.ErrShouldBeNoOtherRulesInSetWithEnum, 0},
+	{errs.ErrShouldBeNoOtherRulesInSetWithAny, 0},
+	{errs.ErrInvalidNestedElementsFoundForTypeAny, 0},
+	{errs.ErrInvalidChildNodeTogetherWithTypeReference, 0},
+	{errs.ErrInvalidChildNodeTogetherWithOrRule, 0},
+	{errs.ErrConstraintMinNotFound, 0},
+	{errs.ErrConstraintMaxNotFound, 0},
+	{errs.ErrInvalidValueInTheTypeRule, 1},
+	{errs.ErrNotFoundRulePrecision, 0},
+	{errs.ErrNotFoundRuleEnum, 0},
+	{errs.ErrNotFoundRuleOr, 0},
+	{errs.ErrIncompatibleTypes, 1},
+	{errs.ErrUnexpectedConstraint, 2},
+	{errs.ErrChecker, 0},
+	{errs.ErrElementNotFoundInArray, 0},
+	{errs.ErrIncorrectConstraintValueForEmptyArray, 0},
+	{errs.ErrIncorrectUserType, 0},
+	{errs.ErrUserTypeNotFound, 1},
+	{errs.ErrInvalidKeyShortcutType, 2},
+	{errs.ErrEmptyType, 1},
+	{errs.ErrUnnecessaryLexemeAfterTheEndOfEnum, 0},
+	{errs.ErrRegexUnexpectedStart, 1},
+	{errs.ErrRegexUnexpectedEnd, 1},
+	{errs.ErrRegexInvalid, 1},
+	{errs.ErrEnumArrayExpected, 0},
+	{errs.ErrEnumIsHoldRuleName, 0},
+	{errs.ErrEnumRuleNotFound, 1},
+	{errs.ErrNotAnEnumRule, 1},
+	{errs.ErrInvalidEnumValues, 2},
+	{errs.ErrInvalidBoolValue, 0},
+	{errs.ErrNotEnoughDataInParseUint, 0},
+	{errs.ErrInvalidByteInParseUint, 2},
+	{errs.ErrTooMuchDataForInt, 0},
+	{errs.ErrIncorrectNumberValue, 1},
+	{errs.ErrURNPrefix, 1},
+	{errs.ErrUUIDLength, 1},
+	{errs.ErrUUIDFormat, 0},
+	{errs.ErrUUIDPrefix, 0},
+	{errs.ErrIncorrectExponentValue, 0},
+	{errs.ErrRegexExample, 1},
+	{errs.ErrCantCollectRulesTypes, 0},
+}
+
+// errorCode returns the stable code of the error and the layer where it
+// occurred.
+func errorCode(e error) (int, string) {
+	var ve *openapiValidationError
+	if errors.As(e, &ve) {
+		return errorCodeInvalidOpenAPI, errorLayerExport
+	}
+
+	var re *requestError
+	if errors.As(e, &re) {
+		return re.code, errorLayerServer
+	}
+
+	var je *jerr.JApiError
+	if !errors.As(e, &je) {
+		return errorCodeServer, errorLayerServer
+	}
+
+	if c := schemaErrorCode(je.Msg); c != 0 {
+		return int(c), errorLayerSchema
+	}
+
+	apiErrorPatternsOnce.Do(func() {
+		apiErrorPatterns = newAPIErrorPatterns()
+	})
+
+	for _, p := range apiErrorPatterns {
+		if p.re.MatchString(je.Msg) {
+			return p.code, p.layer
+		}
+	}
+	return errorCodeCore, errorLayerCore
+}
+
+// schemaErrorCode returns the JSight Schema Core code of the error message, or
+// zero if the message isn't a schema error.
+//
+// JSight API Core passes only the message text of schema errors on, so the
+// code is recognized by the message.
+func schemaErrorCode(msg string) errs.Code {
+	schemaErrorPatternsOnce.Do(func() {
+		schemaErrorPatterns = newSchemaErrorPatterns()
+	})
+
+	for _, p := range schemaErrorPatterns {
+		if p.re.MatchString(msg) {
+			return errs.Code(p.code)
+		}
+	}
+	return 0
+}
+
+type errorPattern struct {
+	code  int
+	layer string
+	re    *regexp.Regexp
+
+	// literal the length of the message text without arguments. The longer it
+	// is, the more specific the pattern is.
+	literal int
+}
+
+var (
+	apiErrorPatterns     []errorPattern
+	apiErrorPatternsOnce sync.Once
+
+	schemaErrorPatterns     []errorPattern
+	schemaErrorPatternsOnce sync.Once
+)
+
+// apiErrorArgRe matches arguments in the formats of JSight API Core messages.
+var apiErrorArgRe = regexp.MustCompile(`%[sq]`)
+
+// newAPIErrorPatterns builds patterns of JSight API Core messages. Messages
+// start with the format, arguments or details can follow it.
+func newAPIErrorPatterns() []errorPattern {
+	pp := make([]errorPattern, 0, len(apiErrorFormats))
+	for _, f := range apiErrorFormats {
+		re, literal := errorFormatRegexp(apiErrorArgRe.Split(f.format, -1))
+		pp = append(pp, errorPattern{
+			code:    f.code,
+			layer:   f.layer,
+			re:      regexp.MustCompile(`(?s)^` + re),
+			literal: literal,
+		})
+	}
+
+	sortErrorPatterns(pp)
+	return pp
+}
+
+// schemaErrorArg replaces the arguments of schema error messages, so the
+// message format can be restored.
+const schemaErrorArg = "\x00"
+
+// schemaErrorArgRe matches the formatted schemaErrorArg: %s, %q and verbs
+// which don't accept a string, e.g. %w.
+var schemaErrorArgRe = regexp.MustCompile(`%!\w\(string=\x00\)|"\\x00"|\x00`)
+
+// newSchemaErrorPatterns builds patterns of the schema error messages.
+func newSchemaErrorPatterns() []errorPattern {
+	pp := make([]errorPattern, 0, len(schemaErrorFormats))
+	for _, f := range schemaErrorFormats {
+		msg, ok := formatSchemaError(f.code, f.args, schemaErrorArg)
+		if !ok {
+			// The schema core panics if the number of arguments is wrong.
+			// The table is checked by tests, the server must not fail here.
+			continue
+		}
+
+		re, literal := errorFormatRegexp(schemaErrorArgRe.Split(msg, -1))
+		pp = append(pp, errorPattern{
+			code:    int(f.code),
+			layer:   errorLayerSchema,
+			re:      regexp.MustCompile(`(?s)` + re + `$`),
+			literal: literal,
+		})
+	}
+
+	sortErrorPatterns(pp)
+	return pp
+}
+
+// formatSchemaError formats the message of the schema error with n copies of
+// the argument. It isn't ok if the number of arguments is wrong.
+func formatSchemaError(c errs.Code, n int, arg string) (msg string, ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			ok = false
+		}
+	}()
+
+	args := make([]any, n)
+	for i := range args {
+		args[i] = arg
+	}
+	return c.F(args...).Error(), true
+}
+
+// errorFormatRegexp joins the literal parts of the message with wildcards for
+// arguments.
+func errorFormatRegexp(parts []string) (re string, literal int) {
+	var b strings.Builder
+	for i, s := range parts {
+		if i != 0 {
+			b.WriteString(".*")
+		}
+		b.WriteString(regexp.QuoteMeta(s))
+		literal += len(s)
+	}
+	return b.String(), literal
+}
+
+// sortErrorPatterns puts more specific patterns first. The order of patterns
+// with the same length is kept, so the first one of the same messages wins.
+func sortErrorPatterns(pp []errorPattern) {
+	sort.SliceStable(pp, func(i, j int) bool {
+		return pp[i].literal > pp[j].literal
+	})
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jsightapi/jsight-schema-core/errs"
+	"github.com/jsightapi/jsight-schema-core/fs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsightapi/jsight-api-core/jerr"
+	"github.com/jsightapi/jsight-api-core/kit"
+)
+
+func Test_errorCode(t *testing.T) {
+	type expected struct {
+		code  int
+		layer string
+	}
+
+	japiError := func(msg string) error {
+		return jerr.NewJApiError(msg, fs.NewFile("root", []byte("JSIGHT 0.3")), 0)
+	}
+
+	cc := map[string]struct {
+		given    error
+		expected expected
+	}{
+		"server": {
+			errors.New("HTTP POST request required"),
+			expected{30000, "server"},
+		},
+		"scanner": {
+			japiError("invalid character 'i' at the directive beginning"),
+			expected{10001, "scanner"},
+		},
+		"scanner, end of file": {
+			japiError("invalid end of file in keyword Body, expecting y"),
+			expected{10002, "scanner"},
+		},
+		"core": {
+			japiError(`tag not found "@cats"`),
+			expected{20005, "core"},
+		},
+		"core, formatted": {
+			japiError(`the path "/cats" has already been defined`),
+			expected{20049, "core"},
+		},
+		"core, the longest format wins": {
+			japiError("the directive is not allowed in included files: INFO"),
+			expected{20036, "core"},
+		},
+		"core, unknown": {
+			japiError("fake error"),
+			expected{20000, "core"},
+		},
+		"schema": {
+			japiError(`Type "@cat" not found`),
+			expected{1302, "schema"},
+		},
+		"export": {
+			fmt.Errorf("new: %w", &openapiValidationError{Pointer: "/paths", Message: "fake error"}),
+			expected{40001, "export"},
+		},
+		"schema, wrapped": {
+			fmt.Errorf("new: %w", japiError(`process type "@cat": Type "@dog" not found`)),
+			expected{1302, "schema"},
+		},
+	}
+
+	for n, c := range cc {
+		t.Run(n, func(t *testing.T) {
+			code, layer := errorCode(c.given)
+			assert.Equal(t, c.expected, expected{code, layer})
+		})
+	}
+}
+
+func Test_schemaErrorCode(t *testing.T) {
+	t.Run("positive", func(t *testing.T) {
+		cc := map[string]errs.Code{
+			`Type "@cat" not found`:                        errs.ErrUserTypeNotFound,
+			`Incorrect number value "\"a\""`:               errs.ErrIncorrectNumberValue,
+			`The rule "min" seems to be forgotten.`:        errs.ErrConstraintMinNotFound,
+			`process type "@cat": Type "@dog" not found`:   errs.ErrUserTypeNotFound,
+			"The regular expression is invalid: missing )": errs.ErrRegexInvalid,
+		}
+
+		for msg, expected := range cc {
+			t.Run(msg, func(t *testing.T) {
+				assert.Equal(t, expected, schemaErrorCode(msg))
+			})
+		}
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		cc := []string{
+			"",
+			"invalid character 'i' at the directive beginning",
+			`user type not found (@cat)`,
+		}
+
+		for _, msg := range cc {
+			t.Run(msg, func(t *testing.T) {
+				assert.Zero(t, schemaErrorCode(msg))
+			})
+		}
+	})
+}
+
+func Test_errorCode_roundTrip(t *testing.T) {
+	type expected struct {
+		code  int
+		layer string
+	}
+
+	japiError := func(msg string) error {
+		return jerr.NewJApiError(msg, fs.NewFile("root", []byte("JSIGHT 0.3")), 0)
+	}
+
+	t.Run("API errors", func(t *testing.T) {
+		for _, f := range apiErrorFormats {
+			f := f
+			t.Run(f.format, func(t *testing.T) {
+				msg := apiErrorArgRe.ReplaceAllString(f.format, `"x"`)
+
+				code, layer := errorCode(japiError(msg))
+				assert.Equal(t, expected{f.code, f.layer}, expected{code, layer})
+			})
+		}
+	})
+
+	t.Run("schema errors", func(t *testing.T) {
+		messages := map[int]string{}
+		for _, f := range schemaErrorFormats {
+			msg, ok := formatSchemaError(f.code, f.args, "x")
+			require.True(t, ok, "the code %d has other number of arguments than %d", f.code, f.args)
+			messages[int(f.code)] = msg
+		}
+
+		for _, f := range schemaErrorFormats {
+			msg := messages[int(f.code)]
+
+			code, layer := errorCode(japiError(msg))
+			assert.Equal(t, errorLayerSchema, layer, msg)
+
+			// Some codes have the same message, e.g. 102 and 103, the first
+			// of them is returned.
+			if code != int(f.code) {
+				assert.Equal(t, msg, messages[code], "the code %d is recognized as %d", f.code, code)
+			}
+		}
+	})
+
+	t.Run("errors of JSight API Core", func(t *testing.T) {
+		cc := map[string]expected{
+			"JSIGHT 0.3\n\nGET /cats\n  200 any\nfoo":                    {10001, errorLayerScanner},
+			"JSIGHT 0.3\n\nGET /cats\n  200 any\nGE":                     {10002, errorLayerScanner},
+			"JSIGHT 0.3\n\nGET /cats\n  200 any\n\x00":                   {10003, errorLayerScanner},
+			"JSIGHT 0.3\n\nGET /cats\n  200 any\nGET":                    {20004, errorLayerCore},
+			"JSIGHT 0.3\n\nGET /cats\n  Tags @cats\n  200 any\n":         {20005, errorLayerCore},
+			"JSIGHT 0.3\n\nGET /cats\n  200 any\nGET /cats\n  200 any\n": {20059, errorLayerCore},
+			"JSIGHT 0.3\n\nGET /cats\n  200 @cat\n":                      {1302, errorLayerSchema},
+			"JSIGHT 0.3\n\nTYPE @cat\n{\n  \"a\": 1 // {min: 2}\n}\n":    {602, errorLayerSchema},
+		}
+
+		for jsight, e := range cc {
+			t.Run(jsight, func(t *testing.T) {
+				_, je := kit.NewJApiFromFile(fs.NewFile("root", []byte(jsight)))
+				require.NotNil(t, je)
+
+				code, layer := errorCode(je)
+				assert.Equal(t, e, expected{code, layer}, je.Error())
+			})
+		}
+	})
+
+	t.Run("request errors", func(t *testing.T) {
+		cc := map[string]struct {
+			given    error
+			expected int
+		}{
+			"unknown lint rule": {
+				newRequestError(errorCodeUnknownLintRule, "unknown lint rule %q", "foo"),
+				30010,
+			},
+			"project too large": {
+				errProjectTooLarge,
+				30006,
+			},
+			"invalid line, wrapped": {
+				fmt.Errorf("position: %w", newRequestError(errorCodeInvalidPosition, "invalid line %q", "0")),
+				30009,
+			},
+			"tag not found": {
+				newRequestError(errorCodeTagNotFound, "the tag %q not found", "@cats"),
+				30011,
+			},
+		}
+
+		for n, c := range cc {
+			t.Run(n, func(t *testing.T) {
+				code, layer := errorCode(c.given)
+				assert.Equal(t, expected{c.expected, "server"}, expected{code, layer})
+			})
+		}
+	})
+}
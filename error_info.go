@@ -2,6 +2,8 @@ package main
 
 import (
 	"errors"
+	"strconv"
+	"strings"
 
 	"github.com/jsightapi/jsight-api-core/jerr"
 )
@@ -11,20 +13,131 @@ type errorInfo struct {
 	Message string
 	Line    int
 	Index   int
+
+	// Code the stable code of the error, Layer the layer where it occurred.
+	Code  int
+	Layer string
+
+	// File the path of the file where the error occurred. Line and Index are
+	// relative to this file.
+	File   string `json:",omitempty"`
+	Column int    `json:",omitempty"`
+
+	// IncludeTrace the files which include the file with the error, from the
+	// nearest one. The message contains the same trace as text.
+	IncludeTrace []includeFrame `json:",omitempty"`
+
+	// Pointer a JSON Pointer (RFC 6901) to the invalid value of the document
+	// generated by the server.
+	Pointer string `json:",omitempty"`
+
+	// Errors all the errors found in the project, the first one is the same as
+	// above. It is filled only if the errors are collected on request.
+	Errors []errorDetail `json:",omitempty"`
+}
+
+// errorDetail describes one of the errors found in the project.
+type errorDetail struct {
+	File     string
+	Line     int
+	Column   int
+	Index    int
+	Severity string
+	Code     int
+	Layer    string
+	Message  string
+
+	IncludeTrace []includeFrame `json:",omitempty"`
 }
 
+// includeFrame is the INCLUDE directive which led to the error.
+type includeFrame struct {
+	File string
+	Line int
+}
+
+const severityError = "error"
+
 func newErrorInfo(e error) errorInfo {
 	r := errorInfo{
 		Status:  "Error",
 		Message: e.Error(),
 	}
+	r.Code, r.Layer = errorCode(e)
 
 	var je *jerr.JApiError
 
 	if errors.As(e, &je) {
 		r.Line = je.Line.Int()
 		r.Index = je.Index.Int()
+		r.Column = je.Column.Int()
+		r.IncludeTrace = includeTrace(je)
+		if je.File != nil {
+			r.File = je.File.Name()
+		}
+	}
+
+	var ve *openapiValidationError
+
+	if errors.As(e, &ve) {
+		r.Pointer = ve.Pointer
+	}
+
+	var ee japiErrors
+
+	if errors.As(e, &ee) {
+		r.Errors = make([]errorDetail, 0, len(ee))
+		for _, je := range ee {
+			r.Errors = append(r.Errors, newErrorDetail(je))
+		}
 	}
 
 	return r
 }
+
+func newErrorDetail(je *jerr.JApiError) errorDetail {
+	d := errorDetail{
+		Line:     je.Line.Int(),
+		Column:   je.Column.Int(),
+		Index:    je.Index.Int(),
+		Severity: severityError,
+		Message:  je.Msg,
+
+		IncludeTrace: includeTrace(je),
+	}
+	d.Code, d.Layer = errorCode(je)
+	if je.File != nil {
+		d.File = je.File.Name()
+	}
+	return d
+}
+
+// includeTrace returns the include frames of the error. JSight API Core keeps
+// them private, but writes them into the error text: "path:line" per line
+// after the message and the location of the error itself.
+func includeTrace(je *jerr.JApiError) []includeFrame {
+	if !je.HasStackTrace() {
+		return nil
+	}
+
+	lines := strings.Split(strings.TrimPrefix(je.Error(), je.Msg), "\n")
+	if len(lines) < 3 {
+		return nil
+	}
+
+	ff := make([]includeFrame, 0, len(lines)-2)
+	for _, l := range lines[2:] {
+		i := strings.LastIndexByte(l, ':')
+		if i == -1 {
+			continue
+		}
+
+		n, err := strconv.Atoi(l[i+1:])
+		if err != nil {
+			continue
+		}
+
+		ff = append(ff, includeFrame{File: l[:i], Line: n})
+	}
+	return ff
+}
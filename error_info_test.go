@@ -21,6 +21,8 @@ func Test_newErrorInfo(t *testing.T) {
 				errorInfo{
 					Status:  "Error",
 					Message: "fake error",
+					Code:    30000,
+					Layer:   "server",
 				},
 			},
 
@@ -31,6 +33,83 @@ func Test_newErrorInfo(t *testing.T) {
 					Message: "fake error",
 					Line:    1,
 					Index:   2,
+					Code:    20000,
+					Layer:   "core",
+					File:    "foo",
+					Column:  3,
+				},
+			},
+
+			"JAPI error with include trace": {
+				func() error {
+					je := jerr.NewJApiError("fake error", fs.NewFile("types/cat.jst", []byte("123")), 2)
+					je.OccurredInFile(fs.NewFile("types/all.jst", []byte("INCLUDE cat.jst")), 0)
+					je.OccurredInFile(fs.NewFile("main.jst", []byte("JSIGHT 0.3\n\nINCLUDE types/all.jst")), 12)
+					return je
+				}(),
+				errorInfo{
+					Status:  "Error",
+					Message: "fake error\ntypes/cat.jst:1\ntypes/all.jst:1\nmain.jst:3",
+					Line:    1,
+					Index:   2,
+					Code:    20000,
+					Layer:   "core",
+					File:    "types/cat.jst",
+					Column:  3,
+					IncludeTrace: []includeFrame{
+						{File: "types/all.jst", Line: 1},
+						{File: "main.jst", Line: 3},
+					},
+				},
+			},
+
+			"OpenAPI validation error": {
+				&openapiValidationError{Pointer: "/paths/~1cats", Message: "fake error"},
+				errorInfo{
+					Status:  "Error",
+					Message: `the generated OpenAPI document is invalid at "/paths/~1cats": fake error`,
+					Code:    40001,
+					Layer:   "export",
+					Pointer: "/paths/~1cats",
+				},
+			},
+
+			"JAPI errors": {
+				japiErrors{
+					jerr.NewJApiError("fake error", fs.NewFile("foo", []byte("123")), 2),
+					jerr.NewJApiError(`Type "@cat" not found`, fs.NewFile("bar", []byte("1\n23")), 3),
+				},
+				errorInfo{
+					Status:  "Error",
+					Message: "fake error",
+					Line:    1,
+					Index:   2,
+					Code:    20000,
+					Layer:   "core",
+					File:    "foo",
+					Column:  3,
+					Errors: []errorDetail{
+						{
+							File:     "foo",
+							Line:     1,
+							Column:   3,
+							Index:    2,
+							Severity: "error",
+							Code:     20000,
+							Layer:    "core",
+							Message:  "fake error",
+						},
+						{
+							File:     "bar",
+							Line:     2,
+							Column:   2,
+							Index:    3,
+							Severity: "error",
+							Code:     1302,
+							Layer:    "schema",
+							Message:  `Type "@cat" not found`,
+						},
+					},
 				},
 			},
 		}
@@ -0,0 +1,952 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"strconv"
+	"strings"
+	"unicode"
+
+	schema "github.com/jsightapi/jsight-schema-core"
+	"github.com/jsightapi/jsight-schema-core/panics"
+
+	"github.com/jsightapi/jsight-api-core/catalog"
+	"github.com/jsightapi/jsight-api-core/kit"
+	"github.com/jsightapi/jsight-api-core/notation"
+)
+
+const goDefaultPackage = "api"
+
+// goFile is a generated Go source file.
+type goFile struct {
+	name    string
+	content []byte
+}
+
+// goServerZip returns the zip archive of the generated Go files.
+func goServerZip(jAPI kit.JApi, pkg string) ([]byte, error) {
+	ff, err := newGoServer(jAPI.Catalog(), pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range ff {
+		w, err := zw.Create(f.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(f.content); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// newGoServer generates the Go package with server stubs:
+//
+//   - models.go: types of enums, user types, path variables, queries, request
+//     and response bodies,
+//   - handler.go: the Handler interface with one method per HTTP interaction
+//     (named after the OperationId when present), the method parameters and
+//     response constructors,
+//   - router.go: NewRouter, which matches the request path, decodes path
+//     variables, query and body into the typed parameters and calls the
+//     handler.
+//
+// The generated code depends only on the standard library.
+func newGoServer(c *catalog.Catalog, pkg string) (ff []goFile, err error) {
+	defer func() {
+		err = panics.Handle(recover(), err)
+	}()
+
+	if pkg == "" {
+		pkg = goDefaultPackage
+	}
+	if !token.IsIdentifier(pkg) {
+		return nil, newRequestError(errorCodeInvalidParameter, "invalid package name %q", pkg)
+	}
+
+	g := goGenerator{
+		declared: map[string]bool{},
+		enums:    map[string]string{},
+		types:    map[string]string{},
+	}
+	for _, n := range goFixedNames {
+		g.declared[n] = true
+	}
+
+	c.UserEnums.EachSafe(func(k string, v *catalog.UserRule) {
+		g.enum(k, v)
+	})
+
+	// User types are declared before their code is generated, so references
+	// to types which come later get the declared names too.
+	c.UserTypes.EachSafe(func(k string, _ *catalog.UserType) {
+		g.types[k] = g.declare(goName(k))
+	})
+
+	err = c.UserTypes.Each(func(k string, v *catalog.UserType) error {
+		if err := g.userType(k, v); err != nil {
+			return fmt.Errorf("type %q: %w", k, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.Interactions.Each(func(_ catalog.InteractionID, v catalog.Interaction) error {
+		h, ok := v.(*catalog.HTTPInteraction)
+		if !ok {
+			return nil
+		}
+
+		if err := g.interaction(h); err != nil {
+			return fmt.Errorf("%s: %w", h.Id, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	files := []struct {
+		name    string
+		imports []string
+		code    string
+	}{
+		{"models.go", []string{"encoding/json"}, strings.Join(g.decls, "\n")},
+		{"handler.go", []string{"context", "net/http"}, g.handler()},
+		{"router.go", []string{"encoding/json", "fmt", "io", "net/http", "strings"}, g.router()},
+	}
+
+	for _, f := range files {
+		var b strings.Builder
+		b.WriteString("// Code generated by JSight Server. DO NOT EDIT.\n\n")
+		b.WriteString("package " + pkg + "\n")
+		if ii := goUsedImports(f.code, f.imports); len(ii) != 0 {
+			b.WriteString("\nimport (\n")
+			for _, i := range ii {
+				b.WriteString("\t" + strconv.Quote(i) + "\n")
+			}
+			b.WriteString(")\n")
+		}
+		b.WriteString("\n" + f.code)
+
+		src, err := format.Source([]byte(b.String()))
+		if err != nil {
+			return nil, fmt.Errorf("the generated file %s is invalid: %w", f.name, err)
+		}
+		ff = append(ff, goFile{name: f.name, content: src})
+	}
+
+	return ff, nil
+}
+
+// goFixedNames are the package-level names which handler.go and router.go
+// always declare. User types and operations never get them.
+var goFixedNames = []string{
+	"Response",
+	"Handler",
+	"NewRouter",
+	"route",
+	"routes",
+	"router",
+	"matchPath",
+	"decodeValue",
+	"writeResponse",
+}
+
+// goOperation describes the HTTP interaction for the handler and the router.
+type goOperation struct {
+	name     string
+	method   string
+	path     string
+	comment  string
+	pathVars []goField
+	query    []goField
+
+	// params, pathType, queryType and bodyType are the names of the declared
+	// types of the parameters.
+	params    string
+	pathType  string
+	queryType string
+	bodyType  string
+
+	// body the kind of the request body: "json", "text", "raw" or "" if the
+	// request has no body.
+	body string
+
+	responses []goResponse
+}
+
+type goResponse struct {
+	code string
+
+	// fn the name of the response constructor.
+	fn string
+
+	// typ the type of the body or "" if the response has no body.
+	typ string
+}
+
+// goField is a field of the generated struct.
+type goField struct {
+	name     string
+	key      string
+	typ      string
+	comment  string
+	optional bool
+}
+
+type goGenerator struct {
+	decls []string
+
+	// declared names of the declared types, functions and handler methods.
+	declared map[string]bool
+
+	// enums the Go types of user enums by their names with "@".
+	enums map[string]string
+
+	// types the Go types of user types by their names with "@".
+	types map[string]string
+
+	operations []goOperation
+}
+
+// declare reserves the unique name of the type, the function or the handler
+// method.
+func (g *goGenerator) declare(name string) string {
+	n := name
+	for i := 2; g.declared[n]; i++ {
+		n = name + strconv.Itoa(i)
+	}
+	g.declared[n] = true
+	return n
+}
+
+// addDecl adds the declaration and returns its index. Declarations of nested
+// types are added after the declaration of the type which uses them.
+func (g *goGenerator) addDecl() int {
+	g.decls = append(g.decls, "")
+	return len(g.decls) - 1
+}
+
+func (g *goGenerator) enum(name string, r *catalog.UserRule) {
+	typeName := g.declare(goName(name))
+	i := g.addDecl()
+
+	var b strings.Builder
+	writeGoDoc(&b, "", description(r.Description, r.Annotation))
+
+	strs := true
+	for _, c := range r.Value.Children {
+		if c.TokenType != catalog.RuleTokenTypeString {
+			strs = false
+		}
+	}
+
+	if !strs {
+		g.enums[name] = typeName
+		fmt.Fprintf(&b, "type %s = json.RawMessage\n", typeName)
+		g.decls[i] = b.String()
+		return
+	}
+
+	g.enums[name] = typeName
+	fmt.Fprintf(&b, "type %s string\n\nconst (\n", typeName)
+	for n, c := range r.Value.Children {
+		cn := typeName + goName(c.ScalarValue)
+		if g.declared[cn] {
+			cn = typeName + strconv.Itoa(n+1)
+		}
+		cn = g.declare(cn)
+		writeGoDoc(&b, "\t", c.Note)
+		fmt.Fprintf(&b, "\t%s %s = %s\n", cn, typeName, strconv.Quote(c.ScalarValue))
+	}
+	b.WriteString(")\n")
+	g.decls[i] = b.String()
+}
+
+func (g *goGenerator) userType(name string, ut *catalog.UserType) error {
+	typeName := g.types[name]
+	doc := description(ut.Description, ut.Annotation)
+
+	switch s := ut.Schema.(type) {
+	case *catalog.ExchangeJSightSchema:
+		node, err := s.GetAST()
+		if err != nil {
+			return err
+		}
+		g.namedType(typeName, doc, node, false)
+
+	case *catalog.ExchangeRegexSchema:
+		var b strings.Builder
+		writeGoDoc(&b, "", doc)
+		fmt.Fprintf(&b, "type %s string\n", typeName)
+		g.decls = append(g.decls, b.String())
+
+	default:
+		var b strings.Builder
+		writeGoDoc(&b, "", doc)
+		fmt.Fprintf(&b, "type %s any\n", typeName)
+		g.decls = append(g.decls, b.String())
+	}
+	return nil
+}
+
+// namedType declares the type for the AST node and returns the fields of the
+// struct. Objects become structs, other types become definitions (or aliases
+// if alias is true).
+func (g *goGenerator) namedType(name, doc string, node schema.ASTNode, alias bool) []goField {
+	i := g.addDecl()
+
+	var b strings.Builder
+	writeGoDoc(&b, "", doc)
+
+	var ff []goField
+	if g.isStruct(node) {
+		var st string
+		st, ff = g.structType(name, node)
+		fmt.Fprintf(&b, "type %s %s\n", name, st)
+	} else {
+		eq := " "
+		if alias {
+			eq = " = "
+		}
+		fmt.Fprintf(&b, "type %s%s%s\n", name, eq, g.goType(name, node))
+	}
+
+	g.decls[i] = b.String()
+	return ff
+}
+
+// isStruct reports whether the AST node is an object with properties.
+func (g *goGenerator) isStruct(node schema.ASTNode) bool {
+	if node.TokenType != schema.TokenTypeObject || ruleIsTrue(node, "nullable") {
+		return false
+	}
+	if _, ok := rule(node, "or"); ok {
+		return false
+	}
+	if _, ok := rule(node, "allOf"); ok {
+		return true
+	}
+	for _, p := range node.Children {
+		if !p.IsKeyShortcut {
+			return true
+		}
+	}
+	return false
+}
+
+// fields returns the fields of the struct for the object properties.
+func (g *goGenerator) fields(parent string, node schema.ASTNode) []goField {
+	ff := make([]goField, 0, len(node.Children))
+	names := map[string]bool{}
+
+	for _, p := range node.Children {
+		if p.IsKeyShortcut || p.InheritedFrom != "" {
+			continue
+		}
+
+		name := goName(p.Key)
+		if name == "" || !unicode.IsLetter(rune(name[0])) {
+			name = "F" + name
+		}
+		for i := 2; names[name]; i++ {
+			name = goName(p.Key) + strconv.Itoa(i)
+		}
+		names[name] = true
+
+		f := goField{
+			name:     name,
+			key:      p.Key,
+			typ:      g.goType(parent+name, p),
+			comment:  p.Comment,
+			optional: ruleIsTrue(p, "optional"),
+		}
+		if f.optional && !goNilable(f.typ) {
+			f.typ = "*" + f.typ
+		}
+		ff = append(ff, f)
+	}
+	return ff
+}
+
+func (g *goGenerator) structType(name string, node schema.ASTNode) (string, []goField) {
+	var b strings.Builder
+	b.WriteString("struct {\n")
+
+	for _, base := range g.allOf(node) {
+		b.WriteString("\t" + base + "\n")
+	}
+
+	ff := g.fields(name, node)
+	for _, f := range ff {
+		writeGoDoc(&b, "\t", f.comment)
+		tag := f.key
+		if f.optional {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(&b, "\t%s %s `json:%s`\n", f.name, f.typ, strconv.Quote(tag))
+	}
+
+	b.WriteString("}")
+	return b.String(), ff
+}
+
+// goType returns the Go type of the AST node. Nested objects are declared as
+// separate structs named after the suggested name.
+func (g *goGenerator) goType(name string, node schema.ASTNode) string {
+	t := g.nonNullType(name, node)
+	if ruleIsTrue(node, "nullable") && !goNilable(t) {
+		t = "*" + t
+	}
+	return t
+}
+
+func (g *goGenerator) nonNullType(name string, node schema.ASTNode) string {
+	if _, ok := rule(node, "or"); ok {
+		// Go has no union types, so the value is left to the handler.
+		return "json.RawMessage"
+	}
+
+	if enum, ok := rule(node, "enum"); ok {
+		if enum.TokenType != schema.TokenTypeArray {
+			if t, ok := g.enums[enum.Value]; ok {
+				return t
+			}
+		}
+		return goLiteralType(node, enum.Items)
+	}
+
+	switch schema.SchemaType(node.SchemaType) { //nolint:exhaustive // Other types are any.
+	case schema.SchemaTypeObject:
+		if g.isStruct(node) {
+			n := g.declare(name)
+			g.namedType(n, "", node, false)
+			return n
+		}
+		return "map[string]" + g.additionalType(name, node)
+
+	case schema.SchemaTypeArray:
+		switch len(node.Children) {
+		case 0:
+			return "[]any"
+		case 1:
+			return "[]" + g.goType(name+"Item", node.Children[0])
+		default:
+			return "[]json.RawMessage"
+		}
+
+	case schema.SchemaTypeString, schema.SchemaTypeEmail, schema.SchemaTypeURI, schema.SchemaTypeUUID,
+		schema.SchemaTypeDate, schema.SchemaTypeDateTime:
+		return "string"
+
+	case schema.SchemaTypeInteger:
+		return "int64"
+
+	case schema.SchemaTypeFloat, schema.SchemaTypeDecimal:
+		return "float64"
+
+	case schema.SchemaTypeBoolean:
+		return "bool"
+
+	default:
+		if strings.HasPrefix(node.SchemaType, "@") {
+			return g.userTypeName(node.SchemaType)
+		}
+		return "any"
+	}
+}
+
+// additionalType returns the type of additional properties of the object
+// without properties.
+func (g *goGenerator) additionalType(name string, node schema.ASTNode) string {
+	for _, p := range node.Children {
+		if p.IsKeyShortcut {
+			return "any"
+		}
+	}
+
+	additional, ok := rule(node, "additionalProperties")
+	if !ok || additional.Value == "true" || additional.Value == "false" ||
+		additional.Value == string(schema.SchemaTypeAny) {
+		return "any"
+	}
+	return g.goType(name+"Value", typeNode(additional.Value))
+}
+
+func (g *goGenerator) interaction(h *catalog.HTTPInteraction) error {
+	name := goName(strings.ToLower(h.HttpMethod.String()) + " " + h.Path().String())
+	if h.OperationId != nil && *h.OperationId != "" {
+		name = goName(*h.OperationId)
+	}
+	name = g.declare(name)
+
+	o := goOperation{
+		name:    name,
+		method:  h.HttpMethod.String(),
+		path:    h.Path().String(),
+		comment: h.HttpMethod.String() + " " + h.Path().String(),
+		params:  g.declare(name + "Params"),
+	}
+	if h.Annotation != nil {
+		o.comment += " " + *h.Annotation
+	}
+
+	if h.PathVariables != nil && h.PathVariables.Schema != nil {
+		node, err := h.PathVariables.Schema.GetAST()
+		if err != nil {
+			return err
+		}
+		o.pathType = g.declare(name + "Path")
+		o.pathVars = g.namedType(o.pathType, "", node, false)
+	}
+
+	if h.Query != nil && h.Query.Schema != nil {
+		node, err := h.Query.Schema.GetAST()
+		if err != nil {
+			return err
+		}
+		o.queryType = g.declare(name + "Query")
+		o.query = g.namedType(o.queryType, "", node, false)
+	}
+
+	if h.Request != nil && h.Request.HTTPRequestBody != nil {
+		o.bodyType = g.declare(name + "Request")
+		kind, err := g.body(o.bodyType, h.Request.HTTPRequestBody.Schema, true)
+		if err != nil {
+			return err
+		}
+		o.body = kind
+	}
+
+	for _, r := range h.Responses {
+		// Responses may have the same code, so the names are reserved as well.
+		resp := goResponse{code: r.Code, fn: g.declare("New" + name + "Response" + r.Code)}
+		if r.Body != nil {
+			typeName := g.declare(name + "Response" + r.Code)
+			kind, err := g.body(typeName, r.Body.Schema, false)
+			if err != nil {
+				return err
+			}
+			if kind != "" {
+				resp.typ = typeName
+			}
+		}
+		o.responses = append(o.responses, resp)
+	}
+
+	g.operations = append(g.operations, o)
+	return nil
+}
+
+// body declares the type of the body with the reserved name and returns its
+// kind: "json", "text", "raw" or "" for empty bodies.
+func (g *goGenerator) body(name string, es catalog.ExchangeSchema, request bool) (string, error) {
+	switch s := es.(type) {
+	case *catalog.ExchangeJSightSchema:
+		node, err := s.GetAST()
+		if err != nil {
+			return "", err
+		}
+		g.namedType(name, "", node, true)
+		return "json", nil
+
+	case *catalog.ExchangeRegexSchema:
+		g.decls = append(g.decls, fmt.Sprintf("type %s = string\n", name))
+		return "text", nil
+
+	default:
+		if es == nil || es.Notation() != notation.SchemaNotationAny {
+			return "", nil
+		}
+		typ := "any"
+		if request {
+			typ = "[]byte"
+		}
+		g.decls = append(g.decls, fmt.Sprintf("type %s = %s\n", name, typ))
+		return "raw", nil
+	}
+}
+
+func (g *goGenerator) handler() string {
+	var b strings.Builder
+
+	b.WriteString(`// Response is the HTTP response returned by the handler. A string body is
+// written as a plain text, a []byte body is written as is, other bodies are
+// encoded as JSON.
+type Response struct {
+	Status int
+	Header http.Header
+	Body   any
+}
+
+// Handler handles the HTTP interactions of the API.
+type Handler interface {
+`)
+	for _, o := range g.operations {
+		fmt.Fprintf(&b, "\t// %s\n", o.comment)
+		fmt.Fprintf(&b, "\t%s(ctx context.Context, params %s) (Response, error)\n", o.name, o.params)
+	}
+	b.WriteString("}\n")
+
+	for _, o := range g.operations {
+		fmt.Fprintf(&b, "\n// %s are the parameters of %s %s.\n", o.params, o.method, o.path)
+		fmt.Fprintf(&b, "type %s struct {\n", o.params)
+		if o.pathVars != nil {
+			fmt.Fprintf(&b, "\tPath %s\n", o.pathType)
+		}
+		if o.query != nil {
+			fmt.Fprintf(&b, "\tQuery %s\n", o.queryType)
+		}
+		b.WriteString("\tHeader http.Header\n")
+		if o.body != "" {
+			fmt.Fprintf(&b, "\tBody %s\n", o.bodyType)
+		}
+		b.WriteString("}\n")
+
+		for _, r := range o.responses {
+			if r.typ == "" {
+				fmt.Fprintf(&b, "\nfunc %s() Response {\n\treturn Response{Status: %s}\n}\n", r.fn, r.code)
+				continue
+			}
+			fmt.Fprintf(&b, "\nfunc %s(body %s) Response {\n\treturn Response{Status: %s, Body: body}\n}\n",
+				r.fn, r.typ, r.code)
+		}
+	}
+
+	return b.String()
+}
+
+func (g *goGenerator) router() string {
+	var b strings.Builder
+
+	b.WriteString(`type route struct {
+	method   string
+	segments []string
+	serve    func(h Handler, w http.ResponseWriter, r *http.Request, vars map[string]string)
+}
+
+var routes = []route{
+`)
+	for _, o := range g.operations {
+		segs := strings.Split(strings.Trim(o.path, "/"), "/")
+		qs := make([]string, 0, len(segs))
+		for _, s := range segs {
+			qs = append(qs, strconv.Quote(s))
+		}
+		fmt.Fprintf(&b, "\t{http.Method%s, []string{%s}, serve%s},\n",
+			goName(strings.ToLower(o.method)), strings.Join(qs, ", "), o.name)
+	}
+	b.WriteString("}\n")
+
+	b.WriteString(goRouter)
+
+	for _, o := range g.operations {
+		fmt.Fprintf(&b, "\nfunc serve%s(h Handler, w http.ResponseWriter, r *http.Request, vars map[string]string) {\n", o.name)
+		fmt.Fprintf(&b, "\tp := %s{Header: r.Header}\n", o.params)
+
+		if len(o.pathVars) != 0 {
+			b.WriteString("\n")
+		}
+		for _, f := range o.pathVars {
+			fmt.Fprintf(&b, "\tif err := decodeValue(vars[%q], &p.Path.%s); err != nil {\n", f.key, f.name)
+			fmt.Fprintf(&b, "\t\thttp.Error(w, fmt.Sprintf(\"the path variable %%q is invalid: %%s\", %q, err), http.StatusBadRequest)\n", f.key)
+			b.WriteString("\t\treturn\n\t}\n")
+		}
+
+		if len(o.query) != 0 {
+			b.WriteString("\n\tquery := r.URL.Query()\n")
+		}
+		for _, f := range o.query {
+			fmt.Fprintf(&b, "\tif v, ok := query[%q]; ok {\n", f.key)
+			fmt.Fprintf(&b, "\t\tif err := decodeValue(v[0], &p.Query.%s); err != nil {\n", f.name)
+			fmt.Fprintf(&b, "\t\t\thttp.Error(w, fmt.Sprintf(\"the query parameter %%q is invalid: %%s\", %q, err), http.StatusBadRequest)\n", f.key)
+			b.WriteString("\t\t\treturn\n\t\t}\n")
+			if !f.optional {
+				b.WriteString("\t} else {\n")
+				fmt.Fprintf(&b, "\t\thttp.Error(w, fmt.Sprintf(\"the query parameter %%q is required\", %q), http.StatusBadRequest)\n", f.key)
+				b.WriteString("\t\treturn\n")
+			}
+			b.WriteString("\t}\n")
+		}
+
+		switch o.body {
+		case "json":
+			b.WriteString(`
+	if err := json.NewDecoder(r.Body).Decode(&p.Body); err != nil {
+		http.Error(w, fmt.Sprintf("the request body is invalid: %s", err), http.StatusBadRequest)
+		return
+	}
+`)
+		case "text", "raw":
+			b.WriteString(`
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("the request body is invalid: %s", err), http.StatusBadRequest)
+		return
+	}
+`)
+			if o.body == "text" {
+				b.WriteString("\tp.Body = string(body)\n")
+			} else {
+				b.WriteString("\tp.Body = body\n")
+			}
+		}
+
+		fmt.Fprintf(&b, "\n\tresp, err := h.%s(r.Context(), p)\n", o.name)
+		b.WriteString(`	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeResponse(w, resp)
+}
+`)
+	}
+
+	return b.String()
+}
+
+const goRouter = `
+// NewRouter returns the HTTP handler which routes requests to the handler
+// methods.
+func NewRouter(h Handler) http.Handler {
+	return router{h: h}
+}
+
+type router struct {
+	h Handler
+}
+
+func (rt router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	found := false
+	for _, route := range routes {
+		vars, ok := matchPath(route.segments, segments)
+		if !ok {
+			continue
+		}
+		found = true
+		if route.method == r.Method {
+			route.serve(rt.h, w, r, vars)
+			return
+		}
+	}
+
+	if found {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	http.NotFound(w, r)
+}
+
+// matchPath matches the path against the template and returns the values of
+// the path variables.
+func matchPath(template, path []string) (map[string]string, bool) {
+	if len(template) != len(path) {
+		return nil, false
+	}
+
+	vars := map[string]string{}
+	for i, t := range template {
+		if strings.HasPrefix(t, "{") && strings.HasSuffix(t, "}") {
+			vars[t[1:len(t)-1]] = path[i]
+			continue
+		}
+		if t != path[i] {
+			return nil, false
+		}
+	}
+	return vars, true
+}
+
+// decodeValue decodes the value of the path variable or the query parameter.
+// Strings are accepted without quotes.
+func decodeValue(s string, v any) error {
+	if err := json.Unmarshal([]byte(s), v); err == nil {
+		return nil
+	}
+
+	q, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(q, v)
+}
+
+func writeResponse(w http.ResponseWriter, resp Response) {
+	for k, vv := range resp.Header {
+		for _, v := range vv {
+			w.Header().Add(k, v)
+		}
+	}
+
+	var body []byte
+	switch b := resp.Body.(type) {
+	case nil:
+	case string:
+		body = []byte(b)
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		}
+	case []byte:
+		body = b
+	default:
+		js, err := json.Marshal(b)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		body = js
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		}
+	}
+
+	w.WriteHeader(resp.Status)
+	_, _ = w.Write(body)
+}
+`
+
+// goUsedImports returns the imports which are used in the code.
+func goUsedImports(code string, imports []string) []string {
+	var ii []string
+	for _, i := range imports {
+		name := i[strings.LastIndex(i, "/")+1:]
+		if strings.Contains(code, name+".") {
+			ii = append(ii, i)
+		}
+	}
+	return ii
+}
+
+// allOf returns the Go names of the base types of the object.
+func (g *goGenerator) allOf(node schema.ASTNode) []string {
+	allOf, ok := rule(node, "allOf")
+	if !ok {
+		return nil
+	}
+
+	if allOf.TokenType != schema.TokenTypeArray {
+		return []string{g.userTypeName(allOf.Value)}
+	}
+
+	nn := make([]string, 0, len(allOf.Items))
+	for _, i := range allOf.Items {
+		nn = append(nn, g.userTypeName(i.Value))
+	}
+	return nn
+}
+
+// userTypeName returns the declared Go name of the user type.
+func (g *goGenerator) userTypeName(name string) string {
+	if n, ok := g.types[name]; ok {
+		return n
+	}
+	return goName(name)
+}
+
+// goLiteralType returns the type of the scalar with the enum rule.
+func goLiteralType(node schema.ASTNode, items []schema.RuleASTNode) string {
+	switch node.TokenType { //nolint:exhaustive // Other types are any.
+	case schema.TokenTypeString:
+		return "string"
+	case schema.TokenTypeBoolean:
+		return "bool"
+	case schema.TokenTypeNumber:
+		for _, i := range items {
+			if i.TokenType == schema.TokenTypeNumber && strings.ContainsAny(i.Value, ".eE") {
+				return "float64"
+			}
+		}
+		if strings.ContainsAny(node.Value, ".eE") {
+			return "float64"
+		}
+		return "int64"
+	default:
+		return "any"
+	}
+}
+
+func goNilable(t string) bool {
+	return t == "any" || t == "json.RawMessage" ||
+		strings.HasPrefix(t, "*") || strings.HasPrefix(t, "[]") || strings.HasPrefix(t, "map[")
+}
+
+func writeGoDoc(b *strings.Builder, indent, s string) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return
+	}
+	for _, l := range strings.Split(s, "\n") {
+		b.WriteString(strings.TrimRight(indent+"// "+strings.TrimSpace(l), " ") + "\n")
+	}
+}
+
+// goInitialisms are the words which are written in upper case in Go names.
+var goInitialisms = map[string]bool{
+	"API": true, "HTML": true, "HTTP": true, "ID": true, "IP": true, "JSON": true,
+	"URI": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// goName converts the JSight name to an exported Go identifier, e.g.
+// "@cat-info" becomes "CatInfo" and "petId" becomes "PetID".
+func goName(s string) string {
+	var b strings.Builder
+	for _, w := range goWords(s) {
+		if u := strings.ToUpper(w); goInitialisms[u] {
+			b.WriteString(u)
+			continue
+		}
+		rr := []rune(w)
+		rr[0] = unicode.ToUpper(rr[0])
+		b.WriteString(string(rr))
+	}
+
+	n := b.String()
+	if n != "" && unicode.IsDigit(rune(n[0])) {
+		n = "T" + n
+	}
+	return n
+}
+
+// goWords splits the name into words by non-alphanumeric characters and by
+// lower-to-upper case transitions.
+func goWords(s string) []string {
+	var ww []string
+	var w []rune
+	var prev rune
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(w) != 0 {
+				ww = append(ww, string(w))
+				w = nil
+			}
+			prev = 0
+			continue
+		}
+		if unicode.IsUpper(r) && (unicode.IsLower(prev) || unicode.IsDigit(prev)) && len(w) != 0 {
+			ww = append(ww, string(w))
+			w = nil
+		}
+		w = append(w, r)
+		prev = r
+	}
+	if len(w) != 0 {
+		ww = append(ww, string(w))
+	}
+	return ww
+}
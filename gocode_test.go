@@ -0,0 +1,307 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsightapi/jsight-schema-core/fs"
+
+	"github.com/jsightapi/jsight-api-core/kit"
+)
+
+const testGoAPI = `JSIGHT 0.3
+
+ENUM @sizes
+[
+  "s", // Small.
+  "m"
+]
+
+TYPE @named // A named thing.
+{
+  "name": "Tom",
+  "petId": 1 // {optional: true}
+}
+
+TYPE @cat
+{ // {allOf: "@named"}
+  "size": "s", // {enum: @sizes}
+  "owner": @named, // {nullable: true}
+  "toys": [
+    {
+      "title": "ball"
+    }
+  ],
+  "extra": {} // {additionalProperties: "string"}
+}
+
+GET /cats/{id}
+  Path
+  {
+    "id": 1
+  }
+  Query
+  {
+    "size": "s", // {enum: @sizes}
+    "limit": 1 // {optional: true}
+  }
+  200 [@cat]
+  404 regex
+    /^not found$/
+
+POST /cats
+  OperationId "createCat"
+  Request @cat
+  204 empty
+`
+
+func Test_goServerZip(t *testing.T) {
+	t.Run("positive", func(t *testing.T) {
+		jAPI, je := kit.NewJApiFromFile(fs.NewFile("root", []byte(testGoAPI)))
+		require.Nil(t, je)
+
+		b, err := goServerZip(jAPI, "pets")
+		require.NoError(t, err)
+
+		ff := unzipFiles(t, b)
+		require.Len(t, ff, 3)
+		typeCheckGoPackage(t, ff)
+
+		assert.Equal(t, `// Code generated by JSight Server. DO NOT EDIT.
+
+package pets
+
+type Sizes string
+
+const (
+	// Small.
+	SizesS Sizes = "s"
+	SizesM Sizes = "m"
+)
+
+// A named thing.
+type Named struct {
+	Name  string `+"`"+`json:"name"`+"`"+`
+	PetID *int64 `+"`"+`json:"petId,omitempty"`+"`"+`
+}
+
+type Cat struct {
+	Named
+	Size  Sizes             `+"`"+`json:"size"`+"`"+`
+	Owner *Named            `+"`"+`json:"owner"`+"`"+`
+	Toys  []CatToysItem     `+"`"+`json:"toys"`+"`"+`
+	Extra map[string]string `+"`"+`json:"extra"`+"`"+`
+}
+
+type CatToysItem struct {
+	Title string `+"`"+`json:"title"`+"`"+`
+}
+
+type GetCatsIDPath struct {
+	ID int64 `+"`"+`json:"id"`+"`"+`
+}
+
+type GetCatsIDQuery struct {
+	Size  Sizes  `+"`"+`json:"size"`+"`"+`
+	Limit *int64 `+"`"+`json:"limit,omitempty"`+"`"+`
+}
+
+type GetCatsIDResponse200 = []Cat
+
+type GetCatsIDResponse404 = string
+
+type CreateCatRequest = Cat
+`, ff["models.go"])
+
+		handler := ff["handler.go"]
+		assert.Contains(t, handler, `type Handler interface {
+	// GET /cats/{id}
+	GetCatsID(ctx context.Context, params GetCatsIDParams) (Response, error)
+	// POST /cats
+	CreateCat(ctx context.Context, params CreateCatParams) (Response, error)
+}`)
+		assert.Contains(t, handler, `type GetCatsIDParams struct {
+	Path   GetCatsIDPath
+	Query  GetCatsIDQuery
+	Header http.Header
+}`)
+		assert.Contains(t, handler, `func NewCreateCatResponse204() Response {
+	return Response{Status: 204}
+}`)
+
+		router := ff["router.go"]
+		assert.Contains(t, router, `var routes = []route{
+	{http.MethodGet, []string{"cats", "{id}"}, serveGetCatsID},
+	{http.MethodPost, []string{"cats"}, serveCreateCat},
+}`)
+		assert.Contains(t, router, `	if err := decodeValue(vars["id"], &p.Path.ID); err != nil {`)
+		assert.Contains(t, router, `	if v, ok := query["size"]; ok {
+		if err := decodeValue(v[0], &p.Query.Size); err != nil {
+			http.Error(w, fmt.Sprintf("the query parameter %q is invalid: %s", "size", err), http.StatusBadRequest)
+			return
+		}
+	} else {
+		http.Error(w, fmt.Sprintf("the query parameter %q is required", "size"), http.StatusBadRequest)
+		return
+	}`)
+		assert.Contains(t, router, `	if err := json.NewDecoder(r.Body).Decode(&p.Body); err != nil {`)
+		assert.NotContains(t, router, `"io"`)
+	})
+
+	t.Run("colliding names", func(t *testing.T) {
+		jAPI, je := kit.NewJApiFromFile(fs.NewFile("root", []byte(`JSIGHT 0.3
+
+TYPE @getCatsListParams
+{
+  "page": 1
+}
+
+GET /cats-list
+  200 [@getCatsListParams]
+  200 empty
+
+GET /cats_list
+  Query
+  {
+    "page": 1
+  }
+  200 any
+`)))
+		require.Nil(t, je)
+
+		b, err := goServerZip(jAPI, "")
+		require.NoError(t, err)
+
+		ff := unzipFiles(t, b)
+		typeCheckGoPackage(t, ff)
+
+		assert.Contains(t, ff["handler.go"], `type Handler interface {
+	// GET /cats-list
+	GetCatsList(ctx context.Context, params GetCatsListParams2) (Response, error)
+	// GET /cats_list
+	GetCatsList2(ctx context.Context, params GetCatsList2Params) (Response, error)
+}`)
+		assert.Contains(t, ff["handler.go"], "func NewGetCatsListResponse200(body GetCatsListResponse200) Response {")
+		assert.Contains(t, ff["handler.go"], "func NewGetCatsListResponse2002() Response {")
+		assert.Contains(t, ff["handler.go"], "func NewGetCatsList2Response200(body GetCatsList2Response200) Response {")
+	})
+
+	t.Run("fixed names", func(t *testing.T) {
+		jAPI, je := kit.NewJApiFromFile(fs.NewFile("root", []byte(`JSIGHT 0.3
+
+TYPE @Handler
+{
+  "id": 1
+}
+
+TYPE @NewRouter
+{
+  "name": "Tom"
+}
+
+TYPE @response
+{
+  "size": "s" // {enum: @sizes}
+}
+
+TYPE @sizesS
+{
+  "small": true
+}
+
+ENUM @sizes
+[
+  "s",
+  "m"
+]
+
+POST /handler
+  Request @Handler
+  200 @NewRouter
+  201 @sizesS
+`)))
+		require.Nil(t, je)
+
+		b, err := goServerZip(jAPI, "")
+		require.NoError(t, err)
+
+		ff := unzipFiles(t, b)
+		typeCheckGoPackage(t, ff)
+
+		assert.Contains(t, ff["models.go"], "type Handler2 struct {")
+		assert.Contains(t, ff["models.go"], "type NewRouter2 struct {")
+		assert.Contains(t, ff["models.go"], "type Response2 struct {")
+		assert.Contains(t, ff["models.go"], "type SizesS2 struct {")
+		assert.Contains(t, ff["models.go"], "type PostHandlerResponse200 = NewRouter2")
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		jAPI, je := kit.NewJApiFromFile(fs.NewFile("root", []byte("JSIGHT 0.3\n")))
+		require.Nil(t, je)
+
+		_, err := goServerZip(jAPI, "my-api")
+		assert.EqualError(t, err, `invalid package name "my-api"`)
+	})
+}
+
+func Test_goName(t *testing.T) {
+	cc := map[string]string{
+		"@cat":           "Cat",
+		"@cat-info_v2":   "CatInfoV2",
+		"petId":          "PetID",
+		"get /cats/{id}": "GetCatsID",
+		"listPets":       "ListPets",
+		"@1st":           "T1st",
+		"apiURL":         "APIURL",
+	}
+
+	for given, expected := range cc {
+		t.Run(given, func(t *testing.T) {
+			assert.Equal(t, expected, goName(given))
+		})
+	}
+}
+
+// typeCheckGoPackage checks that the generated files are the Go package which
+// compiles.
+func typeCheckGoPackage(t *testing.T, ff map[string]string) {
+	fset := token.NewFileSet()
+	files := make([]*ast.File, 0, len(ff))
+	for name, src := range ff {
+		f, err := parser.ParseFile(fset, name, src, 0)
+		require.NoError(t, err)
+		files = append(files, f)
+	}
+
+	conf := types.Config{Importer: importer.Default()}
+	_, err := conf.Check(files[0].Name.Name, fset, files, nil)
+	require.NoError(t, err)
+}
+
+func unzipFiles(t *testing.T, b []byte) map[string]string {
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	require.NoError(t, err)
+
+	ff := map[string]string{}
+	for _, f := range zr.File {
+		r, err := f.Open()
+		require.NoError(t, err)
+
+		content, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.NoError(t, r.Close())
+
+		ff[f.Name] = string(content)
+	}
+	return ff
+}
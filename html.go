@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"html/template"
+
+	"github.com/jsightapi/jsight-api-core/kit"
+)
+
+func htmlDoc(jAPI kit.JApi) ([]byte, error) {
+	page, err := newDocPage(jAPI.Catalog())
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	if err := htmlTemplate.Execute(&b, page); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+var htmlTemplate = template.Must(template.New("page").Parse(htmlTemplateText))
+
+const htmlTemplateText = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="generator" content="JSight Server">
+<title>{{.Title}}</title>
+<style>
+body { margin: 0; font: 15px/1.5 -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #24292f; display: flex; }
+nav { position: sticky; top: 0; align-self: flex-start; width: 280px; height: 100vh; overflow-y: auto; padding: 16px; box-sizing: border-box; background: #f6f8fa; border-right: 1px solid #d0d7de; }
+nav ul { list-style: none; margin: 0; padding-left: 12px; }
+nav > ul { padding-left: 0; }
+nav a { color: #0969da; text-decoration: none; }
+nav a:hover { text-decoration: underline; }
+main { flex: 1; min-width: 0; max-width: 960px; padding: 16px 32px; }
+h1, h2, h3 { border-bottom: 1px solid #d8dee4; padding-bottom: 4px; }
+.description { white-space: pre-line; }
+.method { display: inline-block; min-width: 64px; padding: 0 6px; margin-right: 8px; border-radius: 4px; background: #0969da; color: #fff; font-size: 13px; text-align: center; }
+.interaction { margin: 24px 0; }
+.schemas { display: flex; gap: 16px; flex-wrap: wrap; }
+.schemas > div { flex: 1; min-width: 280px; }
+pre { margin: 4px 0 12px; padding: 12px; overflow-x: auto; border-radius: 6px; background: #f6f8fa; font: 13px/1.45 SFMono-Regular, Consolas, Menlo, monospace; }
+.label { font-size: 13px; color: #57606a; }
+</style>
+</head>
+<body>
+<nav>
+<strong>{{.Title}}</strong>
+<ul>
+{{- range .Tags}}{{template "tagNav" .}}{{end}}
+{{- if .Types}}
+<li><a href="#types">Types</a></li>
+{{- end}}
+{{- if .Enums}}
+<li><a href="#enums">Enums</a></li>
+{{- end}}
+</ul>
+</nav>
+<main>
+<h1>{{.Title}}</h1>
+{{- if .Version}}
+<p class="label">Version {{.Version}}</p>
+{{- end}}
+{{- if .Description}}
+<div class="description">{{.Description}}</div>
+{{- end}}
+{{- if .Servers}}
+<h2>Servers</h2>
+<ul>
+{{- range .Servers}}
+<li><code>{{.BaseURL}}</code> {{.Name}}{{if .Annotation}} — {{.Annotation}}{{end}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- range .Tags}}{{template "tag" .}}{{end}}
+{{- if .Types}}
+<h2 id="types">Types</h2>
+{{- range .Types}}{{template "userType" .}}{{end}}
+{{- end}}
+{{- if .Enums}}
+<h2 id="enums">Enums</h2>
+{{- range .Enums}}{{template "userType" .}}{{end}}
+{{- end}}
+</main>
+</body>
+</html>
+{{define "tagNav"}}
+<li><a href="#{{.Anchor}}">{{.Title}}</a>
+<ul>
+{{- range .Interactions}}{{if not .Ref}}
+<li><a href="#{{.Anchor}}">{{.Method}} {{.Path}}</a></li>
+{{- end}}{{end}}
+{{- range .Children}}{{template "tagNav" .}}{{end}}
+</ul>
+</li>
+{{- end}}
+{{define "tag"}}
+<section id="{{.Anchor}}">
+<h2>{{.Title}}</h2>
+{{- if .Description}}
+<div class="description">{{.Description}}</div>
+{{- end}}
+{{- range .Interactions}}{{template "interaction" .}}{{end}}
+{{- range .Children}}{{template "tag" .}}{{end}}
+</section>
+{{- end}}
+{{define "interaction"}}
+{{- if .Ref}}
+<p><a href="#{{.Anchor}}"><span class="method">{{.Method}}</span><code>{{.Path}}</code></a></p>
+{{- else}}
+<article class="interaction" id="{{.Anchor}}">
+<h3><span class="method">{{.Method}}</span><code>{{.Path}}</code></h3>
+{{- if .Annotation}}
+<p>{{.Annotation}}</p>
+{{- end}}
+{{- if .Description}}
+<div class="description">{{.Description}}</div>
+{{- end}}
+{{- range .Parts}}{{template "schema" .}}{{end}}
+{{- range .Responses}}
+<h4>{{.Code}}{{if .Annotation}} — {{.Annotation}}{{end}}</h4>
+{{- range .Parts}}{{template "schema" .}}{{end}}
+{{- end}}
+</article>
+{{- end}}
+{{- end}}
+{{define "schema"}}
+{{- if .Title}}
+<div class="label">{{.Title}}</div>
+{{- end}}
+<div class="schemas">
+<div><pre><code>{{.Code}}</code></pre></div>
+{{- if .Example}}
+<div><div class="label">Example</div><pre><code>{{.Example}}</code></pre></div>
+{{- end}}
+</div>
+{{- end}}
+{{define "userType"}}
+<article id="{{.Anchor}}">
+<h3><code>{{.Name}}</code></h3>
+{{- if .Description}}
+<div class="description">{{.Description}}</div>
+{{- end}}
+{{- template "schema" .Schema}}
+</article>
+{{- end}}
+`
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsightapi/jsight-schema-core/fs"
+
+	"github.com/jsightapi/jsight-api-core/kit"
+)
+
+const testHTMLAPI = `JSIGHT 0.3
+
+INFO
+  Title "Cats <API>"
+  Version "1.0"
+
+SERVER @prod
+  BaseUrl "https://cats.com/api"
+
+TAG @cats // Cats
+  Description
+  (
+    All about cats.
+  )
+
+TYPE @cat // A cat.
+{
+  "id": 1, // {min: 1} - The ID.
+  "tags": [ // {optional: true}
+    "a"
+  ],
+  "color": "red", // {enum: @color}
+  @name: "x" // {optional: true}
+}
+
+TYPE @name regex
+  /^x$/
+
+ENUM @color
+[
+  "red", // Red.
+  "black"
+]
+
+GET /cats/{id} // Get a cat.
+  Tags @cats
+  Path
+  {
+    "id": 1
+  }
+  200 @cat
+  404 regex
+    /^not found$/
+
+URL /rpc
+  Protocol json-rpc-2.0
+  Method ping
+    Params
+    {
+      "n": 1
+    }
+`
+
+func Test_htmlDoc(t *testing.T) {
+	t.Run("positive", func(t *testing.T) {
+		jAPI, je := kit.NewJApiFromFile(fs.NewFile("root", []byte(testHTMLAPI)))
+		require.Nil(t, je)
+
+		b, err := htmlDoc(jAPI)
+		require.NoError(t, err)
+
+		h := string(b)
+		for _, s := range []string{
+			"<!DOCTYPE html>",
+			"<title>Cats &lt;API&gt;</title>",
+			`<p class="label">Version 1.0</p>`,
+			"<li><code>https://cats.com/api</code> @prod</li>",
+			`<li><a href="#tag-cats">Cats</a>`,
+			`<li><a href="#interaction-http-get-cats-id">GET /cats/{id}</a></li>`,
+			`<div class="description">All about cats.</div>`,
+			`<h3><span class="method">GET</span><code>/cats/{id}</code></h3>`,
+			"<p>Get a cat.</p>",
+			`<div class="label">Path variables</div>`,
+			"<h4>404</h4>",
+			"<pre><code>/^not found$/</code></pre>",
+			`<h3><span class="method">JSON-RPC</span><code>/rpc ping</code></h3>`,
+			`<div class="label">Params</div>`,
+			`<article id="type-cat">`,
+			`<div class="description">A cat.</div>`,
+			`<article id="enum-color">`,
+			"<pre><code>[\n  &#34;red&#34;, // Red.\n  &#34;black&#34;\n]</code></pre>",
+		} {
+			assert.Contains(t, h, s)
+		}
+		assert.NotContains(t, h, "<script")
+		assert.NotContains(t, h, "<link")
+	})
+}
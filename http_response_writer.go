@@ -33,8 +33,36 @@ func (r httpResponseWriter) yaml(b []byte) {
 	log.Printf("... Ok (%d bytes)", n)
 }
 
-func (r httpResponseWriter) errorStr(s string) {
-	r.error(errors.New(s))
+func (r httpResponseWriter) typescript(b []byte) {
+	r.writer.Header().Set("Content-Type", "text/x-typescript; charset=utf-8")
+	n, _ := r.writer.Write(b)
+
+	log.Printf("... Ok (%d bytes)", n)
+}
+
+func (r httpResponseWriter) zip(b []byte) {
+	r.writer.Header().Set("Content-Type", "application/zip")
+	n, _ := r.writer.Write(b)
+
+	log.Printf("... Ok (%d bytes)", n)
+}
+
+func (r httpResponseWriter) html(b []byte) {
+	r.writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+	n, _ := r.writer.Write(b)
+
+	log.Printf("... Ok (%d bytes)", n)
+}
+
+func (r httpResponseWriter) markdown(b []byte) {
+	r.writer.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	n, _ := r.writer.Write(b)
+
+	log.Printf("... Ok (%d bytes)", n)
+}
+
+func (r httpResponseWriter) errorStr(code int, s string) {
+	r.error(&requestError{code: code, err: errors.New(s)})
 }
 
 func (r httpResponseWriter) error(e error) {
@@ -46,12 +74,23 @@ func (r httpResponseWriter) error(e error) {
 	}
 
 	r.writer.Header().Set("Content-Type", "application/json; charset=utf-8")
-	r.writer.WriteHeader(http.StatusConflict)
+	r.writer.WriteHeader(errorStatus(e))
 	_, _ = r.writer.Write(b)
 
 	log.Print("... " + e.Error())
 }
 
+// errorStatus returns the HTTP status of the error response. Errors in the
+// documents generated by the server are server errors, other errors are caused
+// by the request.
+func errorStatus(e error) int {
+	var ve *openapiValidationError
+	if errors.As(e, &ve) {
+		return http.StatusInternalServerError
+	}
+	return http.StatusConflict
+}
+
 func (r httpResponseWriter) internalServerError(e error) {
 	r.writer.Header().Set("Content-Type", "text/plain")
 	r.writer.WriteHeader(http.StatusInternalServerError)
@@ -121,6 +121,150 @@ func Test_httpResponseYAML200(t *testing.T) {
 	})
 }
 
+func Test_httpResponseTypeScript200(t *testing.T) {
+	t.Run("positive", func(t *testing.T) {
+		t.Run("with content", func(t *testing.T) {
+			const content = "foobar"
+			r := httptest.NewRecorder()
+			wr := httpResponseWriter{writer: r}
+
+			wr.typescript([]byte(content))
+
+			assert.Equal(t, http.StatusOK, r.Code)
+			assert.Len(t, r.Header(), 1)
+			assert.Equal(t, "text/x-typescript; charset=utf-8", r.Header().Get("Content-Type"))
+			assert.Equal(t, content, r.Body.String())
+		})
+
+		t.Run("nil content", func(t *testing.T) {
+			r := httptest.NewRecorder()
+			wr := httpResponseWriter{writer: r}
+
+			wr.typescript(nil)
+
+			assert.Equal(t, http.StatusOK, r.Code)
+			assert.Len(t, r.Header(), 1)
+			assert.Equal(t, "text/x-typescript; charset=utf-8", r.Header().Get("Content-Type"))
+			assert.Equal(t, "", r.Body.String())
+		})
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		assert.Panics(t, func() {
+			wr := httpResponseWriter{}
+			wr.typescript(nil)
+		})
+	})
+}
+
+func Test_httpResponseZip200(t *testing.T) {
+	t.Run("positive", func(t *testing.T) {
+		t.Run("with content", func(t *testing.T) {
+			const content = "foobar"
+			r := httptest.NewRecorder()
+			wr := httpResponseWriter{writer: r}
+
+			wr.zip([]byte(content))
+
+			assert.Equal(t, http.StatusOK, r.Code)
+			assert.Len(t, r.Header(), 1)
+			assert.Equal(t, "application/zip", r.Header().Get("Content-Type"))
+			assert.Equal(t, content, r.Body.String())
+		})
+
+		t.Run("nil content", func(t *testing.T) {
+			r := httptest.NewRecorder()
+			wr := httpResponseWriter{writer: r}
+
+			wr.zip(nil)
+
+			assert.Equal(t, http.StatusOK, r.Code)
+			assert.Len(t, r.Header(), 1)
+			assert.Equal(t, "application/zip", r.Header().Get("Content-Type"))
+			assert.Equal(t, "", r.Body.String())
+		})
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		assert.Panics(t, func() {
+			wr := httpResponseWriter{}
+			wr.zip(nil)
+		})
+	})
+}
+
+func Test_httpResponseHTML200(t *testing.T) {
+	t.Run("positive", func(t *testing.T) {
+		t.Run("with content", func(t *testing.T) {
+			const content = "foobar"
+			r := httptest.NewRecorder()
+			wr := httpResponseWriter{writer: r}
+
+			wr.html([]byte(content))
+
+			assert.Equal(t, http.StatusOK, r.Code)
+			assert.Len(t, r.Header(), 1)
+			assert.Equal(t, "text/html; charset=utf-8", r.Header().Get("Content-Type"))
+			assert.Equal(t, content, r.Body.String())
+		})
+
+		t.Run("nil content", func(t *testing.T) {
+			r := httptest.NewRecorder()
+			wr := httpResponseWriter{writer: r}
+
+			wr.html(nil)
+
+			assert.Equal(t, http.StatusOK, r.Code)
+			assert.Len(t, r.Header(), 1)
+			assert.Equal(t, "text/html; charset=utf-8", r.Header().Get("Content-Type"))
+			assert.Equal(t, "", r.Body.String())
+		})
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		assert.Panics(t, func() {
+			wr := httpResponseWriter{}
+			wr.html(nil)
+		})
+	})
+}
+
+func Test_httpResponseMarkdown200(t *testing.T) {
+	t.Run("positive", func(t *testing.T) {
+		t.Run("with content", func(t *testing.T) {
+			const content = "foobar"
+			r := httptest.NewRecorder()
+			wr := httpResponseWriter{writer: r}
+
+			wr.markdown([]byte(content))
+
+			assert.Equal(t, http.StatusOK, r.Code)
+			assert.Len(t, r.Header(), 1)
+			assert.Equal(t, "text/markdown; charset=utf-8", r.Header().Get("Content-Type"))
+			assert.Equal(t, content, r.Body.String())
+		})
+
+		t.Run("nil content", func(t *testing.T) {
+			r := httptest.NewRecorder()
+			wr := httpResponseWriter{writer: r}
+
+			wr.markdown(nil)
+
+			assert.Equal(t, http.StatusOK, r.Code)
+			assert.Len(t, r.Header(), 1)
+			assert.Equal(t, "text/markdown; charset=utf-8", r.Header().Get("Content-Type"))
+			assert.Equal(t, "", r.Body.String())
+		})
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		assert.Panics(t, func() {
+			wr := httpResponseWriter{}
+			wr.markdown(nil)
+		})
+	})
+}
+
 func Test_httpResponse409(t *testing.T) {
 	t.Run("positive", func(t *testing.T) {
 		t.Run("err", func(t *testing.T) {
@@ -132,19 +276,19 @@ func Test_httpResponse409(t *testing.T) {
 			assert.Equal(t, http.StatusConflict, r.Code)
 			assert.Len(t, r.Header(), 1)
 			assert.Equal(t, "application/json; charset=utf-8", r.Header().Get("Content-Type"))
-			assert.Equal(t, `{"Status":"Error","Message":"fake error","Line":0,"Index":0}`, r.Body.String())
+			assert.Equal(t, `{"Status":"Error","Message":"fake error","Line":0,"Index":0,"Code":30000,"Layer":"server"}`, r.Body.String())
 		})
 
 		t.Run("string", func(t *testing.T) {
 			r := httptest.NewRecorder()
 			wr := httpResponseWriter{writer: r}
 
-			wr.errorStr("fake error")
+			wr.errorStr(errorCodePOSTRequired, "fake error")
 
 			assert.Equal(t, http.StatusConflict, r.Code)
 			assert.Len(t, r.Header(), 1)
 			assert.Equal(t, "application/json; charset=utf-8", r.Header().Get("Content-Type"))
-			assert.Equal(t, `{"Status":"Error","Message":"fake error","Line":0,"Index":0}`, r.Body.String())
+			assert.Equal(t, `{"Status":"Error","Message":"fake error","Line":0,"Index":0,"Code":30001,"Layer":"server"}`, r.Body.String())
 		})
 	})
 
@@ -168,15 +312,29 @@ func Test_httpResponse409(t *testing.T) {
 
 func Test_httpResponse500(t *testing.T) {
 	t.Run("positive", func(t *testing.T) {
-		r := httptest.NewRecorder()
-		wr := httpResponseWriter{writer: r}
+		t.Run("internal server error", func(t *testing.T) {
+			r := httptest.NewRecorder()
+			wr := httpResponseWriter{writer: r}
+
+			wr.internalServerError(errors.New("fake error"))
+
+			assert.Equal(t, http.StatusInternalServerError, r.Code)
+			assert.Len(t, r.Header(), 1)
+			assert.Equal(t, "text/plain", r.Header().Get("Content-Type"))
+			assert.Equal(t, "fake error", r.Body.String())
+		})
+
+		t.Run("invalid OpenAPI document", func(t *testing.T) {
+			r := httptest.NewRecorder()
+			wr := httpResponseWriter{writer: r}
 
-		wr.internalServerError(errors.New("fake error"))
+			wr.error(&openapiValidationError{Pointer: "/paths/~1cats/get", Message: `the required property "responses" is missing`})
 
-		assert.Equal(t, http.StatusInternalServerError, r.Code)
-		assert.Len(t, r.Header(), 1)
-		assert.Equal(t, "text/plain", r.Header().Get("Content-Type"))
-		assert.Equal(t, "fake error", r.Body.String())
+			assert.Equal(t, http.StatusInternalServerError, r.Code)
+			assert.Len(t, r.Header(), 1)
+			assert.Equal(t, "application/json; charset=utf-8", r.Header().Get("Content-Type"))
+			assert.Equal(t, `{"Status":"Error","Message":"the generated OpenAPI document is invalid at \"/paths/~1cats/get\": the required property \"responses\" is missing","Line":0,"Index":0,"Code":40001,"Layer":"export","Pointer":"/paths/~1cats/get"}`, r.Body.String())
+		})
 	})
 
 	t.Run("negative", func(t *testing.T) {
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	schema "github.com/jsightapi/jsight-schema-core"
+	sc "github.com/jsightapi/jsight-schema-core/openapi"
+	"github.com/jsightapi/jsight-schema-core/panics"
+
+	"github.com/jsightapi/jsight-api-core/catalog"
+	"github.com/jsightapi/jsight-api-core/kit"
+	"github.com/jsightapi/jsight-api-core/notation"
+)
+
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+const openapiRefPrefix = "#/components/schemas/"
+
+func jsonSchemaJSON(jAPI kit.JApi, bodies bool) ([]byte, error) {
+	doc, err := newJSONSchema(jAPI.Catalog(), bodies)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func jsonSchemaYAML(jAPI kit.JApi, bodies bool) ([]byte, error) {
+	js, err := jsonSchemaJSON(jAPI, bodies)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonToYAML(js)
+}
+
+// newJSONSchema builds the JSON Schema (draft 2020-12) bundle. Every user type
+// is placed in "$defs" under its name without "@", and user types refer to
+// each other with "#/$defs/<name>".
+//
+// If bodies is true, the bodies of HTTP requests and responses are added to
+// "$defs" too, e.g. "GET /cats request" and "GET /cats response 200".
+func newJSONSchema(c *catalog.Catalog, bodies bool) (doc jsonObject, err error) {
+	defer func() {
+		err = panics.Handle(recover(), err)
+	}()
+
+	b := jsonSchemaBuilder{types: map[string]schema.Schema{}}
+	c.UserTypes.EachSafe(func(k string, v *catalog.UserType) {
+		if s, ok := exchangeSchema(v.Schema); ok {
+			b.types[k] = s
+		}
+	})
+
+	defs := jsonObject{}
+
+	err = c.UserTypes.Each(func(k string, v *catalog.UserType) error {
+		s, ok := b.types[k]
+		if !ok {
+			return nil
+		}
+
+		js, err := b.schema(s)
+		if err != nil {
+			return fmt.Errorf("type %q: %w", k, err)
+		}
+
+		if d := description(v.Description, v.Annotation); d != "" && !js.has("description") {
+			js = append(js, jsonMember{Key: "description", Value: d})
+		}
+		defs = append(defs, jsonMember{Key: strings.TrimPrefix(k, "@"), Value: js})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if bodies {
+		if defs, err = b.appendBodies(defs, c); err != nil {
+			return nil, err
+		}
+	}
+
+	return jsonObject{
+		{Key: "$schema", Value: jsonSchemaDialect},
+		{Key: "$defs", Value: defs},
+	}, nil
+}
+
+// exchangeSchema returns the schema-core schema of the exchange schema. Pseudo
+// schemas ("any" and "empty") have no such schema.
+func exchangeSchema(es catalog.ExchangeSchema) (schema.Schema, bool) {
+	switch s := es.(type) {
+	case *catalog.ExchangeJSightSchema:
+		return s.JSchema, true
+	case *catalog.ExchangeRegexSchema:
+		return s.RSchema, true
+	default:
+		return nil, false
+	}
+}
+
+// jsonSchemaBuilder converts JSight schemas into JSON Schema. Schemas are
+// built by the schema-core OpenAPI converter and then rewritten in the same way
+// as for OpenAPI 3.1, whose Schema Objects are JSON Schema 2020-12.
+type jsonSchemaBuilder struct {
+	// types all user types by their names with "@".
+	types map[string]schema.Schema
+}
+
+func (b jsonSchemaBuilder) schema(s schema.Schema) (jsonObject, error) {
+	o, err := b.openapiSchema(sc.NewSchemaObject(s))
+	if err != nil {
+		return nil, err
+	}
+
+	o, _ = convertSchema31(o).(jsonObject)
+	convertRefs(o)
+	return o, nil
+}
+
+// openapiSchema returns the OpenAPI 3.0 Schema Object without "allOf".
+func (b jsonSchemaBuilder) openapiSchema(so sc.SchemaObject) (jsonObject, error) {
+	data, err := so.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	o, err := decodeJSONObject(data)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.flattenAllOf(o)
+	if err != nil {
+		return nil, err
+	}
+	return v.(jsonObject), nil
+}
+
+// flattenAllOf replaces "allOf" of objects with the inherited properties. User
+// types are closed objects ("additionalProperties": false), so they cannot be
+// combined with "allOf" in JSON Schema.
+func (b jsonSchemaBuilder) flattenAllOf(v any) (any, error) {
+	switch vv := v.(type) {
+	case jsonObject:
+		for i, m := range vv {
+			c, err := b.flattenAllOf(m.Value)
+			if err != nil {
+				return nil, err
+			}
+			vv[i].Value = c
+		}
+		if vv.get("type") == "object" && vv.has("allOf") {
+			return b.inheritProperties(vv)
+		}
+	case []any:
+		for i := range vv {
+			c, err := b.flattenAllOf(vv[i])
+			if err != nil {
+				return nil, err
+			}
+			vv[i] = c
+		}
+	}
+	return v, nil
+}
+
+func (b jsonSchemaBuilder) inheritProperties(o jsonObject) (jsonObject, error) {
+	props := jsonObject{}
+	required := map[string]bool{}
+
+	allOf, _ := o.get("allOf").([]any)
+	for _, i := range allOf {
+		ref, _ := i.(jsonObject).get("$ref").(string)
+		name := "@" + strings.TrimPrefix(ref, openapiRefPrefix)
+
+		ut, ok := b.types[name]
+		if !ok {
+			return nil, fmt.Errorf("the type %q not found", name)
+		}
+
+		for _, si := range sc.Dereference(ut) {
+			oi, ok := si.(sc.ObjectInformer)
+			if !ok {
+				return nil, fmt.Errorf("the type %q must be an object", name)
+			}
+
+			for _, p := range oi.PropertiesInfos() {
+				ps, err := b.openapiSchema(p.SchemaObject())
+				if err != nil {
+					return nil, err
+				}
+				props.put(p.Key(), ps)
+				required[p.Key()] = !p.Optional()
+			}
+		}
+	}
+
+	// The properties of the object itself override the inherited ones.
+	own, _ := o.get("properties").(jsonObject)
+	ownRequired, _ := o.get("required").([]any)
+	for _, m := range own {
+		props.put(m.Key, m.Value)
+		required[m.Key] = containsValue(ownRequired, m.Key)
+	}
+
+	rr := []any{}
+	for _, m := range props {
+		if required[m.Key] {
+			rr = append(rr, m.Key)
+		}
+	}
+
+	o.del("allOf")
+	o.put("properties", props)
+	if len(rr) != 0 {
+		o.put("required", rr)
+	}
+	return o, nil
+}
+
+// convertRefs replaces references to OpenAPI components with references to
+// "$defs".
+func convertRefs(v any) {
+	switch vv := v.(type) {
+	case jsonObject:
+		for i, m := range vv {
+			if s, ok := m.Value.(string); ok && m.Key == "$ref" {
+				vv[i].Value = "#/$defs/" + strings.TrimPrefix(s, openapiRefPrefix)
+				continue
+			}
+			convertRefs(m.Value)
+		}
+	case []any:
+		for _, i := range vv {
+			convertRefs(i)
+		}
+	}
+}
+
+func containsValue(vv []any, v any) bool {
+	for _, i := range vv {
+		if i == v {
+			return true
+		}
+	}
+	return false
+}
+
+// appendBodies adds the bodies of HTTP requests and responses to the $defs.
+// Empty bodies are skipped, "any" bodies are the schemas without restrictions.
+func (b jsonSchemaBuilder) appendBodies(defs jsonObject, c *catalog.Catalog) (jsonObject, error) {
+	err := c.Interactions.Each(func(_ catalog.InteractionID, v catalog.Interaction) error {
+		h, ok := v.(*catalog.HTTPInteraction)
+		if !ok {
+			return nil
+		}
+
+		name := fmt.Sprintf("%s %s", h.HttpMethod, h.Path())
+
+		if h.Request != nil && h.Request.HTTPRequestBody != nil {
+			js, ok, err := b.body(h.Request.HTTPRequestBody.Schema)
+			if err != nil {
+				return fmt.Errorf("%s request: %w", name, err)
+			}
+			if ok {
+				defs = append(defs, jsonMember{Key: name + " request", Value: js})
+			}
+		}
+
+		// Responses with the same code are numbered, e.g. "GET /cats response
+		// 200 (2)".
+		codes := map[string]int{}
+		for _, r := range h.Responses {
+			if r.Body == nil {
+				continue
+			}
+			js, ok, err := b.body(r.Body.Schema)
+			if err != nil {
+				return fmt.Errorf("%s response %s: %w", name, r.Code, err)
+			}
+			if !ok {
+				continue
+			}
+
+			key := name + " response " + r.Code
+			if codes[r.Code]++; codes[r.Code] > 1 {
+				key += fmt.Sprintf(" (%d)", codes[r.Code])
+			}
+			defs = append(defs, jsonMember{Key: key, Value: js})
+		}
+		return nil
+	})
+	return defs, err
+}
+
+func (b jsonSchemaBuilder) body(es catalog.ExchangeSchema) (jsonObject, bool, error) {
+	if s, ok := exchangeSchema(es); ok {
+		js, err := b.schema(s)
+		return js, err == nil, err
+	}
+
+	if es != nil && es.Notation() == notation.SchemaNotationAny {
+		return jsonObject{}, true, nil
+	}
+	return nil, false, nil
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsightapi/jsight-schema-core/fs"
+
+	"github.com/jsightapi/jsight-api-core/kit"
+)
+
+const testJSONSchemaAPI = `JSIGHT 0.3
+
+TYPE @named // A named thing.
+{
+  "name": "Tom", // {minLength: 1}
+  "id": 1 // {optional: true}
+}
+
+TYPE @cat
+{ // {allOf: "@named"}
+  "kind": "cat", // The kind.
+  "age": 3, // {min: 0, exclusiveMinimum: true}
+  "owner": @named // {nullable: true}
+}
+
+TYPE @code regex
+  /^[A-Z]+$/
+
+TYPE @pets
+[
+  @cat | @named
+]
+
+GET /cats
+  Request any
+  200 [@cat]
+  404 regex
+    /^not found$/
+  204 empty
+`
+
+func Test_jsonSchemaJSON(t *testing.T) {
+	t.Run("positive", func(t *testing.T) {
+		t.Run("user types", func(t *testing.T) {
+			b := jsonSchemaOf(t, testJSONSchemaAPI, false)
+
+			assert.JSONEq(t, `{
+				"$schema": "https://json-schema.org/draft/2020-12/schema",
+				"$defs": {
+					"named": {
+						"type": "object",
+						"properties": {
+							"name": {"type": "string", "examples": ["Tom"], "minLength": 1},
+							"id": {"type": "integer", "examples": [1]}
+						},
+						"required": ["name"],
+						"additionalProperties": false,
+						"description": "A named thing."
+					},
+					"cat": {
+						"type": "object",
+						"properties": {
+							"name": {"type": "string", "examples": ["Tom"], "minLength": 1},
+							"id": {"type": "integer", "examples": [1]},
+							"kind": {"type": "string", "examples": ["cat"], "description": "The kind."},
+							"age": {"type": "integer", "examples": [3], "exclusiveMinimum": 0},
+							"owner": {"anyOf": [{"$ref": "#/$defs/named"}, {"type": "null"}]}
+						},
+						"required": ["name", "kind", "age", "owner"],
+						"additionalProperties": false
+					},
+					"code": {"pattern": "^[A-Z]+$", "type": "string"},
+					"pets": {
+						"type": "array",
+						"items": {"anyOf": [{"$ref": "#/$defs/cat"}, {"$ref": "#/$defs/named"}]}
+					}
+				}
+			}`, b)
+		})
+
+		t.Run("bodies", func(t *testing.T) {
+			b := jsonSchemaOf(t, testJSONSchemaAPI, true)
+
+			assert.Contains(t, b, `"GET /cats request": {},`)
+			assert.Contains(t, b, `"GET /cats response 200": {
+      "type": "array",
+      "items": {
+        "$ref": "#/$defs/cat"
+      }
+    },`)
+			assert.Contains(t, b, `"GET /cats response 404": {
+      "pattern": "^not found$",
+      "type": "string"
+    }`)
+			assert.NotContains(t, b, `"GET /cats response 204"`)
+		})
+
+		t.Run("responses with the same code", func(t *testing.T) {
+			b := jsonSchemaOf(t, `JSIGHT 0.3
+
+GET /cats
+  200 [@cat]
+  200 empty
+  200 regex
+    /^none$/
+  200 any
+
+TYPE @cat
+{
+  "id": 1
+}
+`, true)
+
+			assert.JSONEq(t, `{
+				"$schema": "https://json-schema.org/draft/2020-12/schema",
+				"$defs": {
+					"cat": {
+						"type": "object",
+						"properties": {"id": {"type": "integer", "examples": [1]}},
+						"required": ["id"],
+						"additionalProperties": false
+					},
+					"GET /cats response 200": {"type": "array", "items": {"$ref": "#/$defs/cat"}},
+					"GET /cats response 200 (2)": {"pattern": "^none$", "type": "string"},
+					"GET /cats response 200 (3)": {}
+				}
+			}`, b)
+		})
+
+		t.Run("YAML", func(t *testing.T) {
+			jAPI, je := kit.NewJApiFromFile(fs.NewFile("root", []byte("JSIGHT 0.3\n\nTYPE @id\n1\n")))
+			require.Nil(t, je)
+
+			b, err := jsonSchemaYAML(jAPI, false)
+			require.NoError(t, err)
+			assert.Equal(t, `$schema: https://json-schema.org/draft/2020-12/schema
+$defs:
+  id:
+    type: integer
+    examples:
+      - 1`, string(b))
+		})
+	})
+}
+
+func jsonSchemaOf(t *testing.T, jsight string, bodies bool) string {
+	jAPI, je := kit.NewJApiFromFile(fs.NewFile("root", []byte(jsight)))
+	require.Nil(t, je)
+
+	b, err := jsonSchemaJSON(jAPI, bodies)
+	require.NoError(t, err)
+	return string(b)
+}
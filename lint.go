@@ -0,0 +1,606 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	schema "github.com/jsightapi/jsight-schema-core"
+
+	"github.com/jsightapi/jsight-api-core/catalog"
+	"github.com/jsightapi/jsight-api-core/directive"
+	"github.com/jsightapi/jsight-api-core/notation"
+)
+
+// lintWarning is an advisory diagnostic. Unlike errors, warnings don't prevent
+// the API from being built.
+type lintWarning struct {
+	// Rule the name of the rule which reported the warning.
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+
+	// File, Line, Column and Index locate the directive the warning is about.
+	// They are empty if the position is unknown.
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+	Index  int    `json:"index,omitempty"`
+}
+
+// lintRule checks the catalog and reports warnings with linter.warn.
+type lintRule struct {
+	name  string
+	check func(l *linter) error
+}
+
+// lintRules are all the rules in the order they run. Rule names are a part of
+// the API, don't rename them.
+var lintRules = []lintRule{
+	{
+		// An interaction has neither an annotation nor a description.
+		name:  "interaction-description",
+		check: lintInteractionDescription,
+	},
+	{
+		// A TYPE or an ENUM isn't used by any interaction.
+		name:  "unused-type",
+		check: lintUnusedTypes,
+	},
+	{
+		// A response has no body, though its code allows one.
+		name:  "empty-response",
+		check: lintEmptyResponses,
+	},
+	{
+		// A path segment is named in another style than most of the paths.
+		name:  "path-naming",
+		check: lintPathNaming,
+	},
+	{
+		// An HTTP interaction has no 4xx response.
+		name:  "error-responses",
+		check: lintErrorResponses,
+	},
+	{
+		// A type or a body has the same example as a type, so it may be the same.
+		name:  "duplicate-example",
+		check: lintDuplicateExamples,
+	},
+}
+
+// lintDisableEnv disables rules by default, e.g. "path-naming,unused-type".
+const lintDisableEnv = "JSIGHT_SERVER_LINT_DISABLE"
+
+// errLintConfig the default rules of the server are misconfigured. It isn't
+// the fault of the client, so it's an internal server error.
+var errLintConfig = errors.New("invalid lint configuration")
+
+// lintRuleNames returns the enabled rules. Rules disabled in the config can be
+// enabled by the enable list, the disable list wins over both.
+func lintRuleNames(enable, disable string) (map[string]bool, error) {
+	enabled := make(map[string]bool, len(lintRules))
+	for _, r := range lintRules {
+		enabled[r.name] = true
+	}
+
+	// set returns the first unknown rule of the list.
+	set := func(list string, v bool) string {
+		for _, name := range strings.Split(list, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if _, ok := enabled[name]; !ok {
+				return name
+			}
+			enabled[name] = v
+		}
+		return ""
+	}
+
+	if name := set(os.Getenv(lintDisableEnv), false); name != "" {
+		return nil, fmt.Errorf("%w: %s: unknown lint rule %q", errLintConfig, lintDisableEnv, name)
+	}
+	if name := set(enable, true); name != "" {
+		return nil, newRequestError(errorCodeUnknownLintRule, "unknown lint rule %q", name)
+	}
+	if name := set(disable, false); name != "" {
+		return nil, newRequestError(errorCodeUnknownLintRule, "unknown lint rule %q", name)
+	}
+	return enabled, nil
+}
+
+// linter runs the rules against the catalog of the project.
+type linter struct {
+	catalog *catalog.Catalog
+
+	// dir and files are used to turn directive positions into project ones.
+	dir   string
+	files memFS
+
+	// rule the name of the running rule.
+	rule     string
+	warnings []lintWarning
+}
+
+func lintCatalog(c *catalog.Catalog, dir string, files memFS, enabled map[string]bool) ([]lintWarning, error) {
+	l := &linter{
+		catalog:  c,
+		dir:      dir,
+		files:    files,
+		warnings: []lintWarning{},
+	}
+
+	for _, r := range lintRules {
+		if !enabled[r.name] {
+			continue
+		}
+		l.rule = r.name
+		if err := r.check(l); err != nil {
+			return nil, fmt.Errorf("%s: %w", r.name, err)
+		}
+	}
+	return l.warnings, nil
+}
+
+// warn reports a warning about the directive. The directive can be nil if it's
+// unknown.
+func (l *linter) warn(d *directive.Directive, format string, args ...any) {
+	w := lintWarning{
+		Rule:    l.rule,
+		Message: fmt.Sprintf(format, args...),
+	}
+
+	if d != nil {
+		je := trimProjectDir(d.KeywordError(w.Message), l.dir, l.files)
+		if je.File != nil {
+			w.File = je.File.Name()
+			w.Line = je.Line.Int()
+			w.Column = je.Column.Int()
+			w.Index = je.Index.Int()
+		}
+	}
+
+	l.warnings = append(l.warnings, w)
+}
+
+func (l *linter) interactions() []catalog.Interaction {
+	var ii []catalog.Interaction
+	l.catalog.Interactions.EachSafe(func(_ catalog.InteractionID, v catalog.Interaction) {
+		ii = append(ii, v)
+	})
+	return ii
+}
+
+// interactionDirective returns the directive of the HTTP method or the
+// JSON-RPC method. The catalog doesn't keep it, but it's the parent of the
+// interaction parts.
+func interactionDirective(i catalog.Interaction) *directive.Directive {
+	switch v := i.(type) {
+	case *catalog.HTTPInteraction:
+		for _, r := range v.Responses {
+			if r.Directive.Parent != nil {
+				return r.Directive.Parent
+			}
+		}
+		if v.Request != nil && v.Request.Directive.Parent != nil {
+			return v.Request.Directive.Parent
+		}
+		if v.Query != nil && v.Query.Directive.Parent != nil {
+			return v.Query.Directive.Parent
+		}
+
+	case *catalog.JsonRpcInteraction:
+		if v.Params != nil && v.Params.Directive.Parent != nil {
+			return v.Params.Directive.Parent
+		}
+		if v.Result != nil && v.Result.Directive.Parent != nil {
+			return v.Result.Directive.Parent
+		}
+	}
+	return nil
+}
+
+func lintInteractionDescription(l *linter) error {
+	for _, i := range l.interactions() {
+		var id string
+		var annotation, description *string
+		switch v := i.(type) {
+		case *catalog.HTTPInteraction:
+			id, annotation, description = v.Id, v.Annotation, v.Description
+		case *catalog.JsonRpcInteraction:
+			id, annotation, description = v.Id, v.Annotation, v.Description
+		}
+
+		if isBlank(annotation) && isBlank(description) {
+			l.warn(interactionDirective(i), "the interaction %s has neither an annotation nor a description", id)
+		}
+	}
+	return nil
+}
+
+func isBlank(s *string) bool {
+	return s == nil || strings.TrimSpace(*s) == ""
+}
+
+func lintUnusedTypes(l *linter) error {
+	var queue []string
+	for _, i := range l.interactions() {
+		for _, es := range interactionSchemas(i) {
+			names, err := usedUserNames(es)
+			if err != nil {
+				return err
+			}
+			queue = append(queue, names...)
+		}
+	}
+
+	used := map[string]bool{}
+	for len(queue) != 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if used[name] {
+			continue
+		}
+		used[name] = true
+
+		if ut, ok := l.catalog.UserTypes.Get(name); ok {
+			names, err := usedUserNames(ut.Schema)
+			if err != nil {
+				return err
+			}
+			queue = append(queue, names...)
+		}
+	}
+
+	l.catalog.UserTypes.EachSafe(func(k string, v *catalog.UserType) {
+		if !used[k] {
+			l.warn(&v.Directive, "the type %s isn't used by any interaction", k)
+		}
+	})
+	l.catalog.UserEnums.EachSafe(func(k string, v *catalog.UserRule) {
+		if !used[k] {
+			l.warn(v.Directive, "the enum %s isn't used by any interaction", k)
+		}
+	})
+	return nil
+}
+
+// interactionSchemas returns all the schemas of the interaction.
+func interactionSchemas(i catalog.Interaction) []catalog.ExchangeSchema {
+	var ss []catalog.ExchangeSchema
+	add := func(s *catalog.ExchangeJSightSchema) {
+		if s != nil {
+			ss = append(ss, s)
+		}
+	}
+
+	switch v := i.(type) {
+	case *catalog.HTTPInteraction:
+		if v.PathVariables != nil {
+			add(v.PathVariables.Schema)
+		}
+		if v.Query != nil {
+			add(v.Query.Schema)
+		}
+		if v.Request != nil && v.Request.HTTPRequestHeaders != nil {
+			add(v.Request.HTTPRequestHeaders.Schema)
+		}
+		if es := requestBodySchema(v); es != nil {
+			ss = append(ss, es)
+		}
+		for _, r := range v.Responses {
+			add(responseHeadersSchema(r))
+			if es := responseBodySchema(r); es != nil {
+				ss = append(ss, es)
+			}
+		}
+
+	case *catalog.JsonRpcInteraction:
+		add(rpcParamsSchema(v))
+		add(rpcResultSchema(v))
+	}
+	return ss
+}
+
+// usedUserNames returns the user types and enums the schema refers to
+// directly: in types, key shortcuts and rule values, e.g. "or" and "enum".
+func usedUserNames(es catalog.ExchangeSchema) ([]string, error) {
+	s, ok := es.(*catalog.ExchangeJSightSchema)
+	if !ok || s == nil {
+		return nil, nil
+	}
+
+	node, err := s.GetAST()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	collectUserNames(node, &names)
+	return names, nil
+}
+
+func collectUserNames(node schema.ASTNode, names *[]string) {
+	if strings.HasPrefix(node.SchemaType, "@") {
+		*names = append(*names, node.SchemaType)
+	}
+	if node.IsKeyShortcut {
+		*names = append(*names, node.Key)
+	}
+	if node.Rules != nil {
+		node.Rules.EachSafe(func(_ string, r schema.RuleASTNode) {
+			collectRuleUserNames(r, names)
+		})
+	}
+	for _, c := range node.Children {
+		collectUserNames(c, names)
+	}
+}
+
+func collectRuleUserNames(r schema.RuleASTNode, names *[]string) {
+	if strings.HasPrefix(r.Value, "@") {
+		*names = append(*names, r.Value)
+	}
+	if r.Properties != nil {
+		r.Properties.EachSafe(func(_ string, p schema.RuleASTNode) {
+			collectRuleUserNames(p, names)
+		})
+	}
+	for _, i := range r.Items {
+		collectRuleUserNames(i, names)
+	}
+}
+
+// isBodilessCode reports whether responses with the code have no body by the
+// HTTP specification.
+func isBodilessCode(code string) bool {
+	return strings.HasPrefix(code, "1") || code == "204" || code == "205" || code == "304"
+}
+
+func lintEmptyResponses(l *linter) error {
+	for _, i := range l.interactions() {
+		v, ok := i.(*catalog.HTTPInteraction)
+		if !ok {
+			continue
+		}
+
+		for _, r := range v.Responses {
+			if isBodilessCode(r.Code) {
+				continue
+			}
+			if schemaNotation(responseBodySchema(r)) == notation.SchemaNotationEmpty {
+				r := r
+				l.warn(&r.Directive, "the response %s of %s has no body", r.Code, v.Id)
+			}
+		}
+	}
+	return nil
+}
+
+// Naming styles of path segments.
+const (
+	pathStyleKebab = "kebab-case"
+	pathStyleSnake = "snake_case"
+	pathStyleCamel = "camelCase"
+)
+
+// pathSegmentStyle returns the naming style of the path segment. Parameters
+// and lowercase words fit any style, for them the style is empty.
+func pathSegmentStyle(s string) string {
+	switch {
+	case strings.HasPrefix(s, "{"):
+		return ""
+	case strings.Contains(s, "-"):
+		return pathStyleKebab
+	case strings.Contains(s, "_"):
+		return pathStyleSnake
+	case strings.ToLower(s) != s:
+		return pathStyleCamel
+	default:
+		return ""
+	}
+}
+
+func lintPathNaming(l *linter) error {
+	type pathItem struct {
+		path        string
+		interaction catalog.Interaction
+	}
+
+	var paths []pathItem
+	seen := map[string]bool{}
+	counts := map[string]int{}
+	var styles []string
+	for _, i := range l.interactions() {
+		p := i.Path().String()
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		paths = append(paths, pathItem{path: p, interaction: i})
+
+		for _, s := range strings.Split(p, "/") {
+			style := pathSegmentStyle(s)
+			if style == "" {
+				continue
+			}
+			if counts[style] == 0 {
+				styles = append(styles, style)
+			}
+			counts[style]++
+		}
+	}
+
+	if len(styles) < 2 {
+		return nil
+	}
+
+	// The first style wins a tie.
+	sort.SliceStable(styles, func(i, j int) bool {
+		return counts[styles[i]] > counts[styles[j]]
+	})
+	dominant := styles[0]
+
+	for _, p := range paths {
+		for _, s := range strings.Split(p.path, "/") {
+			style := pathSegmentStyle(s)
+			if style != "" && style != dominant {
+				l.warn(
+					interactionDirective(p.interaction),
+					"the path segment %q of %s is in %s, while most of the paths use %s",
+					s, p.path, style, dominant,
+				)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func lintErrorResponses(l *linter) error {
+	for _, i := range l.interactions() {
+		v, ok := i.(*catalog.HTTPInteraction)
+		if !ok {
+			continue
+		}
+
+		found := false
+		for _, r := range v.Responses {
+			if strings.HasPrefix(r.Code, "4") {
+				found = true
+				break
+			}
+		}
+		if !found {
+			l.warn(interactionDirective(i), "the interaction %s has no 4xx response", v.Id)
+		}
+	}
+	return nil
+}
+
+// exampleOwner is a user type or a body which has an example.
+type exampleOwner struct {
+	name      string
+	directive *directive.Directive
+	schema    catalog.ExchangeSchema
+}
+
+func lintDuplicateExamples(l *linter) error {
+	var owners []exampleOwner
+	l.catalog.UserTypes.EachSafe(func(k string, v *catalog.UserType) {
+		owners = append(owners, exampleOwner{
+			name:      "the type " + k,
+			directive: &v.Directive,
+			schema:    v.Schema,
+		})
+	})
+
+	for _, i := range l.interactions() {
+		switch v := i.(type) {
+		case *catalog.HTTPInteraction:
+			if v.Request != nil && v.Request.HTTPRequestBody != nil {
+				owners = append(owners, exampleOwner{
+					name:      "the request body of " + v.Id,
+					directive: &v.Request.HTTPRequestBody.Directive,
+					schema:    v.Request.HTTPRequestBody.Schema,
+				})
+			}
+			for _, r := range v.Responses {
+				if r.Body != nil {
+					owners = append(owners, exampleOwner{
+						name:      fmt.Sprintf("the response %s body of %s", r.Code, v.Id),
+						directive: &r.Body.Directive,
+						schema:    r.Body.Schema,
+					})
+				}
+			}
+
+		case *catalog.JsonRpcInteraction:
+			if v.Params != nil {
+				owners = append(owners, exampleOwner{
+					name:      "the params of " + v.Id,
+					directive: &v.Params.Directive,
+					schema:    v.Params.Schema,
+				})
+			}
+			if v.Result != nil {
+				owners = append(owners, exampleOwner{
+					name:      "the result of " + v.Id,
+					directive: &v.Result.Directive,
+					schema:    v.Result.Schema,
+				})
+			}
+		}
+	}
+
+	first := map[string]string{}
+	for _, o := range owners {
+		example, ok, err := compositeExample(o.schema)
+		if err != nil {
+			return fmt.Errorf("%s: %w", o.name, err)
+		}
+		if !ok {
+			continue
+		}
+
+		if name, ok := first[example]; ok {
+			l.warn(o.directive, "%s has the same example as %s", o.name, name)
+			continue
+		}
+		first[example] = o.name
+	}
+	return nil
+}
+
+// compositeExample returns the compact example of the schema if it's a
+// non-empty object or array defined in place. Scalars are the same too often,
+// and a schema which is just a reference to a user type has the example of the
+// type.
+func compositeExample(es catalog.ExchangeSchema) (string, bool, error) {
+	s, ok := es.(*catalog.ExchangeJSightSchema)
+	if !ok || s == nil {
+		return "", false, nil
+	}
+
+	node, err := s.GetAST()
+	if err != nil {
+		return "", false, err
+	}
+	if isUserTypeNode(node) {
+		return "", false, nil
+	}
+
+	b, err := s.Example()
+	if err != nil {
+		return "", false, err
+	}
+
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return "", false, err
+	}
+
+	switch vv := v.(type) {
+	case map[string]any:
+		if len(vv) == 0 {
+			return "", false, nil
+		}
+	case []any:
+		if len(vv) == 0 {
+			return "", false, nil
+		}
+	default:
+		return "", false, nil
+	}
+
+	// Unmarshalling and marshalling again drops formatting and sorts keys.
+	b, err = json.Marshal(v)
+	if err != nil {
+		return "", false, err
+	}
+	return string(b), true, nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+)
+
+type lintJSightResponse struct {
+	Warnings []lintWarning `json:"warnings"`
+}
+
+func lintJSight(w http.ResponseWriter, r *http.Request) {
+	limitProjectBody(w, r)
+
+	log.Printf("%s %s", r.Method, r.URL.Path)
+
+	if getBoolEnv("JSIGHT_SERVER_CORS") {
+		cors(w)
+	}
+
+	wr := httpResponseWriter{writer: w}
+
+	switch r.Method {
+	case http.MethodOptions:
+
+	case http.MethodPost:
+		lintJSightPOST(wr, r)
+		return
+
+	default:
+		wr.errorStr(errorCodePOSTRequired, "HTTP POST request required")
+		return
+	}
+}
+
+func lintJSightPOST(wr httpResponseWriter, r *http.Request) {
+	enabled, err := lintRuleNames(r.FormValue("enable"), r.FormValue("disable"))
+	if errors.Is(err, errLintConfig) {
+		wr.internalServerError(err)
+		return
+	}
+	if err != nil {
+		wr.error(err)
+		return
+	}
+
+	p, err := readProject(wr.writer, r)
+	if err != nil {
+		wr.error(err)
+		return
+	}
+
+	jAPI, dir, jErr := p.buildInDir()
+	if jErr != nil {
+		wr.error(jErr)
+		return
+	}
+
+	ww, err := lintCatalog(jAPI.Catalog(), dir, p.files, enabled)
+	if err != nil {
+		wr.error(err)
+		return
+	}
+
+	b, err := json.Marshal(lintJSightResponse{Warnings: ww})
+	if err != nil {
+		wr.internalServerError(err)
+		return
+	}
+
+	wr.json(b)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_lintJSight(t *testing.T) {
+	const jsight = "JSIGHT 0.3\n\nGET /cats // Get cats.\n  200 any\n  404 empty\n\nGET /dogs\n  200 any\n"
+
+	cc := map[string]testCase{
+		http.MethodOptions: {
+			func(t *testing.T) *http.Request {
+				r, err := http.NewRequest(http.MethodOptions, "/", http.NoBody)
+				require.NoError(t, err)
+				return r
+			},
+			func(t *testing.T, r *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, r.Code)
+			},
+		},
+
+		"POST, warnings": {
+			newLintJSightRequest("/", jsight),
+			func(t *testing.T, r *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, r.Code)
+				assert.Equal(t, "application/json; charset=utf-8", r.Header().Get("Content-Type"))
+				assert.JSONEq(t, `{"warnings": [
+					{
+						"rule": "interaction-description",
+						"message": "the interaction http GET /dogs has neither an annotation nor a description",
+						"file": "root", "line": 7, "column": 1, "index": 58
+					},
+					{
+						"rule": "empty-response",
+						"message": "the response 404 of http GET /cats has no body",
+						"file": "root", "line": 5, "column": 3, "index": 47
+					},
+					{
+						"rule": "error-responses",
+						"message": "the interaction http GET /dogs has no 4xx response",
+						"file": "root", "line": 7, "column": 1, "index": 58
+					}
+				]}`, r.Body.String())
+			},
+		},
+
+		"POST, disabled rules": {
+			newLintJSightRequest("/?disable=empty-response,error-responses", jsight),
+			func(t *testing.T, r *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, r.Code)
+				assert.JSONEq(t, `{"warnings": [
+					{
+						"rule": "interaction-description",
+						"message": "the interaction http GET /dogs has neither an annotation nor a description",
+						"file": "root", "line": 7, "column": 1, "index": 58
+					}
+				]}`, r.Body.String())
+			},
+		},
+
+		"POST, no warnings": {
+			newLintJSightRequest("/?disable=error-responses", "JSIGHT 0.3\n\nGET /cats // Get cats.\n  200 any\n"),
+			func(t *testing.T, r *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, r.Code)
+				assert.Equal(t, `{"warnings":[]}`, r.Body.String())
+			},
+		},
+
+		"POST, unknown rule": {
+			newLintJSightRequest("/?enable=unknown", jsight),
+			func(t *testing.T, r *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, r.Code)
+				assert.Equal(t, `{"Status":"Error","Message":"unknown lint rule \"unknown\"","Line":0,"Index":0,"Code":30010,"Layer":"server"}`, r.Body.String())
+			},
+		},
+
+		"POST, misconfigured rules": {
+			func(t *testing.T) *http.Request {
+				t.Setenv(lintDisableEnv, "unknown")
+				return newLintJSightRequest("/", jsight)(t)
+			},
+			func(t *testing.T, r *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, r.Code)
+				assert.Equal(t, "text/plain", r.Header().Get("Content-Type"))
+				assert.Equal(t, `invalid lint configuration: JSIGHT_SERVER_LINT_DISABLE: unknown lint rule "unknown"`, r.Body.String())
+			},
+		},
+
+		"POST, invalid JSight": {
+			newLintJSightRequest("/", "JSIGHT 0.3\n\nGET /cats\n  200 @cat\n"),
+			func(t *testing.T, r *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, r.Code)
+				assert.Equal(t, `{"Status":"Error","Message":"Type \"@cat\" not found","Line":4,"Index":24,"Code":1302,"Layer":"schema","File":"root","Column":3}`, r.Body.String())
+			},
+		},
+	}
+
+	appendUnhandledMethod(cc)
+	assertHandler(t, lintJSight, cc)
+}
+
+func newLintJSightRequest(url, jsight string) func(*testing.T) *http.Request {
+	return func(t *testing.T) *http.Request {
+		r, err := http.NewRequest(http.MethodPost, url, strings.NewReader(jsight))
+		require.NoError(t, err)
+		return r
+	}
+}
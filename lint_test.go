@@ -0,0 +1,312 @@
+package main
+
+import (
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_lintCatalog(t *testing.T) {
+	lint := func(t *testing.T, p project, rule string) []string {
+		jAPI, dir, je := p.buildInDir()
+		require.Nil(t, je)
+
+		ww, err := lintCatalog(jAPI.Catalog(), dir, p.files, map[string]bool{rule: true})
+		require.NoError(t, err)
+
+		ss := []string{}
+		for _, w := range ww {
+			assert.Equal(t, rule, w.Rule)
+			ss = append(ss, w.File+":"+strconv.Itoa(w.Line)+": "+w.Message)
+		}
+		return ss
+	}
+
+	lintJSight := func(t *testing.T, jsight, rule string) []string {
+		return lint(t, newSingleFileProject([]byte(jsight)), rule)
+	}
+
+	t.Run("interaction-description", func(t *testing.T) {
+		assert.Equal(t, []string{
+			"root:11: the interaction http GET /dogs has neither an annotation nor a description",
+			"root:16: the interaction json-rpc-2.0 getCats /rpc has neither an annotation nor a description",
+		}, lintJSight(t, `JSIGHT 0.3
+
+GET /cats // Get cats.
+  200 any
+
+POST /cats
+  Description
+    Create a cat.
+  201 any
+
+GET /dogs
+  200 any
+
+URL /rpc
+  Protocol json-rpc-2.0
+  Method getCats
+    Params
+    {}
+    Result
+    []
+`, "interaction-description"))
+	})
+
+	t.Run("unused-type", func(t *testing.T) {
+		t.Run("positive", func(t *testing.T) {
+			assert.Equal(t, []string{
+				"root:24: the type @fish isn't used by any interaction",
+				"root:29: the type @bird isn't used by any interaction",
+				"root:8: the enum @unused isn't used by any interaction",
+			}, lintJSight(t, `JSIGHT 0.3
+
+ENUM @color
+[
+  "red"
+]
+
+ENUM @unused
+[
+  "x"
+]
+
+TYPE @kind
+{
+  "name": "red" // {enum: @color}
+}
+
+TYPE @cat
+{
+  "id": 1,
+  "kind": @kind
+}
+
+TYPE @fish
+{
+  "id": 1
+}
+
+TYPE @bird
+{
+  "fish": @fish
+}
+
+GET /cats
+  200 [@cat]
+`, "unused-type"))
+		})
+
+		t.Run("references in rules and shortcuts", func(t *testing.T) {
+			assert.Equal(t, []string{}, lintJSight(t, `JSIGHT 0.3
+
+TYPE @cat
+{
+  "id": 1
+}
+
+TYPE @dog
+{
+  "id": 2
+}
+
+TYPE @bird
+{
+  "id": 3
+}
+
+TYPE @key regex
+  /^[a-z]+$/
+
+GET /pets
+  200
+  {
+    "pet": @cat | @dog,
+    "friends": {
+      @key: @bird
+    }
+  }
+`, "unused-type"))
+		})
+
+		t.Run("included files", func(t *testing.T) {
+			p := project{
+				root: "main.jst",
+				files: memFS{
+					"main.jst":  []byte("JSIGHT 0.3\n\nINCLUDE types.jst\n\nGET /cats\n  200 any\n"),
+					"types.jst": []byte("TYPE @cat\n{\n  \"id\": 1\n}\n"),
+				},
+			}
+
+			assert.Equal(t, []string{
+				"types.jst:1: the type @cat isn't used by any interaction",
+			}, lint(t, p, "unused-type"))
+		})
+	})
+
+	t.Run("empty-response", func(t *testing.T) {
+		assert.Equal(t, []string{
+			"root:5: the response 404 of http GET /cats has no body",
+		}, lintJSight(t, `JSIGHT 0.3
+
+GET /cats
+  200 any
+  404 empty
+
+DELETE /cats
+  204 empty
+  304 empty
+`, "empty-response"))
+	})
+
+	t.Run("path-naming", func(t *testing.T) {
+		t.Run("positive", func(t *testing.T) {
+			assert.Equal(t, []string{
+				`root:9: the path segment "user_profiles" of /user_profiles/{id} is in snake_case, while most of the paths use kebab-case`,
+				`root:12: the path segment "catOwners" of /catOwners is in camelCase, while most of the paths use kebab-case`,
+			}, lintJSight(t, `JSIGHT 0.3
+
+GET /cat-owners/{id}/pet-names
+  200 any
+
+GET /user-groups
+  200 any
+
+GET /user_profiles/{id}
+  200 any
+
+GET /catOwners
+  200 any
+`, "path-naming"))
+		})
+
+		t.Run("negative", func(t *testing.T) {
+			assert.Equal(t, []string{}, lintJSight(t, `JSIGHT 0.3
+
+GET /cats/{catId}
+  200 any
+
+GET /cat_owners
+  200 any
+`, "path-naming"))
+		})
+	})
+
+	t.Run("error-responses", func(t *testing.T) {
+		assert.Equal(t, []string{
+			"root:7: the interaction http POST /cats has no 4xx response",
+		}, lintJSight(t, `JSIGHT 0.3
+
+GET /cats
+  200 any
+  404 any
+
+POST /cats
+  201 any
+  500 any
+`, "error-responses"))
+	})
+
+	t.Run("duplicate-example", func(t *testing.T) {
+		t.Run("positive", func(t *testing.T) {
+			assert.Equal(t, []string{
+				"root:8: the type @pet has the same example as the type @cat",
+				"root:14: the request body of http POST /cats has the same example as the type @cat",
+			}, lintJSight(t, `JSIGHT 0.3
+
+TYPE @cat
+{
+  "id": 1
+}
+
+TYPE @pet
+{
+  "id": 1 // {min: 0}
+}
+
+POST /cats
+  Request
+  {
+    "id": 1
+  }
+  201 @cat
+`, "duplicate-example"))
+		})
+
+		t.Run("negative", func(t *testing.T) {
+			assert.Equal(t, []string{}, lintJSight(t, `JSIGHT 0.3
+
+TYPE @cat
+{
+  "id": 1
+}
+
+TYPE @id
+1
+
+GET /cats
+  200 [@cat]
+  400
+  {}
+
+GET /dogs
+  200 @cat
+`, "duplicate-example"))
+		})
+	})
+}
+
+func Test_lintRuleNames(t *testing.T) {
+	t.Run("positive", func(t *testing.T) {
+		require.NoError(t, os.Setenv(lintDisableEnv, "path-naming, unused-type"))
+		defer func() {
+			require.NoError(t, os.Unsetenv(lintDisableEnv))
+		}()
+
+		enabled, err := lintRuleNames("unused-type", "empty-response")
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]bool{
+			"interaction-description": true,
+			"unused-type":             true,
+			"empty-response":          false,
+			"path-naming":             false,
+			"error-responses":         true,
+			"duplicate-example":       true,
+		}, enabled)
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		_, err := lintRuleNames("", "empty-response,unknown")
+		assert.EqualError(t, err, `unknown lint rule "unknown"`)
+		assert.NotErrorIs(t, err, errLintConfig)
+
+		require.NoError(t, os.Setenv(lintDisableEnv, "unknown"))
+		defer func() {
+			require.NoError(t, os.Unsetenv(lintDisableEnv))
+		}()
+
+		_, err = lintRuleNames("", "")
+		assert.EqualError(t, err, `invalid lint configuration: JSIGHT_SERVER_LINT_DISABLE: unknown lint rule "unknown"`)
+		assert.ErrorIs(t, err, errLintConfig)
+	})
+}
+
+func Test_pathSegmentStyle(t *testing.T) {
+	cc := map[string]string{
+		"cats":      "",
+		"{catId}":   "",
+		"v1":        "",
+		"cat-names": pathStyleKebab,
+		"cat_names": pathStyleSnake,
+		"catNames":  pathStyleCamel,
+		"CatNames":  pathStyleCamel,
+	}
+
+	for given, expected := range cc {
+		assert.Equal(t, expected, pathSegmentStyle(given), given)
+	}
+}
@@ -4,11 +4,45 @@ import (
 	_ "embed"
 	"log"
 	"net/http"
+	"net/url"
+	"os"
 	"time"
+
+	"github.com/jsightapi/jsight-api-core/kit"
 )
 
 func main() {
 	http.HandleFunc("/convert-jsight", convertJSight)
+	http.HandleFunc("/convert-openapi", convertOpenAPI)
+	http.HandleFunc("/validate-payload", validatePayload)
+	http.HandleFunc("/diff-jsight", diffJSight)
+	http.HandleFunc("/lint-jsight", lintJSight)
+	http.HandleFunc("/editor/hover", editorHandler(editorHoverFeature))
+	http.HandleFunc("/editor/definition", editorHandler(editorDefinitionFeature))
+	http.HandleFunc("/editor/references", editorHandler(editorReferencesFeature))
+	http.HandleFunc("/editor/completion", editorHandler(editorCompletionFeature))
+
+	upstream := os.Getenv("JSIGHT_SERVER_PROXY_UPSTREAM")
+
+	switch {
+	case getBoolEnv("JSIGHT_SERVER_MOCK") && upstream != "":
+		log.Fatal("The mock server and the proxy can't be enabled at the same time")
+
+	case getBoolEnv("JSIGHT_SERVER_MOCK"):
+		jAPI := mustLoadSpec()
+		http.Handle("/", newMockServer(jAPI))
+		log.Printf("The mock server of %s is enabled", os.Getenv("JSIGHT_SERVER_SPEC"))
+
+	case upstream != "":
+		u, err := url.Parse(upstream)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		jAPI := mustLoadSpec()
+		http.Handle("/", newValidatingProxy(jAPI, u, getBoolEnv("JSIGHT_SERVER_PROXY_REJECT")))
+		log.Printf("The proxy to %s validating against %s is enabled", upstream, os.Getenv("JSIGHT_SERVER_SPEC"))
+	}
 
 	server := &http.Server{
 		Addr:        ":8080",
@@ -21,3 +55,18 @@ func main() {
 		log.Fatal(err)
 	}
 }
+
+// mustLoadSpec loads the JSight API from the file specified in the
+// JSIGHT_SERVER_SPEC environment variable.
+func mustLoadSpec() kit.JApi {
+	spec := os.Getenv("JSIGHT_SERVER_SPEC")
+	if spec == "" {
+		log.Fatal("The JSIGHT_SERVER_SPEC environment variable must be set")
+	}
+
+	jAPI, je := kit.NewJapi(spec)
+	if je != nil {
+		log.Fatalf("%s: %s (line %d)", spec, je.Error(), je.Line.Int())
+	}
+	return jAPI
+}
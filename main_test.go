@@ -60,6 +60,26 @@ TYPE @cat
 			},
 		},
 
+		"POST, multipart project": {
+			func(t *testing.T) *http.Request {
+				return newMultipartProjectRequest(t, "main.jst", map[string]string{
+					"main.jst": `JSIGHT 0.3
+
+INCLUDE types/cat.jst
+`,
+					"types/cat.jst": `TYPE @cat
+	{
+		"id": 1
+	}
+`,
+				})
+			},
+			func(t *testing.T, r *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, r.Code)
+				assert.Equal(t, `{"tags":{},"userTypes":{"@cat":{"schema":{"content":{"tokenType":"object","type":"object","children":[{"key":"id","tokenType":"number","type":"integer","scalarValue":"1","optional":false}],"optional":false},"example":"{\"id\":1}","notation":"jsight"}}},"interactions":{},"jsight":"0.3","jdocExchangeVersion":"2.0.0"}`, r.Body.String())
+			},
+		},
+
 		"POST, with invalid schema": {
 			func(t *testing.T) *http.Request {
 				r, err := http.NewRequest(http.MethodPost, "/?to=jdoc-2.0", strings.NewReader("invalid"))
@@ -68,7 +88,44 @@ TYPE @cat
 			},
 			func(t *testing.T, r *httptest.ResponseRecorder) {
 				assert.Equal(t, http.StatusConflict, r.Code)
-				assert.Equal(t, `{"Status":"Error","Message":"invalid character 'i' at the directive beginning","Line":1,"Index":0}`, r.Body.String())
+				assert.Equal(t, `{"Status":"Error","Message":"invalid character 'i' at the directive beginning","Line":1,"Index":0,"Code":10001,"Layer":"scanner","File":"root","Column":1}`, r.Body.String())
+			},
+		},
+
+		"POST, with all errors": {
+			func(t *testing.T) *http.Request {
+				r, err := http.NewRequest(http.MethodPost, "/?to=jdoc-2.0&errors=all", strings.NewReader(`JSIGHT 0.3
+
+TYPE @cat
+{
+  "id": 1 // {min: "a"}
+}
+
+GET /cats
+  200 @cat
+
+GET /dogs
+  200 @dog
+`))
+				require.NoError(t, err)
+				return r
+			},
+			func(t *testing.T, r *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, r.Code)
+				assert.JSONEq(t, `{
+					"Status": "Error",
+					"Message": "Incorrect number value \"\\\"a\\\"\"",
+					"Line": 5,
+					"Index": 43,
+					"Code": 1705,
+					"Layer": "schema",
+					"File": "root",
+					"Column": 20,
+					"Errors": [
+						{"File": "root", "Line": 5, "Column": 20, "Index": 43, "Severity": "error", "Code": 1705, "Layer": "schema", "Message": "Incorrect number value \"\\\"a\\\"\""},
+						{"File": "root", "Line": 12, "Column": 3, "Index": 85, "Severity": "error", "Code": 1302, "Layer": "schema", "Message": "Type \"@dog\" not found"}
+					]
+				}`, r.Body.String())
 			},
 		},
 	}
@@ -161,18 +218,22 @@ func appendUnhandledMethod(cc map[string]testCase) {
 			func(t *testing.T, r *httptest.ResponseRecorder) {
 				assert.Equal(t, http.StatusConflict, r.Code)
 				assert.Equal(t, "application/json; charset=utf-8", r.Header().Get("Content-Type"))
-				assert.Equal(t, `{"Status":"Error","Message":"HTTP POST request required","Line":0,"Index":0}`, r.Body.String())
+				assert.Equal(t, `{"Status":"Error","Message":"HTTP POST request required","Line":0,"Index":0,"Code":30001,"Layer":"server"}`, r.Body.String())
 			},
 		}
 	}
 }
 
 func assertAll(t *testing.T, cc map[string]testCase) {
+	assertHandler(t, convertJSight, cc)
+}
+
+func assertHandler(t *testing.T, h http.HandlerFunc, cc map[string]testCase) {
 	for n, c := range cc {
 		t.Run(fmt.Sprintf("%s, without CORS", n), func(t *testing.T) {
 			r := httptest.NewRecorder()
 
-			convertJSight(r, c.request(t))
+			h(r, c.request(t))
 
 			c.asserter(t, r)
 		})
@@ -185,7 +246,7 @@ func assertAll(t *testing.T, cc map[string]testCase) {
 
 			r := httptest.NewRecorder()
 
-			convertJSight(r, c.request(t))
+			h(r, c.request(t))
 
 			c.asserter(t, r)
 			assert.Equal(t, "*", r.Header().Get("Access-Control-Allow-Origin"))
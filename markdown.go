@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jsightapi/jsight-api-core/kit"
+	"github.com/jsightapi/jsight-api-core/notation"
+)
+
+func markdownDoc(jAPI kit.JApi) ([]byte, error) {
+	page, err := newDocPage(jAPI.Catalog())
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(newMarkdown(page)), nil
+}
+
+// newMarkdown renders the documentation into one Markdown document: the table
+// of contents, a section for every tag with its interactions, user types and
+// enums. Sections have explicit anchors, because renderers generate heading
+// IDs differently.
+func newMarkdown(page docPage) string {
+	m := mdWriter{}
+
+	m.heading(1, "", mdEscape(page.Title))
+	if page.Version != "" {
+		m.paragraph("Version " + mdEscape(page.Version))
+	}
+	if page.Description != "" {
+		m.paragraph(page.Description)
+	}
+
+	m.toc(page)
+
+	if len(page.Servers) != 0 {
+		m.heading(2, "servers", "Servers")
+		for _, s := range page.Servers {
+			line := fmt.Sprintf("- `%s` %s", s.Name, s.BaseURL)
+			if s.Annotation != "" {
+				line += " — " + mdEscape(s.Annotation)
+			}
+			m.line(line)
+		}
+		m.line("")
+	}
+
+	for _, t := range page.Tags {
+		m.tag(t, 2)
+	}
+
+	if len(page.Types) != 0 {
+		m.heading(2, "types", "Types")
+		for _, t := range page.Types {
+			m.userType(t)
+		}
+	}
+
+	if len(page.Enums) != 0 {
+		m.heading(2, "enums", "Enums")
+		for _, e := range page.Enums {
+			m.userType(e)
+		}
+	}
+
+	return strings.TrimSuffix(m.b.String(), "\n")
+}
+
+type mdWriter struct {
+	b strings.Builder
+}
+
+func (m *mdWriter) line(s string) {
+	m.b.WriteString(s)
+	m.b.WriteString("\n")
+}
+
+func (m *mdWriter) paragraph(s string) {
+	m.line(strings.TrimSpace(s))
+	m.line("")
+}
+
+func (m *mdWriter) heading(level int, anchor, title string) {
+	if anchor != "" {
+		m.line(fmt.Sprintf(`<a id="%s"></a>`, anchor))
+		m.line("")
+	}
+	if level > 6 {
+		level = 6
+	}
+	m.line(strings.Repeat("#", level) + " " + title)
+	m.line("")
+}
+
+func (m *mdWriter) toc(page docPage) {
+	m.heading(2, "", "Contents")
+
+	if len(page.Servers) != 0 {
+		m.line("- [Servers](#servers)")
+	}
+	for _, t := range page.Tags {
+		m.tocTag(t, "")
+	}
+	if len(page.Types) != 0 {
+		m.line("- [Types](#types)")
+		for _, t := range page.Types {
+			m.line(fmt.Sprintf("  - [`%s`](#%s)", t.Name, t.Anchor))
+		}
+	}
+	if len(page.Enums) != 0 {
+		m.line("- [Enums](#enums)")
+		for _, e := range page.Enums {
+			m.line(fmt.Sprintf("  - [`%s`](#%s)", e.Name, e.Anchor))
+		}
+	}
+	m.line("")
+}
+
+func (m *mdWriter) tocTag(t *docTag, indent string) {
+	m.line(fmt.Sprintf("%s- [%s](#%s)", indent, mdEscape(t.Title), t.Anchor))
+	for _, i := range t.Interactions {
+		if !i.Ref {
+			m.line(fmt.Sprintf("%s  - [`%s %s`](#%s)", indent, i.Method, i.Path, i.Anchor))
+		}
+	}
+	for _, c := range t.Children {
+		m.tocTag(c, indent+"  ")
+	}
+}
+
+func (m *mdWriter) tag(t *docTag, level int) {
+	m.heading(level, t.Anchor, mdEscape(t.Title))
+	if t.Description != "" {
+		m.paragraph(t.Description)
+	}
+
+	for _, i := range t.Interactions {
+		m.interaction(i, level+1)
+	}
+	for _, c := range t.Children {
+		m.tag(c, level+1)
+	}
+}
+
+func (m *mdWriter) interaction(i *docInteraction, level int) {
+	title := fmt.Sprintf("`%s %s`", i.Method, i.Path)
+	if i.Ref {
+		m.paragraph(fmt.Sprintf("See [%s](#%s).", title, i.Anchor))
+		return
+	}
+
+	m.heading(level, i.Anchor, title)
+	if i.Annotation != "" {
+		m.paragraph(mdEscape(i.Annotation))
+	}
+	if i.Description != "" {
+		m.paragraph(i.Description)
+	}
+
+	for _, p := range i.Parts {
+		m.schema(p)
+	}
+
+	for _, r := range i.Responses {
+		title := "**Response " + r.Code + "**"
+		if r.Annotation != "" {
+			title += " — " + mdEscape(r.Annotation)
+		}
+		m.paragraph(title)
+
+		for _, p := range r.Parts {
+			m.schema(p)
+		}
+	}
+}
+
+func (m *mdWriter) userType(t docUserType) {
+	m.heading(3, t.Anchor, "`"+t.Name+"`")
+	if t.Description != "" {
+		m.paragraph(t.Description)
+	}
+	m.schema(t.Schema)
+}
+
+func (m *mdWriter) schema(s docSchema) {
+	if s.Title != "" {
+		m.paragraph("*" + s.Title + "*")
+	}
+	m.code("jsight", s.Code)
+
+	if s.Example != "" {
+		m.paragraph("Example:")
+		lang := "json"
+		if s.Notation == notation.SchemaNotationRegex {
+			lang = "" // The example of the regular expression is a plain text.
+		}
+		m.code(lang, s.Example)
+	}
+}
+
+// code writes the fenced code block. The fence is longer than any sequence of
+// backticks in the code.
+func (m *mdWriter) code(lang, code string) {
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+
+	m.line(fence + lang)
+	m.line(code)
+	m.line(fence)
+	m.line("")
+}
+
+var mdEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	"*", `\*`,
+	"_", `\_`,
+	"[", `\[`,
+	"]", `\]`,
+	"<", `\<`,
+	">", `\>`,
+	"#", `\#`,
+	"|", `\|`,
+)
+
+// mdEscape escapes the plain text, so it isn't interpreted as Markdown.
+func mdEscape(s string) string {
+	return mdEscaper.Replace(s)
+}
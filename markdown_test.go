@@ -0,0 +1,95 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsightapi/jsight-schema-core/fs"
+
+	"github.com/jsightapi/jsight-api-core/kit"
+)
+
+func Test_markdownDoc(t *testing.T) {
+	t.Run("positive", func(t *testing.T) {
+		t.Run("document", func(t *testing.T) {
+			jAPI, je := kit.NewJApiFromFile(fs.NewFile("root", []byte(`JSIGHT 0.3
+
+INFO
+  Title "Cats_API"
+
+GET /cats // List *all* cats.
+  Query
+  {
+    "page": 1 // {min: 1}
+  }
+  200 [@cat]
+
+TYPE @cat
+{
+  "name": "Tom" // {maxLength: 10}
+}
+`)))
+			require.Nil(t, je)
+
+			b, err := markdownDoc(jAPI)
+			require.NoError(t, err)
+			assert.Equal(t, "# Cats\\_API\n\n"+
+				"## Contents\n\n"+
+				"- [/cats](#tag-cats)\n"+
+				"  - [`GET /cats`](#interaction-http-get-cats)\n"+
+				"- [Types](#types)\n"+
+				"  - [`@cat`](#type-cat)\n\n"+
+				"<a id=\"tag-cats\"></a>\n\n"+
+				"## /cats\n\n"+
+				"<a id=\"interaction-http-get-cats\"></a>\n\n"+
+				"### `GET /cats`\n\n"+
+				"List \\*all\\* cats.\n\n"+
+				"*Query*\n\n"+
+				"```jsight\n{\n  \"page\": 1 // {min: 1}\n}\n```\n\n"+
+				"Example:\n\n"+
+				"```json\n{\n  \"page\": 1\n}\n```\n\n"+
+				"**Response 200**\n\n"+
+				"*Body*\n\n"+
+				"```jsight\n[\n  @cat\n]\n```\n\n"+
+				"Example:\n\n"+
+				"```json\n[\n  {\n    \"name\": \"Tom\"\n  }\n]\n```\n\n"+
+				"<a id=\"types\"></a>\n\n"+
+				"## Types\n\n"+
+				"<a id=\"type-cat\"></a>\n\n"+
+				"### `@cat`\n\n"+
+				"```jsight\n{\n  \"name\": \"Tom\" // {maxLength: 10}\n}\n```\n\n"+
+				"Example:\n\n"+
+				"```json\n{\n  \"name\": \"Tom\"\n}\n```\n",
+				string(b))
+		})
+
+		t.Run("tags, servers and enums", func(t *testing.T) {
+			jAPI, je := kit.NewJApiFromFile(fs.NewFile("root", []byte(testHTMLAPI)))
+			require.Nil(t, je)
+
+			b, err := markdownDoc(jAPI)
+			require.NoError(t, err)
+
+			md := string(b)
+			for _, s := range []string{
+				"# Cats \\<API\\>\n\nVersion 1.0\n\n",
+				"- [Servers](#servers)\n- [Cats](#tag-cats)\n  - [`GET /cats/{id}`](#interaction-http-get-cats-id)\n",
+				"## Servers\n\n- `@prod` https://cats.com/api\n\n",
+				"## Cats\n\nAll about cats.\n\n",
+				"**Response 404**\n\n*Body*\n\n```jsight\n/^not found$/\n```\n\nExample:\n\n```\nnot found\n```\n",
+				"### `JSON-RPC /rpc ping`\n\n*Params*\n\n",
+				"### `@color`\n\n```jsight\n[\n  \"red\", // Red.\n  \"black\"\n]\n```",
+			} {
+				assert.Contains(t, md, s)
+			}
+		})
+	})
+}
+
+func Test_mdWriter_code(t *testing.T) {
+	m := mdWriter{}
+	m.code("", "a ``` b")
+	assert.Equal(t, "````\na ``` b\n````\n\n", m.b.String())
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jsightapi/jsight-api-core/catalog"
+	"github.com/jsightapi/jsight-api-core/kit"
+)
+
+// mockServer answers HTTP requests with examples of responses described in the
+// JSight API.
+type mockServer struct {
+	router *httpRouter
+}
+
+func newMockServer(jAPI kit.JApi) *mockServer {
+	return &mockServer{
+		router: newHTTPRouter(jAPI.Catalog()),
+	}
+}
+
+func (s *mockServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s (mock)", r.Method, r.URL.Path)
+
+	if getBoolEnv("JSIGHT_SERVER_CORS") {
+		cors(w)
+	}
+
+	i, _, allowed := s.router.match(r.Method, r.URL.Path)
+	if i == nil {
+		if r.Method == http.MethodOptions && len(allowed) != 0 {
+			return
+		}
+		writeMockNotFound(w, allowed)
+		return
+	}
+
+	resp := mockResponse(i)
+	if resp == nil {
+		w.WriteHeader(http.StatusNoContent)
+		log.Print("... No responses are described")
+		return
+	}
+
+	if err := writeMockResponse(w, *resp); err != nil {
+		httpResponseWriter{writer: w}.internalServerError(err)
+	}
+}
+
+func writeMockNotFound(w http.ResponseWriter, allowed []string) {
+	if len(allowed) == 0 {
+		http.NotFound(w, nil)
+		log.Print("... Not found")
+		return
+	}
+
+	sort.Strings(allowed)
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	log.Print("... Method not allowed")
+}
+
+// mockResponse returns the first 2xx response of the interaction. If there are
+// no such responses, the first one is returned.
+func mockResponse(i *catalog.HTTPInteraction) *catalog.HTTPResponse {
+	if len(i.Responses) == 0 {
+		return nil
+	}
+
+	for k := range i.Responses {
+		if strings.HasPrefix(i.Responses[k].Code, "2") {
+			return &i.Responses[k]
+		}
+	}
+	return &i.Responses[0]
+}
+
+func writeMockResponse(w http.ResponseWriter, resp catalog.HTTPResponse) error {
+	code, err := strconv.Atoi(resp.Code)
+	if err != nil {
+		return fmt.Errorf("invalid response code %q", resp.Code)
+	}
+
+	var body []byte
+	if resp.Body != nil {
+		body, err = schemaExample(resp.Body.Schema)
+		if err != nil {
+			return err
+		}
+		if body != nil {
+			w.Header().Set("Content-Type", mockContentType(resp.Body.Format))
+		}
+	}
+
+	if resp.Headers != nil && resp.Headers.Schema != nil {
+		hh, err := mockHeaders(resp.Headers.Schema)
+		if err != nil {
+			return err
+		}
+		for k, v := range hh {
+			w.Header().Set(k, v)
+		}
+	}
+
+	w.WriteHeader(code)
+	n, _ := w.Write(body)
+
+	log.Printf("... %d (%d bytes)", code, n)
+	return nil
+}
+
+// schemaExample returns an example of data for the schema. Schemas of the any
+// and empty notations have no examples.
+//
+// Schema-core reuses the buffer of the example between calls, so a copy is
+// returned.
+func schemaExample(es catalog.ExchangeSchema) ([]byte, error) {
+	var (
+		b   []byte
+		err error
+	)
+	switch s := es.(type) {
+	case *catalog.ExchangeJSightSchema:
+		b, err = s.Example()
+	case *catalog.ExchangeRegexSchema:
+		b, err = s.Example()
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), b...), nil
+}
+
+// mockHeaders returns values of the headers from the example of the headers
+// schema.
+func mockHeaders(s *catalog.ExchangeJSightSchema) (map[string]string, error) {
+	b, err := s.Example()
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+
+	hh := make(map[string]string, len(raw))
+	for k, v := range raw {
+		var str string
+		if err := json.Unmarshal(v, &str); err != nil {
+			// Not a string, so the JSON literal is used as it is.
+			str = string(v)
+		}
+		hh[k] = str
+	}
+	return hh, nil
+}
+
+func mockContentType(f catalog.SerializeFormat) string {
+	switch f {
+	case catalog.SerializeFormatJSON:
+		return "application/json; charset=utf-8"
+	case catalog.SerializeFormatPlainString:
+		return "text/plain; charset=utf-8"
+	default:
+		return "application/octet-stream"
+	}
+}
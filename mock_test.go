@@ -0,0 +1,134 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsightapi/jsight-schema-core/fs"
+
+	"github.com/jsightapi/jsight-api-core/kit"
+)
+
+const testMockAPI = `JSIGHT 0.3
+
+GET /cats/{id}
+  Path
+  {
+    "id": 1
+  }
+  200
+    Headers
+    {
+      "X-Cache": "hit",
+      "X-Count": 1
+    }
+    Body
+    {
+      "id": 1,
+      "name": "Tom"
+    }
+  404 any
+
+GET /cats/new
+  200 regex
+    /new/
+
+POST /cats
+  400 any
+  201
+  {
+    "id": 2
+  }
+
+DELETE /cats/{id}
+  204 empty
+`
+
+func Test_mockServer(t *testing.T) {
+	jAPI, je := kit.NewJApiFromFile(fs.NewFile("root", []byte(testMockAPI)))
+	require.Nil(t, je)
+
+	s := newMockServer(jAPI)
+
+	cc := map[string]struct {
+		method   string
+		path     string
+		code     int
+		headers  map[string]string
+		expected string
+	}{
+		"example with headers": {
+			http.MethodGet,
+			"/cats/12",
+			http.StatusOK,
+			map[string]string{
+				"Content-Type": "application/json; charset=utf-8",
+				"X-Cache":      "hit",
+				"X-Count":      "1",
+			},
+			`{"id":1,"name":"Tom"}`,
+		},
+		"static path wins": {
+			http.MethodGet,
+			"/cats/new",
+			http.StatusOK,
+			map[string]string{"Content-Type": "text/plain; charset=utf-8"},
+			"new",
+		},
+		"first 2xx response": {
+			http.MethodPost,
+			"/cats/",
+			http.StatusCreated,
+			nil,
+			`{"id":2}`,
+		},
+		"empty response": {
+			http.MethodDelete,
+			"/cats/1",
+			http.StatusNoContent,
+			map[string]string{"Content-Type": ""},
+			"",
+		},
+		"path variable doesn't match the schema": {
+			http.MethodGet,
+			"/cats/tom",
+			http.StatusNotFound,
+			nil,
+			"404 page not found\n",
+		},
+		"unknown path": {
+			http.MethodGet,
+			"/dogs",
+			http.StatusNotFound,
+			nil,
+			"404 page not found\n",
+		},
+		"method not allowed": {
+			http.MethodPut,
+			"/cats/1",
+			http.StatusMethodNotAllowed,
+			map[string]string{"Allow": "DELETE, GET"},
+			"Method Not Allowed\n",
+		},
+	}
+
+	for n, c := range cc {
+		t.Run(n, func(t *testing.T) {
+			r, err := http.NewRequest(c.method, c.path, http.NoBody)
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			s.ServeHTTP(w, r)
+
+			assert.Equal(t, c.code, w.Code)
+			for k, v := range c.headers {
+				assert.Equal(t, v, w.Header().Get(k), k)
+			}
+			assert.Equal(t, c.expected, w.Body.String())
+		})
+	}
+}
@@ -10,22 +10,88 @@ import (
 	"github.com/jsightapi/jsight-api-core/kit"
 )
 
-func openapiJSON(jAPI kit.JApi) ([]byte, error) {
-	oa, oaErr := openapi.NewOpenAPI(jAPI.Catalog())
+// openapiJSON builds the OpenAPI 3.0 document and validates it if asked.
+func openapiJSON(jAPI kit.JApi, o openapiOptions) ([]byte, error) {
+	b, err := buildOpenAPI(jAPI, o)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.validate {
+		if err := validateOpenAPIDocument(b); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// buildOpenAPI builds the OpenAPI 3.0 document, which is also the base of
+// the OpenAPI 3.1 one.
+func buildOpenAPI(jAPI kit.JApi, o openapiOptions) ([]byte, error) {
+	c := jAPI.Catalog()
+
+	excluded, err := excludedTagTitles(c, o.excludeTags)
+	if err != nil {
+		return nil, err
+	}
+
+	oa, oaErr := openapi.NewOpenAPI(c)
 	if oaErr != nil {
 		return nil, oaErr
 	}
 
-	resp, jsonErr := json.MarshalIndent(oa, "", "  ")
+	if err := addJSONRPCOperations(oa, c, excluded); err != nil {
+		return nil, err
+	}
+
+	js, jsonErr := json.Marshal(oa)
 	if jsonErr != nil {
 		return nil, jsonErr
 	}
 
-	return resp, nil
+	// JSight API Core doesn't export everything the server needs, so the
+	// document is completed as a JSON object.
+	doc, err := decodeJSONObject(js)
+	if err != nil {
+		return nil, err
+	}
+
+	addServerVariables(doc, c)
+
+	if err := setDefaultServer(doc, c, o.server); err != nil {
+		return nil, err
+	}
+
+	removeTaggedOperations(&doc, excluded)
+	addTags(&doc, c, excluded)
+
+	if o.omitExamples {
+		removeExamples(doc)
+	} else if err := addExamples(doc, c); err != nil {
+		return nil, err
+	}
+
+	if o.extensions {
+		if err := addRuleExtensions(doc, c); err != nil {
+			return nil, err
+		}
+	}
+
+	if o.operationIDs == operationIDsMethodPath {
+		addOperationIDs(doc)
+	}
+
+	if o.inline {
+		if err := inlineUserTypes(&doc); err != nil {
+			return nil, err
+		}
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
 }
 
-func openapiYAML(jAPI kit.JApi) ([]byte, error) {
-	js, err := openapiJSON(jAPI)
+func openapiYAML(jAPI kit.JApi, o openapiOptions) ([]byte, error) {
+	js, err := openapiJSON(jAPI, o)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jsightapi/jsight-api-core/kit"
+)
+
+// openapi31JSON builds OpenAPI 3.1 from the OpenAPI 3.0 document. Schema Objects of
+// OpenAPI 3.1 are JSON Schema 2020-12, so they are rewritten:
+//
+//   - "nullable" becomes the "null" type (or the "null" item of "enum" and
+//     "anyOf", "allOf" becomes an option of "anyOf" along with "null"),
+//   - the single value of "enum" becomes "const",
+//   - "example" becomes "examples",
+//   - boolean "exclusiveMinimum" and "exclusiveMaximum" become numbers,
+//   - "$ref" is used along with other keywords instead of "allOf".
+//
+// The order of keys is kept, so both documents look the same.
+//
+// The server validates only OpenAPI 3.0 documents, the meta-schema of OpenAPI
+// 3.1 needs JSON Schema 2020-12.
+func openapi31JSON(jAPI kit.JApi, o openapiOptions) ([]byte, error) {
+	if o.validate {
+		return nil, newRequestError(errorCodeInvalidParameter, "the validation is not supported for the openapi-3.1.0 target")
+	}
+
+	js, err := buildOpenAPI(jAPI, o)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := decodeJSONObject(js)
+	if err != nil {
+		return nil, err
+	}
+
+	doc.set("openapi", "3.1.0")
+	convertOpenAPI31(doc)
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func openapi31YAML(jAPI kit.JApi, o openapiOptions) ([]byte, error) {
+	js, err := openapi31JSON(jAPI, o)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonToYAML(js)
+}
+
+// convertOpenAPI31 converts the Schema Objects of the OpenAPI document.
+func convertOpenAPI31(doc jsonObject) {
+	openapiWalker{schema: convertSchema31}.document(doc)
+}
+
+func convertSchema31(v any) any {
+	s, ok := v.(jsonObject)
+	if !ok {
+		return v
+	}
+
+	for _, k := range []string{"allOf", "anyOf", "oneOf"} {
+		if ss, ok := s.get(k).([]any); ok {
+			for i := range ss {
+				ss[i] = convertSchema31(ss[i])
+			}
+		}
+	}
+	if pp, ok := s.get("properties").(jsonObject); ok {
+		for i := range pp {
+			pp[i].Value = convertSchema31(pp[i].Value)
+		}
+	}
+	for _, k := range []string{"items", "additionalProperties", "not"} {
+		if i, ok := s.get(k).(jsonObject); ok {
+			s.set(k, convertSchema31(i))
+		}
+	}
+
+	convertRef31(s)
+	convertNullable31(&s)
+	convertEnum31(s)
+	convertExclusive31(&s, "exclusiveMinimum", "minimum")
+	convertExclusive31(&s, "exclusiveMaximum", "maximum")
+
+	if s.has("example") {
+		s.rename("example", "examples")
+		s.set("examples", []any{s.get("examples")})
+	}
+
+	return s
+}
+
+// convertRef31 replaces "allOf" of the single reference with the reference.
+func convertRef31(s jsonObject) {
+	allOf, ok := s.get("allOf").([]any)
+	if !ok || len(allOf) != 1 {
+		return
+	}
+
+	ref, ok := allOf[0].(jsonObject)
+	if !ok || len(ref) != 1 || ref[0].Key != "$ref" {
+		return
+	}
+
+	s.rename("allOf", "$ref")
+	s.set("$ref", ref[0].Value)
+}
+
+func convertNullable31(s *jsonObject) {
+	nullable := s.get("nullable") == true
+	s.del("nullable")
+	if !nullable {
+		return
+	}
+
+	null := jsonObject{{Key: "type", Value: "null"}}
+
+	if enum, ok := s.get("enum").([]any); ok {
+		s.set("enum", append(enum, nil))
+	}
+
+	switch {
+	case s.has("type"):
+		s.set("type", []any{s.get("type"), "null"})
+	case s.has("$ref"):
+		s.rename("$ref", "anyOf")
+		s.set("anyOf", []any{jsonObject{{Key: "$ref", Value: s.get("anyOf")}}, null})
+	case s.has("anyOf"):
+		s.set("anyOf", append(s.get("anyOf").([]any), null))
+	case s.has("oneOf"):
+		s.set("oneOf", append(s.get("oneOf").([]any), null))
+	}
+
+	// Items of allOf don't accept null whatever the type is, so allOf becomes
+	// an option along with null.
+	if allOf, ok := s.get("allOf").([]any); ok {
+		if !s.has("anyOf") {
+			s.rename("allOf", "anyOf")
+			s.set("anyOf", []any{jsonObject{{Key: "allOf", Value: allOf}}, null})
+			return
+		}
+		for i := range allOf {
+			allOf[i] = jsonObject{{Key: "anyOf", Value: []any{allOf[i], null}}}
+		}
+	}
+}
+
+// convertEnum31 replaces the enum of the single value with the constant.
+func convertEnum31(s jsonObject) {
+	enum, ok := s.get("enum").([]any)
+	if !ok || len(enum) != 1 {
+		return
+	}
+
+	if enum[0] == nil && !s.has("type") {
+		s.rename("enum", "type")
+		s.set("type", "null")
+		return
+	}
+
+	s.rename("enum", "const")
+	s.set("const", enum[0])
+}
+
+func convertExclusive31(s *jsonObject, exclusive, limit string) {
+	if s.get(exclusive) == true && s.has(limit) {
+		s.set(exclusive, s.get(limit))
+		s.del(limit)
+		return
+	}
+	s.del(exclusive)
+}
+
+// jsonObject is a JSON object which keeps the order of its keys.
+type jsonObject []jsonMember
+
+type jsonMember struct {
+	Key   string
+	Value any
+}
+
+func (o jsonObject) index(k string) int {
+	for i, m := range o {
+		if m.Key == k {
+			return i
+		}
+	}
+	return -1
+}
+
+func (o jsonObject) has(k string) bool {
+	return o.index(k) != -1
+}
+
+func (o jsonObject) get(k string) any {
+	if i := o.index(k); i != -1 {
+		return o[i].Value
+	}
+	return nil
+}
+
+// object returns the value of the key if it's an object.
+func (o jsonObject) object(k string) jsonObject {
+	v, _ := o.get(k).(jsonObject)
+	return v
+}
+
+// set changes the value of the existing key.
+func (o jsonObject) set(k string, v any) {
+	if i := o.index(k); i != -1 {
+		o[i].Value = v
+	}
+}
+
+// put changes the value of the key or adds the key to the end.
+func (o *jsonObject) put(k string, v any) {
+	if i := o.index(k); i != -1 {
+		(*o)[i].Value = v
+		return
+	}
+	*o = append(*o, jsonMember{Key: k, Value: v})
+}
+
+// rename changes the key keeping its position.
+func (o jsonObject) rename(from, to string) {
+	if i := o.index(from); i != -1 {
+		o[i].Key = to
+	}
+}
+
+func (o *jsonObject) del(k string) {
+	if i := o.index(k); i != -1 {
+		*o = append((*o)[:i], (*o)[i+1:]...)
+	}
+}
+
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, m := range o {
+		if i != 0 {
+			b.WriteByte(',')
+		}
+		k, err := json.Marshal(m.Key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(m.Value)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(k)
+		b.WriteByte(':')
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+func decodeJSONObject(b []byte) (jsonObject, error) {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+
+	v, err := decodeJSONValue(d)
+	if err != nil {
+		return nil, err
+	}
+
+	o, ok := v.(jsonObject)
+	if !ok {
+		return nil, errors.New("the JSON object expected")
+	}
+	return o, nil
+}
+
+func decodeJSONValue(d *json.Decoder) (any, error) {
+	t, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t {
+	case json.Delim('{'):
+		o := jsonObject{}
+		for d.More() {
+			k, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := decodeJSONValue(d)
+			if err != nil {
+				return nil, err
+			}
+			o = append(o, jsonMember{Key: k.(string), Value: v})
+		}
+		_, err = d.Token()
+		return o, err
+
+	case json.Delim('['):
+		a := []any{}
+		for d.More() {
+			v, err := decodeJSONValue(d)
+			if err != nil {
+				return nil, err
+			}
+			a = append(a, v)
+		}
+		_, err = d.Token()
+		return a, err
+
+	case json.Delim('}'), json.Delim(']'):
+		return nil, fmt.Errorf("unexpected %v", t)
+	}
+
+	return t, nil
+}
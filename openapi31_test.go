@@ -0,0 +1,288 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsightapi/jsight-schema-core/fs"
+
+	"github.com/jsightapi/jsight-api-core/kit"
+)
+
+func Test_openapi31JSON(t *testing.T) {
+	t.Run("positive", func(t *testing.T) {
+		cc := map[string]struct {
+			schema   string
+			given30  string
+			expected string
+		}{
+			"nullable scalar": {
+				`"Tom" // {nullable: true}`,
+				`{"type": "string", "example": "Tom", "nullable": true}`,
+				`{"type": ["string", "null"], "examples": ["Tom"]}`,
+			},
+			"nullable enum": {
+				`"red" // {enum: ["red", "black"], nullable: true}`,
+				`{"example": "red", "enum": ["red", "black"], "nullable": true}`,
+				`{"examples": ["red"], "enum": ["red", "black", null]}`,
+			},
+			"nullable or": {
+				`1 // {or: [{type: "integer"}, {type: "string"}], nullable: true}`,
+				`{"anyOf": [{"type": "integer"}, {"type": "string"}], "example": 1, "nullable": true}`,
+				`{"anyOf": [{"type": "integer"}, {"type": "string"}, {"type": "null"}], "examples": [1]}`,
+			},
+			"nullable user type": {
+				`@owner // {nullable: true}`,
+				`{"allOf": [{"$ref": "#/components/schemas/owner"}], "nullable": true, "example": {"name": "Bob"}}`,
+				`{"anyOf": [{"$ref": "#/components/schemas/owner"}, {"type": "null"}], "examples": [{"name": "Bob"}]}`,
+			},
+			"user type with description": {
+				`{
+  "owner": @owner // The owner.
+}`,
+				`{"type": "object", "properties": {
+					"owner": {"allOf": [{"$ref": "#/components/schemas/owner"}], "description": "The owner."}
+				}, "required": ["owner"], "additionalProperties": false, "example": {"owner": {"name": "Bob"}}}`,
+				`{"type": "object", "properties": {
+					"owner": {"$ref": "#/components/schemas/owner", "description": "The owner."}
+				}, "required": ["owner"], "additionalProperties": false, "examples": [{"owner": {"name": "Bob"}}]}`,
+			},
+			"const": {
+				`"cat" // {const: true}`,
+				`{"type": "string", "example": "cat", "enum": ["cat"]}`,
+				`{"type": "string", "examples": ["cat"], "const": "cat"}`,
+			},
+			"null": {
+				`null`,
+				`{"example": null, "enum": [null]}`,
+				`{"examples": [null], "type": "null"}`,
+			},
+			"exclusive minimum": {
+				`1 // {min: 0, exclusiveMinimum: true}`,
+				`{"type": "integer", "example": 1, "minimum": 0, "exclusiveMinimum": true}`,
+				`{"type": "integer", "examples": [1], "exclusiveMinimum": 0}`,
+			},
+			"not exclusive maximum": {
+				`1 // {max: 2, exclusiveMaximum: false}`,
+				`{"type": "integer", "example": 1, "maximum": 2}`,
+				`{"type": "integer", "examples": [1], "maximum": 2}`,
+			},
+			"nested": {
+				`[ // {nullable: true}
+    {
+      "id": 1 // {nullable: true}
+    }
+  ]`,
+				`{"type": "array", "items": {
+					"type": "object",
+					"properties": {"id": {"type": "integer", "example": 1, "nullable": true}},
+					"required": ["id"],
+					"additionalProperties": false
+				}, "nullable": true, "example": [{"id": 1}]}`,
+				`{"type": ["array", "null"], "items": {
+					"type": "object",
+					"properties": {"id": {"type": ["integer", "null"], "examples": [1]}},
+					"required": ["id"],
+					"additionalProperties": false
+				}, "examples": [[{"id": 1}]]}`,
+			},
+			"nullable allOf": {
+				`{ // {allOf: ["@owner", "@pet"], nullable: true}
+  "id": 1
+}`,
+				`{"type": "object", "properties": {"id": {"type": "integer", "example": 1}},
+					"required": ["id"], "additionalProperties": false, "nullable": true,
+					"allOf": [{"$ref": "#/components/schemas/owner"}, {"$ref": "#/components/schemas/pet"}],
+					"example": {"id": 1, "kind": "cat", "name": "Bob"}}`,
+				`{"type": ["object", "null"], "properties": {"id": {"type": "integer", "examples": [1]}},
+					"required": ["id"], "additionalProperties": false,
+					"anyOf": [
+						{"allOf": [{"$ref": "#/components/schemas/owner"}, {"$ref": "#/components/schemas/pet"}]},
+						{"type": "null"}
+					],
+					"examples": [{"id": 1, "kind": "cat", "name": "Bob"}]}`,
+			},
+		}
+
+		for n, c := range cc {
+			t.Run(n, func(t *testing.T) {
+				jsight := `JSIGHT 0.3
+
+TYPE @owner
+{
+  "name": "Bob"
+}
+
+TYPE @pet
+{
+  "kind": "cat"
+}
+
+TYPE @value
+` + c.schema + `
+
+GET /owners
+  200 @value
+`
+				doc30 := openapiDocument(t, openapiJSON, jsight)
+				doc31 := openapiDocument(t, openapi31JSON, jsight)
+
+				assert.Equal(t, "3.0.3", doc30["openapi"])
+				assert.Equal(t, "3.1.0", doc31["openapi"])
+				assert.Equal(t, mustMarshal(t, doc30["info"]), mustMarshal(t, doc31["info"]))
+				assert.Equal(t, mustMarshal(t, doc30["paths"]), mustMarshal(t, doc31["paths"]))
+
+				assert.JSONEq(t, c.given30, mustMarshal(t, componentSchema(doc30, "value")))
+				assert.JSONEq(t, c.expected, mustMarshal(t, componentSchema(doc31, "value")))
+				assert.JSONEq(t, `{
+					"type": "object",
+					"properties": {"name": {"type": "string", "example": "Bob"}},
+					"required": ["name"],
+					"additionalProperties": false,
+					"example": {"name": "Bob"}
+				}`, mustMarshal(t, componentSchema(doc30, "owner")))
+				assert.JSONEq(t, `{
+					"type": "object",
+					"properties": {"name": {"type": "string", "examples": ["Bob"]}},
+					"required": ["name"],
+					"additionalProperties": false,
+					"examples": [{"name": "Bob"}]
+				}`, mustMarshal(t, componentSchema(doc31, "owner")))
+			})
+		}
+
+		t.Run("parameters and JSON-RPC", func(t *testing.T) {
+			doc := openapiDocument(t, openapi31JSON, testJSONRPCAPI+`
+GET /cats/{id}
+  Path
+  {
+    "id": 1
+  }
+`)
+
+			assert.JSONEq(t, `[{
+				"required": true,
+				"schema": {"type": "integer", "examples": [1]},
+				"name": "id",
+				"in": "path",
+				"example": 1
+			}]`, mustMarshal(t, doc["paths"].(map[string]any)["/cats/{id}"].(map[string]any)["parameters"]))
+
+			rpc := doc["paths"].(map[string]any)["/api/rpc"].(map[string]any)["post"].(map[string]any)
+			b := mustMarshal(t, rpc)
+			assert.NotContains(t, b, `"nullable"`)
+			assert.Contains(t, b, `{"oneOf":[{"type":"string"},{"type":"integer"},{"type":"null"}]}`)
+		})
+
+		t.Run("examples with OpenAPI keys", func(t *testing.T) {
+			doc := openapiDocument(t, openapi31JSON, `JSIGHT 0.3
+
+TYPE @wrapper
+{
+  "schema": {
+    "nullable": true,
+    "enum": ["only"],
+    "example": "x"
+  }
+}
+
+GET /wrappers
+  200 @wrapper
+`)
+
+			example := `{"schema": {"nullable": true, "enum": ["only"], "example": "x"}}`
+			assert.JSONEq(t, `[`+example+`]`, mustMarshal(t, componentSchema(doc, "wrapper").(map[string]any)["examples"]))
+
+			r200 := doc["paths"].(map[string]any)["/wrappers"].(map[string]any)["get"].(map[string]any)["responses"].(map[string]any)["200"]
+			mt := r200.(map[string]any)["content"].(map[string]any)["application/json"].(map[string]any)
+			assert.JSONEq(t, example, mustMarshal(t, mt["example"]))
+		})
+
+		t.Run("keeps the order of keys", func(t *testing.T) {
+			jAPI, je := kit.NewJApiFromFile(fs.NewFile("root", []byte("JSIGHT 0.3\n")))
+			require.Nil(t, je)
+
+			b, err := openapi31JSON(jAPI, openapiOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, `{
+  "openapi": "3.1.0",
+  "info": {
+    "title": "",
+    "version": ""
+  },
+  "paths": {}
+}`, string(b))
+
+			b, err = openapi31YAML(jAPI, openapiOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, `openapi: 3.1.0
+info:
+  title: ""
+  version: ""
+paths: {}`, string(b))
+		})
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		jAPI, je := kit.NewJApiFromFile(fs.NewFile("root", []byte("JSIGHT 0.3\n\nGET /cats\n  200 any\n")))
+		require.Nil(t, je)
+
+		_, err := openapi31JSON(jAPI, openapiOptions{validate: true})
+		assert.EqualError(t, err, "the validation is not supported for the openapi-3.1.0 target")
+
+		_, err = decodeJSONObject([]byte(`[]`))
+		assert.EqualError(t, err, "the JSON object expected")
+
+		_, err = decodeJSONObject([]byte(`{"a": `))
+		assert.Error(t, err)
+	})
+}
+
+func Test_convertNullable31(t *testing.T) {
+	cc := map[string]struct {
+		given    string
+		expected string
+	}{
+		"allOf without type": {
+			`{"allOf": [{"$ref": "#/components/schemas/a"}, {"$ref": "#/components/schemas/b"}], "nullable": true}`,
+			`{"anyOf": [{"allOf": [{"$ref": "#/components/schemas/a"}, {"$ref": "#/components/schemas/b"}]}, {"type": "null"}]}`,
+		},
+		"allOf with anyOf": {
+			`{"allOf": [{"$ref": "#/components/schemas/a"}], "anyOf": [{"type": "object"}], "nullable": true}`,
+			`{"allOf": [{"anyOf": [{"$ref": "#/components/schemas/a"}, {"type": "null"}]}], "anyOf": [{"type": "object"}, {"type": "null"}]}`,
+		},
+		"not nullable allOf": {
+			`{"allOf": [{"$ref": "#/components/schemas/a"}, {"$ref": "#/components/schemas/b"}], "nullable": false}`,
+			`{"allOf": [{"$ref": "#/components/schemas/a"}, {"$ref": "#/components/schemas/b"}]}`,
+		},
+	}
+
+	for n, c := range cc {
+		t.Run(n, func(t *testing.T) {
+			s, err := decodeJSONObject([]byte(c.given))
+			require.NoError(t, err)
+
+			convertNullable31(&s)
+			assert.JSONEq(t, c.expected, mustMarshal(t, s))
+		})
+	}
+}
+
+func openapiDocument(t *testing.T, fn func(kit.JApi, openapiOptions) ([]byte, error), jsight string) map[string]any {
+	jAPI, je := kit.NewJApiFromFile(fs.NewFile("root", []byte(jsight)))
+	require.Nil(t, je)
+
+	b, err := fn(jAPI, openapiOptions{})
+	require.NoError(t, err)
+
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(b, &doc))
+	return doc
+}
+
+func componentSchema(doc map[string]any, name string) any {
+	return doc["components"].(map[string]any)["schemas"].(map[string]any)[name]
+}
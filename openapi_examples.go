@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jsightapi/jsight-api-core/catalog"
+)
+
+// addExamples adds examples to the OpenAPI document: to the media types of
+// request and response bodies, to parameters and headers, and to the schemas
+// of user types. Schemas of JSight API Core have examples of scalar properties
+// only, so tools like Swagger UI show made up samples otherwise.
+func addExamples(doc jsonObject, c *catalog.Catalog) error {
+	ss := doc.object("components").object("schemas")
+	err := c.UserTypes.Each(func(k string, ut *catalog.UserType) error {
+		i := ss.index(strings.TrimPrefix(k, "@"))
+		if i == -1 {
+			return nil
+		}
+
+		var err error
+		if ss[i].Value, err = withExample(ss[i].Value, ut.Schema); err != nil {
+			return fmt.Errorf("type %q: %w", k, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	paths := doc.object("paths")
+
+	c.Interactions.EachSafe(func(_ catalog.InteractionID, v catalog.Interaction) {
+		i, ok := v.(*catalog.HTTPInteraction)
+		if !ok || err != nil {
+			return
+		}
+
+		op := paths.object(i.Path().String()).object(strings.ToLower(i.HttpMethod.String()))
+		if op == nil {
+			return
+		}
+
+		if err = putContentExample(op.get("requestBody"), requestBodySchema(i)); err != nil {
+			err = fmt.Errorf("%s request: %w", i.Id, err)
+			return
+		}
+
+		responses := op.object("responses")
+		for _, r := range i.Responses {
+			if err = putContentExample(responses.get(r.Code), responseBodySchema(r)); err != nil {
+				err = fmt.Errorf("%s response %s: %w", i.Id, r.Code, err)
+				return
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	// Examples are copied from the schemas of parameters and headers to the
+	// parameters and headers themselves.
+	openapiWalker{parameter: copySchemaExample}.document(doc)
+	return nil
+}
+
+// putContentExample adds the example to all media types of the request body
+// or the response.
+func putContentExample(v any, es catalog.ExchangeSchema) error {
+	o, _ := v.(jsonObject)
+	content := o.object("content")
+	for i := range content {
+		var err error
+		if content[i].Value, err = withExample(content[i].Value, es); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// withExample adds the example of the schema to the OpenAPI object, if the
+// schema has an example.
+func withExample(v any, es catalog.ExchangeSchema) (any, error) {
+	o, ok := v.(jsonObject)
+	if !ok || es == nil {
+		return v, nil
+	}
+
+	b, err := schemaExample(es)
+	if err != nil || b == nil {
+		return v, err
+	}
+
+	var e any = string(b)
+	if _, ok := es.(*catalog.ExchangeJSightSchema); ok {
+		// Decoding keeps the order of keys of the example.
+		d := json.NewDecoder(bytes.NewReader(b))
+		d.UseNumber()
+		if e, err = decodeJSONValue(d); err != nil {
+			return v, err
+		}
+	}
+
+	o.put("example", e)
+	return o, nil
+}
+
+func copySchemaExample(v any) any {
+	o, ok := v.(jsonObject)
+	if !ok {
+		return v
+	}
+
+	if s := o.object("schema"); s.has("example") {
+		o.put("example", s.get("example"))
+	}
+	return o
+}
+
+// removeExamples removes the examples of JSight API Core from the Schema
+// Objects of the OpenAPI document.
+func removeExamples(doc jsonObject) {
+	openapiWalker{schema: removeSchemaExamples}.document(doc)
+}
+
+func removeSchemaExamples(v any) any {
+	s, ok := v.(jsonObject)
+	if !ok {
+		return v
+	}
+
+	for _, k := range []string{"allOf", "anyOf", "oneOf"} {
+		if ss, ok := s.get(k).([]any); ok {
+			for i := range ss {
+				ss[i] = removeSchemaExamples(ss[i])
+			}
+		}
+	}
+	if pp, ok := s.get("properties").(jsonObject); ok {
+		for i := range pp {
+			pp[i].Value = removeSchemaExamples(pp[i].Value)
+		}
+	}
+	for _, k := range []string{"items", "additionalProperties", "not"} {
+		if i, ok := s.get(k).(jsonObject); ok {
+			s.set(k, removeSchemaExamples(i))
+		}
+	}
+
+	s.del("example")
+	return s
+}
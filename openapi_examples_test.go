@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsightapi/jsight-schema-core/fs"
+
+	"github.com/jsightapi/jsight-api-core/kit"
+)
+
+const testExamplesAPI = `JSIGHT 0.3
+
+TYPE @cat
+{
+  "id": 1,
+  "name": "Tom"
+}
+
+POST /cats/{id}
+  Path
+  {
+    "id": 1
+  }
+  Request
+    Headers
+    {
+      "X-Token": "abc"
+    }
+    Body
+    {
+      "name": "Tom",
+      "age": 3
+    }
+  200 [@cat]
+  400
+    Headers
+    {
+      "X-Error": "bad"
+    }
+    Body regex
+      /^err$/
+  404 any
+`
+
+func Test_addExamples(t *testing.T) {
+	openapiDoc := func(t *testing.T, o openapiOptions) (map[string]any, string) {
+		jAPI, je := kit.NewJApiFromFile(fs.NewFile("root", []byte(testExamplesAPI)))
+		require.Nil(t, je)
+
+		b, err := openapiJSON(jAPI, o)
+		require.NoError(t, err)
+
+		var oa map[string]any
+		require.NoError(t, json.Unmarshal(b, &oa))
+		return oa, string(b)
+	}
+
+	t.Run("positive", func(t *testing.T) {
+		oa, js := openapiDoc(t, openapiOptions{})
+
+		assert.JSONEq(t, `{
+			"type": "object",
+			"properties": {
+				"id": {"type": "integer", "example": 1},
+				"name": {"type": "string", "example": "Tom"}
+			},
+			"required": ["id", "name"],
+			"additionalProperties": false,
+			"example": {"id": 1, "name": "Tom"}
+		}`, mustMarshal(t, oa["components"].(map[string]any)["schemas"].(map[string]any)["cat"]))
+
+		pi := oa["paths"].(map[string]any)["/cats/{id}"].(map[string]any)
+		assert.Equal(t, 1.0, pi["parameters"].([]any)[0].(map[string]any)["example"])
+
+		op := pi["post"].(map[string]any)
+		assert.Equal(t, "abc", op["parameters"].([]any)[0].(map[string]any)["example"])
+
+		body := op["requestBody"].(map[string]any)["content"].(map[string]any)["application/json"].(map[string]any)
+		assert.Equal(t, map[string]any{"name": "Tom", "age": 3.0}, body["example"])
+		// The order of keys is kept.
+		assert.Contains(t, js, `"example": {
+                "name": "Tom",
+                "age": 3
+              }`)
+
+		responses := op["responses"].(map[string]any)
+		assert.Equal(t, []any{map[string]any{"id": 1.0, "name": "Tom"}},
+			responses["200"].(map[string]any)["content"].(map[string]any)["application/json"].(map[string]any)["example"])
+
+		r400 := responses["400"].(map[string]any)
+		assert.Equal(t, "err", r400["content"].(map[string]any)["text/plain"].(map[string]any)["example"])
+		assert.Equal(t, "bad", r400["headers"].(map[string]any)["X-Error"].(map[string]any)["example"])
+
+		assert.NotContains(t, responses["404"].(map[string]any)["content"].(map[string]any)["*/*"], "example")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		_, js := openapiDoc(t, openapiOptions{omitExamples: true})
+		assert.NotContains(t, js, `"example"`)
+	})
+}
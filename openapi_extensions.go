@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	schema "github.com/jsightapi/jsight-schema-core"
+
+	"github.com/jsightapi/jsight-api-core/catalog"
+)
+
+// jsonTypes are JSight types which OpenAPI expresses exactly.
+var jsonTypes = []string{"object", "array", "string", "integer", "float", "boolean", "null", "any", "mixed"}
+
+// addRuleExtensions adds JSight rules which OpenAPI can't express exactly to
+// the Schema Objects of user types, bodies, parameters and headers:
+//
+//   - x-jsight-type is the JSight type which isn't a JSON type, e.g. "email"
+//     or "decimal",
+//   - x-jsight-precision is the precision of the decimal,
+//   - x-jsight-enum is the name of the user enum.
+func addRuleExtensions(doc jsonObject, c *catalog.Catalog) error {
+	ss := doc.object("components").object("schemas")
+	err := c.UserTypes.Each(func(k string, ut *catalog.UserType) error {
+		i := ss.index(strings.TrimPrefix(k, "@"))
+		if i == -1 {
+			return nil
+		}
+
+		node, ok, err := exchangeSchemaAST(ut.Schema)
+		if err != nil {
+			return fmt.Errorf("type %q: %w", k, err)
+		}
+		if ok {
+			ss[i].Value = ruleExtensions(ss[i].Value, node)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	paths := doc.object("paths")
+
+	c.Interactions.EachSafe(func(_ catalog.InteractionID, v catalog.Interaction) {
+		i, ok := v.(*catalog.HTTPInteraction)
+		if !ok || err != nil {
+			return
+		}
+
+		pi := paths.object(i.Path().String())
+		op := pi.object(strings.ToLower(i.HttpMethod.String()))
+		if op == nil {
+			return
+		}
+
+		var query, headers, pathVariables *catalog.ExchangeJSightSchema
+		if i.Query != nil {
+			query = i.Query.Schema
+		}
+		if i.Request != nil && i.Request.HTTPRequestHeaders != nil {
+			headers = i.Request.HTTPRequestHeaders.Schema
+		}
+		if i.PathVariables != nil {
+			pathVariables = i.PathVariables.Schema
+		}
+
+		if err = parameterRuleExtensions(pi.get("parameters"), "path", pathVariables); err != nil {
+			return
+		}
+		if err = parameterRuleExtensions(op.get("parameters"), "query", query); err != nil {
+			return
+		}
+		if err = parameterRuleExtensions(op.get("parameters"), "header", headers); err != nil {
+			return
+		}
+
+		if err = contentRuleExtensions(op.get("requestBody"), requestBodySchema(i)); err != nil {
+			return
+		}
+
+		responses := op.object("responses")
+		for _, r := range i.Responses {
+			ro := responses.object(r.Code)
+			if err = contentRuleExtensions(ro, responseBodySchema(r)); err != nil {
+				return
+			}
+			if err = headerRuleExtensions(ro.object("headers"), responseHeadersSchema(r)); err != nil {
+				return
+			}
+		}
+	})
+	return err
+}
+
+// exchangeSchemaAST returns the AST of JSight schemas.
+func exchangeSchemaAST(es catalog.ExchangeSchema) (schema.ASTNode, bool, error) {
+	s, ok := es.(*catalog.ExchangeJSightSchema)
+	if !ok || s == nil {
+		return schema.ASTNode{}, false, nil
+	}
+
+	node, err := s.GetAST()
+	return node, err == nil, err
+}
+
+func contentRuleExtensions(v any, es catalog.ExchangeSchema) error {
+	node, ok, err := exchangeSchemaAST(es)
+	if !ok {
+		return err
+	}
+
+	o, _ := v.(jsonObject)
+	for _, m := range o.object("content") {
+		if mt, ok := m.Value.(jsonObject); ok {
+			mt.set("schema", ruleExtensions(mt.get("schema"), node))
+		}
+	}
+	return nil
+}
+
+// parameterRuleExtensions adds extensions to the schemas of the parameters of
+// the given location. The schema of the parameters is the object of all of
+// them.
+func parameterRuleExtensions(v any, in string, s *catalog.ExchangeJSightSchema) error {
+	node, ok, err := exchangeSchemaAST(s)
+	if !ok {
+		return err
+	}
+
+	pp, _ := v.([]any)
+	for _, p := range pp {
+		po, ok := p.(jsonObject)
+		if !ok || po.get("in") != in {
+			continue
+		}
+		if prop := node.ObjectProperty(fmt.Sprint(po.get("name"))); prop != nil {
+			po.set("schema", ruleExtensions(po.get("schema"), *prop))
+		}
+	}
+	return nil
+}
+
+func headerRuleExtensions(hh jsonObject, s *catalog.ExchangeJSightSchema) error {
+	node, ok, err := exchangeSchemaAST(s)
+	if !ok {
+		return err
+	}
+
+	for _, m := range hh {
+		ho, ok := m.Value.(jsonObject)
+		if !ok {
+			continue
+		}
+		if prop := node.ObjectProperty(m.Key); prop != nil {
+			ho.set("schema", ruleExtensions(ho.get("schema"), *prop))
+		}
+	}
+	return nil
+}
+
+// ruleExtensions adds the extensions to the Schema Object of the node and to
+// the Schema Objects of its properties and items.
+func ruleExtensions(v any, node schema.ASTNode) any {
+	so, ok := v.(jsonObject)
+	if !ok {
+		return v
+	}
+
+	if r, ok := rule(node, "type"); ok && !strings.HasPrefix(r.Value, "@") && !containsString(jsonTypes, r.Value) {
+		so.put("x-jsight-type", r.Value)
+	}
+	if r, ok := rule(node, "precision"); ok {
+		so.put("x-jsight-precision", json.Number(r.Value))
+	}
+	if r, ok := rule(node, "enum"); ok && r.TokenType != schema.TokenTypeArray {
+		so.put("x-jsight-enum", r.Value)
+	}
+
+	switch node.TokenType { //nolint:exhaustive // Only objects and arrays have children.
+	case schema.TokenTypeObject:
+		if pp := so.object("properties"); pp != nil {
+			for _, c := range node.Children {
+				if i := pp.index(c.Key); i != -1 {
+					pp[i].Value = ruleExtensions(pp[i].Value, c)
+				}
+			}
+		}
+	case schema.TokenTypeArray:
+		if items := so.object("items"); items != nil && len(node.Children) == 1 {
+			so.set("items", ruleExtensions(items, node.Children[0]))
+		}
+	}
+	return so
+}
@@ -0,0 +1,728 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	jbytes "github.com/jsightapi/jsight-schema-core/bytes"
+)
+
+// importWarning describes a construct of the OpenAPI document which can't be
+// expressed in JSight.
+type importWarning struct {
+	// Pointer a JSON Pointer (RFC 6901) to the construct in the OpenAPI document.
+	Pointer string `json:"pointer"`
+
+	// Message a human-readable description of the problem.
+	Message string `json:"message"`
+}
+
+// openapiImporter converts the OpenAPI 3.0 document into the JSight code.
+type openapiImporter struct {
+	doc jsonObject
+
+	// types names of JSight user types by names of schemas of components.
+	types map[string]string
+
+	// resolving references which are being resolved, to break cycles.
+	resolving map[string]bool
+
+	warnings []importWarning
+	b        strings.Builder
+}
+
+// importOpenAPI converts the OpenAPI document in JSON or YAML into the JSight
+// code. Constructs which can't be expressed in JSight are skipped and reported
+// as warnings.
+func importOpenAPI(data []byte) (string, []importWarning, error) {
+	doc, err := decodeOpenAPI(data)
+	if err != nil {
+		return "", nil, err
+	}
+
+	v, _ := doc.get("openapi").(string)
+	if !strings.HasPrefix(v, "3.") {
+		return "", nil, newRequestError(errorCodeInvalidOpenAPIInput, "only OpenAPI 3.0 documents are supported")
+	}
+
+	im := &openapiImporter{
+		doc:       doc,
+		types:     map[string]string{},
+		resolving: map[string]bool{},
+	}
+	if !strings.HasPrefix(v, "3.0.") {
+		im.warn("/openapi", fmt.Sprintf("OpenAPI %s is converted as OpenAPI 3.0", v))
+	}
+
+	im.collectUserTypes()
+
+	im.b.WriteString("JSIGHT 0.3\n")
+	im.writeInfo()
+	im.writeServers()
+	im.writeTags()
+	im.writeUserTypes()
+	im.writePaths()
+
+	for _, k := range []string{"security", "externalDocs", "webhooks"} {
+		if doc.has(k) {
+			im.warn("/"+k, fmt.Sprintf("the %q field is not supported and is skipped", k))
+		}
+	}
+	if c, ok := doc.get("components").(jsonObject); ok && c.has("securitySchemes") {
+		im.warn("/components/securitySchemes", `the "securitySchemes" field is not supported and is skipped`)
+	}
+
+	code := im.b.String()
+	if _, je := newSingleFileProject([]byte(code)).build(); je != nil {
+		im.warn("", fmt.Sprintf("the generated JSight code is invalid: %s (line %d)", je.Error(), je.Line.Int()))
+	}
+
+	return code, im.warnings, nil
+}
+
+func (im *openapiImporter) warn(ptr, msg string) {
+	im.warnings = append(im.warnings, importWarning{Pointer: ptr, Message: msg})
+}
+
+// decodeOpenAPI decodes the document keeping the order of keys. JSON is a
+// subset of YAML, so both of them are decoded as YAML.
+func decodeOpenAPI(data []byte) (jsonObject, error) {
+	var n yaml.Node
+	if err := yaml.Unmarshal(data, &n); err != nil {
+		return nil, newRequestError(errorCodeInvalidOpenAPIInput, "invalid OpenAPI document: %w", err)
+	}
+
+	v, err := (&yamlDecoder{}).value(&n, false)
+	if err != nil {
+		return nil, newRequestError(errorCodeInvalidOpenAPIInput, "invalid OpenAPI document: %w", err)
+	}
+
+	doc, ok := v.(jsonObject)
+	if !ok {
+		return nil, newRequestError(errorCodeInvalidOpenAPIInput, "invalid OpenAPI document: an object expected")
+	}
+	return doc, nil
+}
+
+// maxYAMLAliasNodes limits the number of nodes the aliases of the document
+// expand to. Nested aliases grow exponentially ("billion laughs").
+const maxYAMLAliasNodes = 100000
+
+// yamlDecoder converts YAML nodes into JSON values.
+type yamlDecoder struct {
+	// aliasNodes the number of nodes decoded through aliases.
+	aliasNodes int
+}
+
+func (d *yamlDecoder) value(n *yaml.Node, aliased bool) (any, error) {
+	if aliased {
+		d.aliasNodes++
+		if d.aliasNodes > maxYAMLAliasNodes {
+			return nil, fmt.Errorf("aliases expand to more than %d nodes", maxYAMLAliasNodes)
+		}
+	}
+
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return nil, nil
+		}
+		return d.value(n.Content[0], aliased)
+
+	case yaml.AliasNode:
+		return d.value(n.Alias, true)
+
+	case yaml.MappingNode:
+		o := make(jsonObject, 0, len(n.Content)/2)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			v, err := d.value(n.Content[i+1], aliased)
+			if err != nil {
+				return nil, err
+			}
+			o = append(o, jsonMember{Key: n.Content[i].Value, Value: v})
+		}
+		return o, nil
+
+	case yaml.SequenceNode:
+		a := make([]any, 0, len(n.Content))
+		for _, c := range n.Content {
+			v, err := d.value(c, aliased)
+			if err != nil {
+				return nil, err
+			}
+			a = append(a, v)
+		}
+		return a, nil
+	}
+
+	switch n.ShortTag() {
+	case "!!null":
+		return nil, nil
+	case "!!bool":
+		var b bool
+		err := n.Decode(&b)
+		return b, err
+	case "!!int", "!!float":
+		var f any
+		if err := n.Decode(&f); err != nil {
+			return nil, err
+		}
+		b, err := json.Marshal(f)
+		return json.Number(b), err
+	default:
+		return n.Value, nil
+	}
+}
+
+// collectUserTypes makes names of JSight user types for schemas of components.
+func (im *openapiImporter) collectUserTypes() {
+	used := map[string]bool{}
+	for _, s := range im.components("schemas") {
+		name := jsightName(s.Key)
+		for i := 2; used[name]; i++ {
+			name = jsightName(s.Key) + strconv.Itoa(i)
+		}
+		used[name] = true
+		im.types[s.Key] = name
+	}
+}
+
+func (im *openapiImporter) components(k string) jsonObject {
+	c, _ := im.doc.get("components").(jsonObject)
+	o, _ := c.get(k).(jsonObject)
+	return o
+}
+
+// jsightName replaces characters which are not allowed in JSight names.
+func jsightName(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if !jbytes.IsValidUserTypeNameByte(c) {
+			b[i] = '_'
+		}
+	}
+	if len(b) == 0 {
+		return "_"
+	}
+	return string(b)
+}
+
+// userType returns the user type name for the reference to the schema of
+// components.
+func (im *openapiImporter) userType(ref string) (string, bool) {
+	const prefix = "#/components/schemas/"
+	if !strings.HasPrefix(ref, prefix) {
+		return "", false
+	}
+	name, ok := im.types[unescapeJSONPointer(ref[len(prefix):])]
+	return name, ok
+}
+
+// resolve returns the value of the local reference. Call leave when the value
+// is processed.
+func (im *openapiImporter) resolve(ref, ptr string) (any, bool) {
+	if !strings.HasPrefix(ref, "#/") {
+		im.warn(ptr, fmt.Sprintf("the external reference %q is not supported", ref))
+		return nil, false
+	}
+	if im.resolving[ref] {
+		im.warn(ptr, fmt.Sprintf("the recursive reference %q is not supported", ref))
+		return nil, false
+	}
+
+	var v any = im.doc
+	for _, t := range strings.Split(ref[2:], "/") {
+		t = unescapeJSONPointer(t)
+		switch vv := v.(type) {
+		case jsonObject:
+			if !vv.has(t) {
+				v = nil
+				break
+			}
+			v = vv.get(t)
+		case []any:
+			i, err := strconv.Atoi(t)
+			if err != nil || i < 0 || i >= len(vv) {
+				v = nil
+				break
+			}
+			v = vv[i]
+		default:
+			v = nil
+		}
+	}
+	if v == nil {
+		im.warn(ptr, fmt.Sprintf("the reference %q can't be resolved", ref))
+		return nil, false
+	}
+
+	im.resolving[ref] = true
+	return v, true
+}
+
+func (im *openapiImporter) leave(ref string) {
+	delete(im.resolving, ref)
+}
+
+// object returns the object or the object it refers to.
+func (im *openapiImporter) object(v any, ptr string) (jsonObject, string, func()) {
+	o, _ := v.(jsonObject)
+	ref, ok := o.get("$ref").(string)
+	if !ok {
+		return o, ptr, func() {}
+	}
+
+	r, ok := im.resolve(ref, ptr)
+	if !ok {
+		return nil, ptr, func() {}
+	}
+	o, _ = r.(jsonObject)
+	return o, ref[1:], func() { im.leave(ref) }
+}
+
+func unescapeJSONPointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~1", "/"), "~0", "~")
+}
+
+func (im *openapiImporter) writeInfo() {
+	info, _ := im.doc.get("info").(jsonObject)
+
+	title, _ := info.get("title").(string)
+	version, _ := info.get("version").(string)
+	description, _ := info.get("description").(string)
+	if title == "" && version == "" && description == "" {
+		return
+	}
+
+	im.b.WriteString("\nINFO\n")
+	if title != "" {
+		im.b.WriteString("  Title " + ruleLiteral(title) + "\n")
+	}
+	if version != "" {
+		im.b.WriteString("  Version " + ruleLiteral(version) + "\n")
+	}
+	im.writeDescription("  ", description, "/info/description")
+}
+
+// lineBreakRe matches line breaks, JSight treats all of them as such.
+var lineBreakRe = regexp.MustCompile(`\r\n|\r|\n`)
+
+// writeDescription writes the Description directive. A line starting with ")"
+// would end the text block, and the rest of the text would become directives,
+// so such descriptions are skipped.
+func (im *openapiImporter) writeDescription(indent, s, ptr string) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return
+	}
+
+	ll := lineBreakRe.Split(s, -1)
+	for _, l := range ll {
+		if strings.HasPrefix(strings.TrimSpace(l), ")") {
+			im.warn(ptr, `the description has a line starting with ")" and is skipped`)
+			return
+		}
+	}
+
+	im.b.WriteString(indent + "Description\n" + indent + "(\n")
+	for _, l := range ll {
+		if l = strings.TrimRight(l, " \t"); l != "" {
+			l = indent + "  " + l
+		}
+		im.b.WriteString(l + "\n")
+	}
+	im.b.WriteString(indent + ")\n")
+}
+
+var serverVariableRegexp = regexp.MustCompile(`\{([^}]+)\}`)
+
+// writeServers writes servers. JSight has no server variables, so they are
+// replaced with their default values.
+func (im *openapiImporter) writeServers() {
+	ss, _ := im.doc.get("servers").([]any)
+	for i, v := range ss {
+		s, _ := v.(jsonObject)
+		ptr := fmt.Sprintf("/servers/%d", i)
+
+		u, _ := s.get("url").(string)
+		if vars, ok := s.get("variables").(jsonObject); ok {
+			u = serverVariableRegexp.ReplaceAllStringFunc(u, func(m string) string {
+				vv, _ := vars.get(m[1 : len(m)-1]).(jsonObject)
+				d, _ := vv.get("default").(string)
+				return d
+			})
+			im.warn(ptr+"/variables", "server variables are not supported, they are replaced with default values")
+		}
+
+		im.b.WriteString(fmt.Sprintf("\nSERVER @server%d", i+1))
+		if d, ok := s.get("description").(string); ok && d != "" {
+			im.b.WriteString(" // " + singleLine(d))
+		}
+		im.b.WriteString("\n  BaseUrl " + ruleLiteral(u) + "\n")
+	}
+}
+
+func (im *openapiImporter) writeTags() {
+	declared := map[string]bool{}
+
+	writeTag := func(name, description, ptr string) {
+		if declared[name] {
+			return
+		}
+		declared[name] = true
+
+		im.b.WriteString("\nTAG @" + jsightName(name))
+		if jsightName(name) != name {
+			im.b.WriteString(" // " + singleLine(name))
+		}
+		im.b.WriteString("\n")
+		im.writeDescription("  ", description, ptr)
+	}
+
+	tt, _ := im.doc.get("tags").([]any)
+	for i, v := range tt {
+		t, _ := v.(jsonObject)
+		name, _ := t.get("name").(string)
+		description, _ := t.get("description").(string)
+		if name != "" {
+			writeTag(name, description, fmt.Sprintf("/tags/%d/description", i))
+		}
+	}
+
+	im.eachOperation(func(_, _ string, op jsonObject, _ string) {
+		tt, _ := op.get("tags").([]any)
+		for _, v := range tt {
+			if name, ok := v.(string); ok {
+				writeTag(name, "", "")
+			}
+		}
+	})
+}
+
+func (im *openapiImporter) writeUserTypes() {
+	for _, s := range im.components("schemas") {
+		ptr := "/components/schemas/" + escapeJSONPointer(s.Key)
+		im.resolving["#"+ptr] = true
+
+		n := im.schema(s.Value, ptr)
+
+		im.b.WriteString("\nTYPE @" + im.types[s.Key])
+		if n.annotation != "" {
+			im.b.WriteString(" // " + n.annotation)
+			n.annotation = ""
+		}
+		im.b.WriteString("\n")
+		n.write(&im.b, "", "", true)
+
+		delete(im.resolving, "#"+ptr)
+	}
+}
+
+// httpMethods HTTP methods supported by JSight in the order of the OpenAPI
+// Path Item Object.
+var httpMethods = []string{"get", "put", "post", "delete", "patch"}
+
+// eachOperation calls the function for operations of supported paths. Paths
+// with line breaks would break the JSight code, so they are skipped.
+func (im *openapiImporter) eachOperation(fn func(path, method string, op jsonObject, ptr string)) {
+	paths, _ := im.doc.get("paths").(jsonObject)
+	for _, p := range paths {
+		if lineBreakRe.MatchString(p.Key) {
+			continue
+		}
+		item, _ := p.Value.(jsonObject)
+		for _, m := range httpMethods {
+			if op, ok := item.get(m).(jsonObject); ok {
+				fn(p.Key, m, op, "/paths/"+escapeJSONPointer(p.Key)+"/"+m)
+			}
+		}
+	}
+}
+
+func (im *openapiImporter) writePaths() {
+	paths, _ := im.doc.get("paths").(jsonObject)
+	for _, p := range paths {
+		ptr := "/paths/" + escapeJSONPointer(p.Key)
+		if lineBreakRe.MatchString(p.Key) {
+			im.warn(ptr, "the path has a line break and is skipped")
+			continue
+		}
+		item, _ := p.Value.(jsonObject)
+		for _, k := range []string{"head", "options", "trace"} {
+			if item.has(k) {
+				im.warn(ptr+"/"+k, fmt.Sprintf("the HTTP method %s is not supported and is skipped", strings.ToUpper(k)))
+			}
+		}
+	}
+
+	im.eachOperation(im.writeOperation)
+}
+
+func (im *openapiImporter) writeOperation(path, method string, op jsonObject, ptr string) {
+	im.b.WriteString("\n" + strings.ToUpper(method) + " " + path)
+	if s, ok := op.get("summary").(string); ok && s != "" {
+		im.b.WriteString(" // " + singleLine(s))
+	}
+	im.b.WriteString("\n")
+
+	if tt, ok := op.get("tags").([]any); ok && len(tt) != 0 {
+		im.b.WriteString("  Tags")
+		for _, t := range tt {
+			if s, ok := t.(string); ok {
+				im.b.WriteString(" @" + jsightName(s))
+			}
+		}
+		im.b.WriteString("\n")
+	}
+	if id, ok := op.get("operationId").(string); ok && id != "" {
+		im.b.WriteString("  OperationId " + ruleLiteral(id) + "\n")
+	}
+	if d, ok := op.get("description").(string); ok {
+		im.writeDescription("  ", d, ptr+"/description")
+	}
+	for _, k := range []string{"callbacks", "security", "servers"} {
+		if op.has(k) {
+			im.warn(ptr+"/"+k, fmt.Sprintf("the %q field is not supported and is skipped", k))
+		}
+	}
+
+	pp := im.parameters(path, op, ptr)
+	if n := pp["path"]; n != nil {
+		im.writeSchemaBlock("  ", "Path", n)
+	}
+	if n := pp["query"]; n != nil {
+		im.writeSchemaBlock("  ", "Query", n)
+	}
+	im.writeRequest(op, pp["header"], ptr)
+	im.writeResponses(op, ptr)
+}
+
+// writeSchemaBlock writes the directive followed by the schema.
+func (im *openapiImporter) writeSchemaBlock(indent, directive string, n *jsightNode) {
+	im.b.WriteString(indent + directive + "\n")
+	n.write(&im.b, indent, "", true)
+}
+
+// parameters returns objects of path, query and header parameters. Parameters
+// of the operation override parameters of the path.
+func (im *openapiImporter) parameters(path string, op jsonObject, ptr string) map[string]*jsightNode {
+	type param struct {
+		name, in string
+		node     *jsightNode
+	}
+	var pp []param
+
+	add := func(v any, ptr string) {
+		o, ptr, done := im.object(v, ptr)
+		defer done()
+
+		name, _ := o.get("name").(string)
+		in, _ := o.get("in").(string)
+		if in == "cookie" {
+			im.warn(ptr, "cookie parameters are not supported and are skipped")
+			return
+		}
+
+		var n *jsightNode
+		if s := o.get("schema"); s != nil {
+			n = im.schema(s, ptr+"/schema")
+		} else {
+			im.warn(ptr, "only parameters with the schema are supported, the parameter is described as any")
+			n = anyJSightNode()
+		}
+		if d, ok := o.get("description").(string); ok && d != "" {
+			n.annotation = singleLine(d)
+		}
+		if o.get("required") != true && in != "path" {
+			n.addRuleFirst("optional", true)
+		}
+
+		for i := range pp {
+			if pp[i].name == name && pp[i].in == in {
+				pp[i].node = n
+				return
+			}
+		}
+		pp = append(pp, param{name: name, in: in, node: n})
+	}
+
+	paths, _ := im.doc.get("paths").(jsonObject)
+	item, _ := paths.get(path).(jsonObject)
+	pathParams, _ := item.get("parameters").([]any)
+	for i, v := range pathParams {
+		add(v, fmt.Sprintf("/paths/%s/parameters/%d", escapeJSONPointer(path), i))
+	}
+	opParams, _ := op.get("parameters").([]any)
+	for i, v := range opParams {
+		add(v, fmt.Sprintf("%s/parameters/%d", ptr, i))
+	}
+
+	nn := map[string]*jsightNode{}
+	for _, p := range pp {
+		n := nn[p.in]
+		if n == nil {
+			n = &jsightNode{object: true}
+			nn[p.in] = n
+		}
+		n.keys = append(n.keys, p.name)
+		n.children = append(n.children, p.node)
+	}
+	return nn
+}
+
+func (im *openapiImporter) writeRequest(op jsonObject, headers *jsightNode, ptr string) {
+	body, bodyPtr, done := im.object(op.get("requestBody"), ptr+"/requestBody")
+	defer done()
+
+	if body == nil && headers == nil {
+		return
+	}
+
+	notation, n := im.content(body, bodyPtr)
+	if headers == nil {
+		im.writeBody("  ", "Request", notation, n, "")
+		return
+	}
+
+	im.b.WriteString("  Request\n")
+	im.writeSchemaBlock("    ", "Headers", headers)
+	im.writeBody("    ", "Body", notation, n, "")
+}
+
+// writeBody writes the directive of the body with the notation or the schema.
+// References to user types are written in the line of the directive.
+func (im *openapiImporter) writeBody(indent, directive, notation string, n *jsightNode, annotation string) {
+	if annotation != "" {
+		annotation = " // " + annotation
+	}
+
+	switch {
+	case strings.HasPrefix(notation, "regex "):
+		// The regular expression is written in the next line, because it may
+		// have spaces.
+		im.b.WriteString(indent + directive + " regex" + annotation + "\n")
+		im.b.WriteString(indent + "  " + strings.TrimPrefix(notation, "regex ") + "\n")
+	case n == nil:
+		im.b.WriteString(indent + directive + " " + notation + annotation + "\n")
+	case isUserTypeJSightNode(n):
+		im.b.WriteString(indent + directive + " " + n.literal + annotation + "\n")
+	default:
+		im.b.WriteString(indent + directive + annotation + "\n")
+		n.write(&im.b, indent, "", true)
+	}
+}
+
+func isUserTypeJSightNode(n *jsightNode) bool {
+	return strings.HasPrefix(n.literal, "@") && !strings.Contains(n.literal, " ") &&
+		len(n.rules) == 0 && n.annotation == ""
+}
+
+// content returns the notation or the schema of the body. JSON is preferred
+// over other media types.
+func (im *openapiImporter) content(body jsonObject, ptr string) (string, *jsightNode) {
+	cc, _ := body.get("content").(jsonObject)
+	if len(cc) == 0 {
+		return "empty", nil
+	}
+
+	i := 0
+	for k, c := range cc {
+		if isJSONMediaType(c.Key) {
+			i = k
+			break
+		}
+	}
+	for k, c := range cc {
+		if k != i {
+			im.warn(ptr+"/content/"+escapeJSONPointer(c.Key), "only one media type is supported, the media type is skipped")
+		}
+	}
+
+	mt := cc[i]
+	mtPtr := ptr + "/content/" + escapeJSONPointer(mt.Key)
+	o, _ := mt.Value.(jsonObject)
+	s := o.get("schema")
+
+	switch {
+	case s == nil:
+		return "any", nil
+	case isJSONMediaType(mt.Key):
+		return "", im.schema(s, mtPtr+"/schema")
+	case strings.HasPrefix(mt.Key, "text/"):
+		if ss, ok := s.(jsonObject); ok {
+			if p, ok := ss.get("pattern").(string); ok && !lineBreakRe.MatchString(p) {
+				return "regex /" + strings.ReplaceAll(p, "/", `\/`) + "/", nil
+			}
+		}
+	}
+
+	im.warn(mtPtr, fmt.Sprintf("the %q media type is described as any", mt.Key))
+	return "any", nil
+}
+
+func isJSONMediaType(mt string) bool {
+	mt = strings.TrimSpace(strings.SplitN(mt, ";", 2)[0])
+	return mt == "application/json" || strings.HasSuffix(mt, "+json")
+}
+
+func (im *openapiImporter) writeResponses(op jsonObject, ptr string) {
+	rr, _ := op.get("responses").(jsonObject)
+	for _, r := range rr {
+		rPtr := ptr + "/responses/" + escapeJSONPointer(r.Key)
+		if _, err := strconv.Atoi(r.Key); err != nil || len(r.Key) != 3 {
+			im.warn(rPtr, fmt.Sprintf("the %q response code is not supported and is skipped", r.Key))
+			continue
+		}
+
+		im.writeResponse(r.Key, r.Value, rPtr)
+	}
+}
+
+func (im *openapiImporter) writeResponse(code string, v any, ptr string) {
+	resp, ptr, done := im.object(v, ptr)
+	defer done()
+
+	var headers *jsightNode
+	if hh, ok := resp.get("headers").(jsonObject); ok && len(hh) != 0 {
+		headers = &jsightNode{object: true}
+		for _, h := range hh {
+			hPtr := ptr + "/headers/" + escapeJSONPointer(h.Key)
+			o, hPtr, done := im.object(h.Value, hPtr)
+
+			n := anyJSightNode()
+			if s := o.get("schema"); s != nil {
+				n = im.schema(s, hPtr+"/schema")
+			}
+			if d, ok := o.get("description").(string); ok && d != "" {
+				n.annotation = singleLine(d)
+			}
+			if o.get("required") != true {
+				n.addRuleFirst("optional", true)
+			}
+			headers.keys = append(headers.keys, h.Key)
+			headers.children = append(headers.children, n)
+			done()
+		}
+	}
+
+	annotation, _ := resp.get("description").(string)
+	annotation = singleLine(annotation)
+
+	notation, n := im.content(resp, ptr)
+	if headers == nil {
+		im.writeBody("  ", code, notation, n, annotation)
+		return
+	}
+
+	if annotation != "" {
+		annotation = " // " + annotation
+	}
+	im.b.WriteString("  " + code + annotation + "\n")
+	im.writeSchemaBlock("    ", "Headers", headers)
+	im.writeBody("    ", "Body", notation, n, "")
+}
@@ -0,0 +1,688 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/jsightapi/jsight-schema-core/notations/regex"
+)
+
+// jsightNode is an element of the JSight schema built from the OpenAPI Schema
+// Object.
+type jsightNode struct {
+	// literal an example of the scalar value or a user type, e.g. `1` or `@cat`.
+	literal string
+
+	// object, array the node is an object or an array of children.
+	object bool
+	array  bool
+
+	// keys property names of the object, in the order of children.
+	keys     []string
+	children []*jsightNode
+
+	rules      []jsightRule
+	annotation string
+}
+
+// jsightRule is a rule of the JSight schema. The value is written as it is.
+type jsightRule struct {
+	name  string
+	value string
+}
+
+func (n *jsightNode) addRule(name string, v any) {
+	n.rules = append(n.rules, jsightRule{name: name, value: ruleLiteral(v)})
+}
+
+// addRuleFirst adds the rule before other rules, it's used for "optional" which
+// reads better first.
+func (n *jsightNode) addRuleFirst(name string, v any) {
+	n.rules = append([]jsightRule{{name: name, value: ruleLiteral(v)}}, n.rules...)
+}
+
+func ruleLiteral(v any) string {
+	switch vv := v.(type) {
+	case jsightRuleValue:
+		return string(vv)
+	case []any:
+		ss := make([]string, 0, len(vv))
+		for _, i := range vv {
+			ss = append(ss, ruleLiteral(i))
+		}
+		return "[" + strings.Join(ss, ", ") + "]"
+	case []string:
+		ss := make([]string, 0, len(vv))
+		for _, i := range vv {
+			ss = append(ss, ruleLiteral(i))
+		}
+		return "[" + strings.Join(ss, ", ") + "]"
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return `""`
+	}
+	return string(b)
+}
+
+// jsightRuleValue is a rule value written without any conversion.
+type jsightRuleValue string
+
+func anyJSightNode() *jsightNode {
+	n := &jsightNode{literal: `"any"`}
+	n.addRule("type", "any")
+	return n
+}
+
+// write writes the node with the given indentation. Rules and the annotation
+// are written in the comment at the end of the first line of the node.
+func (n *jsightNode) write(b *strings.Builder, indent, key string, last bool) {
+	b.WriteString(indent)
+	if key != "" {
+		b.WriteString(ruleLiteral(key) + ": ")
+	}
+
+	comma := ","
+	if last {
+		comma = ""
+	}
+
+	switch {
+	case (n.object || n.array) && len(n.children) != 0:
+		open, closing := "{", "}"
+		if n.array {
+			open, closing = "[", "]"
+		}
+		b.WriteString(open)
+		n.writeComment(b)
+		b.WriteString("\n")
+		for i, c := range n.children {
+			k := ""
+			if n.object {
+				k = n.keys[i]
+			}
+			c.write(b, indent+"  ", k, i == len(n.children)-1)
+		}
+		b.WriteString(indent + closing + comma)
+
+	case n.object:
+		b.WriteString("{}" + comma)
+		n.writeComment(b)
+
+	case n.array:
+		b.WriteString("[]" + comma)
+		n.writeComment(b)
+
+	default:
+		b.WriteString(n.literal + comma)
+		n.writeComment(b)
+	}
+	b.WriteString("\n")
+}
+
+func (n *jsightNode) writeComment(b *strings.Builder) {
+	if len(n.rules) == 0 && n.annotation == "" {
+		return
+	}
+
+	b.WriteString(" // ")
+	if len(n.rules) != 0 {
+		rr := make([]string, 0, len(n.rules))
+		for _, r := range n.rules {
+			rr = append(rr, r.name+": "+r.value)
+		}
+		b.WriteString("{" + strings.Join(rr, ", ") + "}")
+		if n.annotation != "" {
+			b.WriteString(" - ")
+		}
+	}
+	b.WriteString(n.annotation)
+}
+
+// jsightTypeByFormat maps OpenAPI string formats to JSight types.
+var jsightTypeByFormat = map[string]string{
+	"date":      "date",
+	"date-time": "datetime",
+	"email":     "email",
+	"uri":       "uri",
+	"uuid":      "uuid",
+}
+
+var exampleByJSightType = map[string]string{
+	"date":     "2006-01-02",
+	"datetime": "2006-01-02T15:04:05Z",
+	"email":    "user@example.com",
+	"uri":      "https://example.com",
+	"uuid":     "123e4567-e89b-12d3-a456-426614174000",
+}
+
+// unsupportedSchemaKeywords keywords of the Schema Object which can't be
+// expressed in JSight.
+var unsupportedSchemaKeywords = []string{
+	"not",
+	"discriminator",
+	"multipleOf",
+	"uniqueItems",
+	"minProperties",
+	"maxProperties",
+	"readOnly",
+	"writeOnly",
+}
+
+// schema converts the OpenAPI Schema Object into the JSight schema.
+func (im *openapiImporter) schema(v any, ptr string) *jsightNode {
+	s, ok := v.(jsonObject)
+	if !ok {
+		im.warn(ptr, "the schema must be an object, it is described as any")
+		return anyJSightNode()
+	}
+
+	if ref, ok := s.get("$ref").(string); ok {
+		return im.schemaRef(ref, ptr)
+	}
+
+	for _, k := range unsupportedSchemaKeywords {
+		if s.has(k) {
+			im.warn(ptr+"/"+k, fmt.Sprintf("the %q keyword is not supported and is skipped", k))
+		}
+	}
+
+	var n *jsightNode
+	switch t, _ := s.get("type").(string); {
+	case s.has("allOf"):
+		n = im.schemaAllOf(s, ptr)
+	case s.has("oneOf"):
+		n = im.schemaOr(s, "oneOf", ptr)
+	case s.has("anyOf"):
+		n = im.schemaOr(s, "anyOf", ptr)
+	case t == "object" || (t == "" && s.has("properties")):
+		n = im.schemaObject(s, ptr)
+	case t == "array" || (t == "" && s.has("items")):
+		n = im.schemaArray(s, ptr)
+	case t == "string", t == "integer", t == "number", t == "boolean":
+		n = im.schemaScalar(s, t, ptr)
+	case t == "" && s.has("enum"):
+		n = im.schemaScalar(s, enumType(s.get("enum")), ptr)
+	case t == "":
+		n = anyJSightNode()
+	default:
+		im.warn(ptr+"/type", fmt.Sprintf("the %q type is not supported, it is described as any", t))
+		n = anyJSightNode()
+	}
+
+	if s.get("nullable") == true && !isAnyJSightNode(n) {
+		n.addRule("nullable", true)
+	}
+	if d, ok := s.get("description").(string); ok {
+		n.annotation = singleLine(d)
+	}
+	return n
+}
+
+func isAnyJSightNode(n *jsightNode) bool {
+	return len(n.rules) != 0 && n.rules[0].name == "type" && n.rules[0].value == `"any"`
+}
+
+func (im *openapiImporter) schemaRef(ref, ptr string) *jsightNode {
+	if name, ok := im.userType(ref); ok {
+		return &jsightNode{literal: "@" + name}
+	}
+
+	v, ok := im.resolve(ref, ptr)
+	if !ok {
+		return anyJSightNode()
+	}
+	defer im.leave(ref)
+	return im.schema(v, ref[1:])
+}
+
+func (im *openapiImporter) schemaAllOf(s jsonObject, ptr string) *jsightNode {
+	n := &jsightNode{object: true}
+	var refs []string
+
+	items, _ := s.get("allOf").([]any)
+	for i, item := range items {
+		itemPtr := fmt.Sprintf("%s/allOf/%d", ptr, i)
+
+		o, _ := item.(jsonObject)
+		if ref, ok := o.get("$ref").(string); ok {
+			if name, ok := im.userType(ref); ok {
+				refs = append(refs, "@"+name)
+				continue
+			}
+		}
+
+		part := im.schema(item, itemPtr)
+		if !part.object {
+			im.warn(itemPtr, "only objects and references to schemas of components are supported in allOf, the item is skipped")
+			continue
+		}
+		n.keys = append(n.keys, part.keys...)
+		n.children = append(n.children, part.children...)
+		n.rules = append(n.rules, part.rules...)
+	}
+
+	if len(refs) == 1 && len(n.children) == 0 && len(n.rules) == 0 {
+		return &jsightNode{literal: refs[0]}
+	}
+
+	switch len(refs) {
+	case 0:
+	case 1:
+		n.addRule("allOf", refs[0])
+	default:
+		n.addRule("allOf", refs)
+	}
+	return n
+}
+
+// schemaOr converts oneOf and anyOf into the "or" rule. Both of them become
+// "or", because JSight doesn't check that only one item matches.
+func (im *openapiImporter) schemaOr(s jsonObject, keyword, ptr string) *jsightNode {
+	var (
+		refs    []string
+		types   []string
+		example string
+	)
+
+	items, _ := s.get(keyword).([]any)
+	for i, item := range items {
+		itemPtr := fmt.Sprintf("%s/%s/%d", ptr, keyword, i)
+
+		o, _ := item.(jsonObject)
+		if ref, ok := o.get("$ref").(string); ok {
+			if name, ok := im.userType(ref); ok {
+				refs = append(refs, "@"+name)
+				types = append(types, "@"+name)
+				continue
+			}
+		}
+
+		part := im.schema(item, itemPtr)
+		if part.object || part.array || strings.HasPrefix(part.literal, "@") {
+			im.warn(itemPtr, fmt.Sprintf("only scalars and references to schemas of components are supported in %s, the item is skipped", keyword))
+			continue
+		}
+
+		t := jsightScalarType(part)
+		if len(part.rules) > 1 || (len(part.rules) == 1 && part.rules[0].name != "type") {
+			im.warn(itemPtr, fmt.Sprintf("only the type of the %s item is kept", keyword))
+		}
+		types = append(types, t)
+		if example == "" {
+			example = part.literal
+		}
+	}
+
+	switch {
+	case len(types) == 0:
+		return anyJSightNode()
+	case len(types) == len(refs):
+		return &jsightNode{literal: strings.Join(refs, " | ")}
+	case len(types) == 1:
+		return &jsightNode{literal: example}
+	}
+
+	oo := make([]string, 0, len(types))
+	for _, t := range types {
+		oo = append(oo, "{type: "+ruleLiteral(t)+"}")
+	}
+
+	n := &jsightNode{literal: example}
+	n.addRule("or", jsightRuleValue("["+strings.Join(oo, ", ")+"]"))
+	return n
+}
+
+// jsightScalarType returns the JSight type of the scalar node built by
+// schemaScalar.
+func jsightScalarType(n *jsightNode) string {
+	for _, r := range n.rules {
+		if r.name == "type" {
+			var t string
+			_ = json.Unmarshal([]byte(r.value), &t)
+			return t
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(n.literal, `"`):
+		return "string"
+	case n.literal == "true", n.literal == "false":
+		return "boolean"
+	case strings.ContainsAny(n.literal, ".eE"):
+		return "float"
+	default:
+		return "integer"
+	}
+}
+
+func (im *openapiImporter) schemaObject(s jsonObject, ptr string) *jsightNode {
+	n := &jsightNode{object: true}
+
+	required := map[string]bool{}
+	if rr, ok := s.get("required").([]any); ok {
+		for _, r := range rr {
+			if k, ok := r.(string); ok {
+				required[k] = true
+			}
+		}
+	}
+
+	props, _ := s.get("properties").(jsonObject)
+	for _, p := range props {
+		c := im.schema(p.Value, ptr+"/properties/"+escapeJSONPointer(p.Key))
+		if !required[p.Key] {
+			c.addRuleFirst("optional", true)
+		}
+		n.keys = append(n.keys, p.Key)
+		n.children = append(n.children, c)
+	}
+
+	switch ap := s.get("additionalProperties").(type) {
+	case bool:
+		if ap {
+			n.addRule("additionalProperties", true)
+		}
+	case jsonObject:
+		n.addRule("additionalProperties", im.additionalProperties(ap, ptr+"/additionalProperties"))
+	case nil:
+		if len(props) == 0 {
+			n.addRule("additionalProperties", true)
+		}
+	}
+	return n
+}
+
+// additionalProperties returns the value of the "additionalProperties" rule,
+// which is a type name or true.
+func (im *openapiImporter) additionalProperties(s jsonObject, ptr string) any {
+	if ref, ok := s.get("$ref").(string); ok {
+		if name, ok := im.userType(ref); ok {
+			return "@" + name
+		}
+	}
+
+	if len(s) == 0 {
+		return true
+	}
+
+	if t, ok := s.get("type").(string); ok && len(s) == 1 {
+		switch t {
+		case "string", "integer", "boolean":
+			return t
+		case "number":
+			return "float"
+		}
+	}
+
+	im.warn(ptr, "only scalar types and references to schemas of components are supported in additionalProperties, any values are allowed")
+	return true
+}
+
+func (im *openapiImporter) schemaArray(s jsonObject, ptr string) *jsightNode {
+	n := &jsightNode{array: true}
+
+	item := anyJSightNode()
+	if v := s.get("items"); v != nil {
+		item = im.schema(v, ptr+"/items")
+	}
+
+	// Examples are checked by JSight, so the example array has as many items as
+	// required.
+	count := 1
+	if v, ok := jsonInt(s.get("minItems")); ok {
+		n.addRule("minItems", v)
+		if v > 1 {
+			count = int(v)
+		}
+	}
+	if v, ok := jsonInt(s.get("maxItems")); ok {
+		n.addRule("maxItems", v)
+		if v == 0 {
+			count = 0
+		}
+	}
+
+	const maxExampleItems = 10
+	if count > maxExampleItems {
+		im.warn(ptr+"/minItems", fmt.Sprintf("the example array can't have more than %d items, the rule is skipped", maxExampleItems))
+		n.rules = n.rules[1:]
+		count = 1
+	}
+
+	for i := 0; i < count; i++ {
+		n.children = append(n.children, item)
+	}
+	return n
+}
+
+func (im *openapiImporter) schemaScalar(s jsonObject, t, ptr string) *jsightNode {
+	n := &jsightNode{}
+
+	enum, _ := s.get("enum").([]any)
+	if len(enum) != 0 {
+		n.addRule("enum", enum)
+	}
+
+	switch t {
+	case "string":
+		im.stringSchema(n, s, enum, ptr)
+	case "integer", "number":
+		numberSchema(n, s, t, enum)
+	default:
+		n.literal = "true"
+		if v, ok := exampleOf(s, enum, func(v any) bool { _, ok := v.(bool); return ok }); ok {
+			n.literal = ruleLiteral(v)
+		}
+	}
+	return n
+}
+
+func (im *openapiImporter) stringSchema(n *jsightNode, s jsonObject, enum []any, ptr string) {
+	isString := func(v any) bool { _, ok := v.(string); return ok }
+
+	format, _ := s.get("format").(string)
+	jt, hasType := jsightTypeByFormat[format]
+	if hasType && len(enum) == 0 {
+		n.addRule("type", jt)
+	}
+
+	minLength, hasMin := im.stringLength(s, "minLength", ptr)
+	if hasMin {
+		n.addRule("minLength", minLength)
+	}
+	maxLength, hasMax := im.stringLength(s, "maxLength", ptr)
+	if hasMax {
+		n.addRule("maxLength", maxLength)
+	}
+	pattern, hasPattern := s.get("pattern").(string)
+	if hasPattern {
+		n.addRule("regex", pattern)
+	}
+
+	if v, ok := exampleOf(s, enum, isString); ok {
+		n.literal = ruleLiteral(v)
+		return
+	}
+
+	example := "string"
+	switch {
+	case hasType:
+		example = exampleByJSightType[jt]
+	case hasPattern:
+		b, err := regex.New("", "/"+strings.ReplaceAll(pattern, "/", `\/`)+"/").Example()
+		if err != nil {
+			im.warn(ptr+"/pattern", "an example of the pattern can't be generated, the rule is skipped")
+			n.rules = n.rules[:len(n.rules)-1]
+		} else {
+			example = string(b)
+		}
+	}
+
+	if !hasType {
+		if hasMin && int64(len(example)) < minLength {
+			example += strings.Repeat("x", int(minLength)-len(example))
+		}
+		if hasMax && int64(len(example)) > maxLength {
+			example = example[:maxLength]
+		}
+	}
+	n.literal = ruleLiteral(example)
+}
+
+// maxExampleLength limits the length of generated example strings, so the
+// document can't make the server build huge strings.
+const maxExampleLength = 1024
+
+// stringLength returns the length rule of the string schema. Negative lengths
+// and lengths of too long examples are skipped.
+func (im *openapiImporter) stringLength(s jsonObject, rule, ptr string) (int64, bool) {
+	v, ok := jsonInt(s.get(rule))
+	if !ok {
+		return 0, false
+	}
+
+	switch {
+	case v < 0:
+		im.warn(ptr+"/"+rule, "the length can't be negative, the rule is skipped")
+		return 0, false
+	case rule == "minLength" && v > maxExampleLength:
+		im.warn(ptr+"/"+rule, fmt.Sprintf("the example string can't be longer than %d characters, the rule is skipped", maxExampleLength))
+		return 0, false
+	}
+	return v, true
+}
+
+func numberSchema(n *jsightNode, s jsonObject, t string, enum []any) {
+	min, hasMin := jsonFloat(s.get("minimum"))
+	max, hasMax := jsonFloat(s.get("maximum"))
+	exclusiveMin := s.get("exclusiveMinimum") == true
+	exclusiveMax := s.get("exclusiveMaximum") == true
+
+	if hasMin {
+		n.addRule("min", s.get("minimum"))
+		if exclusiveMin {
+			n.addRule("exclusiveMinimum", true)
+		}
+	}
+	if hasMax {
+		n.addRule("max", s.get("maximum"))
+		if exclusiveMax {
+			n.addRule("exclusiveMaximum", true)
+		}
+	}
+
+	isNumber := func(v any) bool {
+		f, ok := jsonFloat(v)
+		return ok && (t == "number" || f == math.Trunc(f))
+	}
+	if v, ok := exampleOf(s, enum, isNumber); ok {
+		n.literal = numberLiteral(v.(json.Number).String(), t)
+		return
+	}
+
+	step := 1.0
+	example := 1.0
+	if t == "number" {
+		step = 0.5
+		example = 1.5
+	}
+	switch {
+	case hasMin && (example < min || (exclusiveMin && example == min)):
+		example = min
+		if exclusiveMin {
+			example += step
+		}
+	case hasMax && (example > max || (exclusiveMax && example == max)):
+		example = max
+		if exclusiveMax {
+			example -= step
+		}
+	}
+	if t == "integer" {
+		example = math.Ceil(example)
+	}
+
+	n.literal = numberLiteral(strconv.FormatFloat(example, 'f', -1, 64), t)
+}
+
+// numberLiteral returns the literal of the number. JSight infers the type from
+// the literal, so numbers always have the fractional part.
+func numberLiteral(s, t string) string {
+	if t == "number" && !strings.ContainsAny(s, ".eE") {
+		return s + ".0"
+	}
+	return s
+}
+
+// exampleOf returns the example, the default value or the first item of the
+// enum, whichever fits.
+func exampleOf(s jsonObject, enum []any, fits func(any) bool) (any, bool) {
+	for _, k := range []string{"example", "default"} {
+		if v := s.get(k); v != nil && fits(v) && inEnum(v, enum) {
+			return v, true
+		}
+	}
+	for _, v := range enum {
+		if v != nil && fits(v) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func inEnum(v any, enum []any) bool {
+	if len(enum) == 0 {
+		return true
+	}
+	for _, e := range enum {
+		if ruleLiteral(e) == ruleLiteral(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func enumType(v any) string {
+	enum, _ := v.([]any)
+	for _, e := range enum {
+		switch e.(type) {
+		case string:
+			return "string"
+		case bool:
+			return "boolean"
+		case json.Number:
+			if strings.ContainsAny(e.(json.Number).String(), ".eE") {
+				return "number"
+			}
+			return "integer"
+		}
+	}
+	return "string"
+}
+
+func jsonInt(v any) (int64, bool) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, false
+	}
+	i, err := n.Int64()
+	return i, err == nil
+}
+
+func jsonFloat(v any) (float64, bool) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, false
+	}
+	f, err := n.Float64()
+	return f, err == nil
+}
+
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,452 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testOpenAPIDocument = `openapi: 3.0.3
+info:
+  title: Petstore
+  version: 1.0.0
+  description: The pet store.
+servers:
+  - url: https://{env}.pets.com/v1
+    description: Production
+    variables:
+      env:
+        default: api
+tags:
+  - name: pets
+    description: Pets.
+paths:
+  /pets:
+    get:
+      summary: List pets
+      operationId: listPets
+      tags: [pets]
+      parameters:
+        - name: limit
+          in: query
+          description: How many items to return.
+          schema:
+            type: integer
+            minimum: 1
+            maximum: 100
+        - $ref: '#/components/parameters/Trace'
+        - name: session
+          in: cookie
+          schema:
+            type: string
+      responses:
+        '200':
+          description: A paged array of pets
+          headers:
+            X-Next:
+              schema:
+                type: string
+                format: uri
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pets'
+        default:
+          description: Unexpected error
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Pet'
+          application/xml:
+            schema:
+              $ref: '#/components/schemas/Pet'
+      responses:
+        '201':
+          description: Created
+    head:
+      responses:
+        '200':
+          description: OK
+  /pets/{petId}:
+    parameters:
+      - name: petId
+        in: path
+        required: true
+        schema:
+          type: string
+          format: uuid
+    delete:
+      responses:
+        '404':
+          description: Not found
+          content:
+            text/plain:
+              schema:
+                type: string
+                pattern: ^not found$
+components:
+  parameters:
+    Trace:
+      name: X-Trace
+      in: header
+      required: true
+      schema:
+        type: string
+  schemas:
+    Pet:
+      type: object
+      description: A pet.
+      required: [id, name]
+      properties:
+        id:
+          type: integer
+          example: 42
+        name:
+          type: string
+          minLength: 8
+        tag:
+          type: string
+          nullable: true
+          enum: [cat, dog]
+        price:
+          type: number
+          minimum: 0
+          exclusiveMinimum: true
+        code:
+          type: string
+          pattern: '^[A-Z]{3}$'
+          example: ABC
+        owner:
+          oneOf:
+            - $ref: '#/components/schemas/Owner'
+            - type: string
+        extra:
+          type: object
+          additionalProperties:
+            type: string
+        tags:
+          type: array
+          minItems: 2
+          items:
+            type: string
+            not:
+              type: integer
+    Pets:
+      type: array
+      items:
+        $ref: '#/components/schemas/Pet'
+    Owner:
+      allOf:
+        - $ref: '#/components/schemas/Named'
+        - type: object
+          properties:
+            pets:
+              type: integer
+    Named:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+          default: Tom
+`
+
+func Test_importOpenAPI(t *testing.T) {
+	t.Run("positive", func(t *testing.T) {
+		t.Run("YAML", func(t *testing.T) {
+			code, warnings, err := importOpenAPI([]byte(testOpenAPIDocument))
+			require.NoError(t, err)
+
+			assert.Equal(t, `JSIGHT 0.3
+
+INFO
+  Title "Petstore"
+  Version "1.0.0"
+  Description
+  (
+    The pet store.
+  )
+
+SERVER @server1 // Production
+  BaseUrl "https://api.pets.com/v1"
+
+TAG @pets
+  Description
+  (
+    Pets.
+  )
+
+TYPE @Pet // A pet.
+{
+  "id": 42,
+  "name": "stringxx", // {minLength: 8}
+  "tag": "cat", // {optional: true, enum: ["cat", "dog"], nullable: true}
+  "price": 1.5, // {optional: true, min: 0, exclusiveMinimum: true}
+  "code": "ABC", // {optional: true, regex: "^[A-Z]{3}$"}
+  "owner": "string", // {optional: true, or: [{type: "@Owner"}, {type: "string"}]}
+  "extra": {}, // {optional: true, additionalProperties: "string"}
+  "tags": [ // {optional: true, minItems: 2}
+    "string",
+    "string"
+  ]
+}
+
+TYPE @Pets
+[
+  @Pet
+]
+
+TYPE @Owner
+{ // {allOf: "@Named"}
+  "pets": 1 // {optional: true}
+}
+
+TYPE @Named
+{
+  "name": "Tom"
+}
+
+GET /pets // List pets
+  Tags @pets
+  OperationId "listPets"
+  Query
+  {
+    "limit": 1 // {optional: true, min: 1, max: 100} - How many items to return.
+  }
+  Request
+    Headers
+    {
+      "X-Trace": "string"
+    }
+    Body empty
+  200 // A paged array of pets
+    Headers
+    {
+      "X-Next": "https://example.com" // {optional: true, type: "uri"}
+    }
+    Body @Pets
+
+POST /pets
+  Request @Pet
+  201 empty // Created
+
+DELETE /pets/{petId}
+  Path
+  {
+    "petId": "123e4567-e89b-12d3-a456-426614174000" // {type: "uuid"}
+  }
+  404 regex // Not found
+    /^not found$/
+`, code)
+
+			assert.Equal(t, []importWarning{
+				{"/servers/0/variables", "server variables are not supported, they are replaced with default values"},
+				{"/components/schemas/Pet/properties/tags/items/not", `the "not" keyword is not supported and is skipped`},
+				{"/paths/~1pets/head", "the HTTP method HEAD is not supported and is skipped"},
+				{"/paths/~1pets/get/parameters/2", "cookie parameters are not supported and are skipped"},
+				{"/paths/~1pets/get/responses/default", `the "default" response code is not supported and is skipped`},
+				{"/paths/~1pets/post/requestBody/content/application~1xml", "only one media type is supported, the media type is skipped"},
+			}, warnings)
+		})
+
+		t.Run("JSON", func(t *testing.T) {
+			code, warnings, err := importOpenAPI([]byte(`{
+				"openapi": "3.0.0",
+				"info": {"title": "Cats", "version": "1"},
+				"paths": {
+					"/cats": {
+						"get": {
+							"responses": {
+								"200": {
+									"description": "",
+									"content": {
+										"application/json": {
+											"schema": {
+												"type": "array",
+												"items": {"$ref": "#/components/schemas/cat.v1"},
+												"maxItems": 10
+											}
+										}
+									}
+								}
+							}
+						}
+					}
+				},
+				"components": {
+					"schemas": {
+						"cat.v1": {
+							"type": "object",
+							"properties": {
+								"size": {"enum": [1, 2]},
+								"weight": {"type": "number", "example": 3, "maximum": 5}
+							},
+							"required": ["size", "weight"]
+						}
+					}
+				}
+			}`))
+			require.NoError(t, err)
+			assert.Empty(t, warnings)
+
+			assert.Equal(t, `JSIGHT 0.3
+
+INFO
+  Title "Cats"
+  Version "1"
+
+TYPE @cat_v1
+{
+  "size": 1, // {enum: [1, 2]}
+  "weight": 3.0 // {max: 5}
+}
+
+GET /cats
+  200
+  [ // {maxItems: 10}
+    @cat_v1
+  ]
+`, code)
+		})
+
+		t.Run("recursive reference", func(t *testing.T) {
+			_, warnings, err := importOpenAPI([]byte(`openapi: 3.0.3
+paths: {}
+components:
+  parameters:
+    Loop:
+      $ref: '#/components/parameters/Loop'
+  schemas:
+    Node:
+      type: object
+      properties:
+        next:
+          $ref: '#/components/schemas/Node'
+        ext:
+          $ref: 'other.yaml#/Ext'
+`))
+			require.NoError(t, err)
+			assert.Equal(t, []importWarning{
+				{"/components/schemas/Node/properties/ext", `the external reference "other.yaml#/Ext" is not supported`},
+			}, warnings)
+		})
+
+		t.Run("invalid string lengths", func(t *testing.T) {
+			code, warnings, err := importOpenAPI([]byte(`openapi: 3.0.3
+info:
+  title: Cats
+  version: '1'
+paths: {}
+components:
+  schemas:
+    Short:
+      type: string
+      maxLength: -1
+    Long:
+      type: string
+      minLength: 1000000000
+    Negative:
+      type: string
+      minLength: -5
+      maxLength: 3
+`))
+			require.NoError(t, err)
+			assert.Equal(t, []importWarning{
+				{"/components/schemas/Short/maxLength", "the length can't be negative, the rule is skipped"},
+				{"/components/schemas/Long/minLength", "the example string can't be longer than 1024 characters, the rule is skipped"},
+				{"/components/schemas/Negative/minLength", "the length can't be negative, the rule is skipped"},
+			}, warnings)
+			assert.Contains(t, code, "TYPE @Short\n\"string\"\n")
+			assert.Contains(t, code, "TYPE @Long\n\"string\"\n")
+			assert.Contains(t, code, "TYPE @Negative\n\"str\" // {maxLength: 3}\n")
+		})
+
+		t.Run("injected directives", func(t *testing.T) {
+			code, warnings, err := importOpenAPI([]byte(`{
+  "openapi": "3.0.3",
+  "info": {"title": "Cats", "version": "1", "description": "Cats.\n)\nINCLUDE secret.jst\n(\nThe rest."},
+  "tags": [{"name": "cats", "description": "Cats.\r  ) INCLUDE secret.jst"}],
+  "paths": {
+    "/cats\nINCLUDE secret.jst": {
+      "get": {"responses": {"200": {"description": "OK"}}}
+    },
+    "/dogs": {
+      "get": {
+        "summary": "Dogs.\nINCLUDE secret.jst",
+        "description": "Dogs.\n)\nINCLUDE secret.jst",
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {"text/plain": {"schema": {"pattern": "^a$/\nINCLUDE secret.jst\n"}}}
+          }
+        }
+      }
+    }
+  }
+}`))
+			require.NoError(t, err)
+			assert.Equal(t, []importWarning{
+				{"/info/description", `the description has a line starting with ")" and is skipped`},
+				{"/tags/0/description", `the description has a line starting with ")" and is skipped`},
+				{"/paths/~1cats\nINCLUDE secret.jst", "the path has a line break and is skipped"},
+				{"/paths/~1dogs/get/description", `the description has a line starting with ")" and is skipped`},
+				{"/paths/~1dogs/get/responses/200/content/text~1plain", `the "text/plain" media type is described as any`},
+			}, warnings)
+			assert.NotContains(t, code, "\nINCLUDE")
+			assert.Contains(t, code, "GET /dogs // Dogs. INCLUDE secret.jst\n")
+		})
+
+		t.Run("invalid JSight code", func(t *testing.T) {
+			_, warnings, err := importOpenAPI([]byte(`openapi: 3.0.3
+paths:
+  /cats:
+    get:
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: integer
+                example: 5
+                maximum: 1
+`))
+			require.NoError(t, err)
+			require.Len(t, warnings, 1)
+			assert.Equal(t, "", warnings[0].Pointer)
+			assert.Contains(t, warnings[0].Message, "the generated JSight code is invalid: ")
+		})
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		cc := map[string]string{
+			`swagger: "2.0"`: "only OpenAPI 3.0 documents are supported",
+			`- openapi`:      "invalid OpenAPI document: an object expected",
+			`{"openapi": `:   "invalid OpenAPI document: yaml: line 1: did not find expected node content",
+		}
+
+		for given, expected := range cc {
+			t.Run(given, func(t *testing.T) {
+				_, _, err := importOpenAPI([]byte(given))
+				assert.EqualError(t, err, expected)
+			})
+		}
+
+		t.Run("nested aliases", func(t *testing.T) {
+			doc := "openapi: 3.0.3\na0: &a0 [x, x, x, x, x, x, x, x, x]\n"
+			for i := 1; i <= 9; i++ {
+				doc += fmt.Sprintf("a%d: &a%d [*a%d, *a%d, *a%d, *a%d, *a%d, *a%d, *a%d, *a%d, *a%d]\n", i, i, i-1, i-1, i-1, i-1, i-1, i-1, i-1, i-1, i-1)
+			}
+
+			start := time.Now()
+			_, _, err := importOpenAPI([]byte(doc))
+			assert.EqualError(t, err, "invalid OpenAPI document: aliases expand to more than 100000 nodes")
+			assert.Less(t, time.Since(start), time.Second)
+		})
+	})
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"strings"
+)
+
+// maxInlinedValues limits the number of JSON values in copies of inlined
+// schemas. Types which are used many times in other types used many times grow
+// the document exponentially.
+const maxInlinedValues = 1 << 20
+
+// inlineUserTypes replaces references to user types with copies of their
+// schemas. A reference to a type inside its own schema can't be replaced, so
+// recursive types stay in the components, other types are removed.
+func inlineUserTypes(doc *jsonObject) error {
+	components := doc.object("components")
+	schemas := components.object("schemas")
+	if schemas == nil {
+		return nil
+	}
+
+	in := typeInliner{schemas: schemas, kept: map[string]bool{}, expanded: map[string]inlinedType{}}
+	for i, m := range *doc {
+		if m.Key == "components" {
+			continue
+		}
+		v, _, err := in.inline(m.Value, nil)
+		if err != nil {
+			return err
+		}
+		(*doc)[i].Value = v
+	}
+
+	// Kept types may refer to other recursive types, which are kept in turn.
+	inlined := map[string]any{}
+	for len(inlined) != len(in.kept) {
+		for _, m := range schemas {
+			if _, ok := inlined[m.Key]; ok || !in.kept[m.Key] {
+				continue
+			}
+			v, _, err := in.inline(copyJSONValue(m.Value), []string{m.Key})
+			if err != nil {
+				return err
+			}
+			inlined[m.Key] = v
+		}
+	}
+
+	kept := jsonObject{}
+	for _, m := range schemas {
+		if s, ok := inlined[m.Key]; ok {
+			kept = append(kept, jsonMember{Key: m.Key, Value: s})
+		}
+	}
+
+	if len(kept) != 0 {
+		components.set("schemas", kept)
+	} else {
+		components.del("schemas")
+	}
+
+	if len(components) != 0 {
+		doc.set("components", components)
+	} else {
+		doc.del("components")
+	}
+	return nil
+}
+
+type typeInliner struct {
+	schemas jsonObject
+
+	// kept are the names of the types which are still referred to.
+	kept map[string]bool
+
+	// expanded are the inlined schemas of types which don't depend on the
+	// types being inlined around them, so they are expanded only once.
+	expanded map[string]inlinedType
+
+	// copied the number of JSON values in copies of inlined schemas.
+	copied int
+}
+
+type inlinedType struct {
+	schema any
+	size   int
+}
+
+// inline replaces references in the value. The stack has names of the types
+// being inlined, references to them are kept. The returned index is the lowest
+// index of the stack the value refers to, or the length of the stack if the
+// value doesn't depend on it.
+func (in *typeInliner) inline(v any, stack []string) (any, int, error) {
+	outer := len(stack)
+
+	switch vv := v.(type) {
+	case jsonObject:
+		if ref, ok := vv.get("$ref").(string); ok && strings.HasPrefix(ref, openapiRefPrefix) {
+			return in.inlineRef(vv, strings.TrimPrefix(ref, openapiRefPrefix), stack)
+		}
+
+		for i := range vv {
+			c, o, err := in.inline(vv[i].Value, stack)
+			if err != nil {
+				return nil, 0, err
+			}
+			vv[i].Value = c
+			if o < outer {
+				outer = o
+			}
+		}
+		return vv, outer, nil
+
+	case []any:
+		for i := range vv {
+			c, o, err := in.inline(vv[i], stack)
+			if err != nil {
+				return nil, 0, err
+			}
+			vv[i] = c
+			if o < outer {
+				outer = o
+			}
+		}
+		return vv, outer, nil
+	}
+	return v, outer, nil
+}
+
+func (in *typeInliner) inlineRef(ref jsonObject, n string, stack []string) (any, int, error) {
+	if !in.schemas.has(n) {
+		return ref, len(stack), nil
+	}
+	for i, s := range stack {
+		if s == n {
+			in.kept[n] = true
+			return ref, i, nil
+		}
+	}
+
+	t, ok := in.expanded[n]
+	if !ok {
+		s, outer, err := in.inline(copyJSONValue(in.schemas.get(n)), append(stack[:len(stack):len(stack)], n))
+		if err != nil {
+			return nil, 0, err
+		}
+		if outer < len(stack) {
+			// The schema refers to the types around it, so it's not reused.
+			return s, outer, in.count(jsonValues(s))
+		}
+		t = inlinedType{schema: s, size: jsonValues(s)}
+		in.expanded[n] = t
+	}
+
+	if err := in.count(t.size); err != nil {
+		return nil, 0, err
+	}
+	return copyJSONValue(t.schema), len(stack), nil
+}
+
+// count adds the size of the copied schema and checks the limit.
+func (in *typeInliner) count(size int) error {
+	in.copied += size
+	if in.copied > maxInlinedValues {
+		return newRequestError(errorCodeTooLarge,
+			"the OpenAPI document with inlined types exceeds %d JSON values", maxInlinedValues)
+	}
+	return nil
+}
+
+// jsonValues returns the number of JSON values in the decoded JSON value.
+func jsonValues(v any) int {
+	n := 1
+	switch vv := v.(type) {
+	case jsonObject:
+		for _, m := range vv {
+			n += jsonValues(m.Value)
+		}
+	case []any:
+		for _, i := range vv {
+			n += jsonValues(i)
+		}
+	}
+	return n
+}
+
+// copyJSONValue makes a deep copy of the decoded JSON value.
+func copyJSONValue(v any) any {
+	switch vv := v.(type) {
+	case jsonObject:
+		o := make(jsonObject, 0, len(vv))
+		for _, m := range vv {
+			o = append(o, jsonMember{Key: m.Key, Value: copyJSONValue(m.Value)})
+		}
+		return o
+
+	case []any:
+		a := make([]any, 0, len(vv))
+		for _, i := range vv {
+			a = append(a, copyJSONValue(i))
+		}
+		return a
+	}
+	return v
+}
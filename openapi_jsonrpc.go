@@ -0,0 +1,271 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	sc "github.com/jsightapi/jsight-schema-core/openapi"
+
+	"github.com/jsightapi/jsight-api-core/catalog"
+	"github.com/jsightapi/jsight-api-core/catalog/ser/openapi"
+)
+
+// jsonSchemaObject is an OpenAPI Schema Object built by the server. Values can
+// be other Schema Objects of JSight Schema Core.
+type jsonSchemaObject map[string]any
+
+var _ sc.SchemaObject = jsonSchemaObject{}
+
+func (o jsonSchemaObject) SetDescription(s string) {
+	if s != "" {
+		o["description"] = s
+	}
+}
+
+func (o jsonSchemaObject) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any(o))
+}
+
+// jsonRPCMethods is a list of JSON-RPC 2.0 methods served on the same path.
+type jsonRPCMethods struct {
+	path    string
+	methods []*catalog.JsonRpcInteraction
+}
+
+// addJSONRPCOperations adds JSON-RPC 2.0 methods to the OpenAPI document.
+//
+// OpenAPI has no notion of JSON-RPC, so all methods of a path become a single
+// POST operation on this path. Its request body is any of the request
+// envelopes of the methods, the 200 response is any of the result envelopes
+// or the error envelope. Methods with any of the excluded tags are skipped.
+func addJSONRPCOperations(oa *openapi.OpenAPI, c *catalog.Catalog, excluded []string) error {
+	for _, mm := range groupJSONRPCMethods(c) {
+		mm.methods = withoutTaggedMethods(mm.methods, c, excluded)
+		if len(mm.methods) == 0 {
+			continue
+		}
+
+		pi, ok := oa.Paths[mm.path]
+		if !ok {
+			pi = &openapi.PathItem{}
+			oa.Paths[mm.path] = pi
+		}
+		if pi.Post != nil {
+			return fmt.Errorf("the JSON-RPC methods of the path %q conflict with the HTTP POST interaction", mm.path)
+		}
+
+		pi.Post = jsonRPCOperation(mm, c)
+	}
+	return nil
+}
+
+func groupJSONRPCMethods(c *catalog.Catalog) []jsonRPCMethods {
+	var gg []jsonRPCMethods
+	idx := map[string]int{}
+
+	c.Interactions.EachSafe(func(_ catalog.InteractionID, v catalog.Interaction) {
+		m, ok := v.(*catalog.JsonRpcInteraction)
+		if !ok {
+			return
+		}
+
+		p := m.Path().String()
+		i, ok := idx[p]
+		if !ok {
+			i = len(gg)
+			idx[p] = i
+			gg = append(gg, jsonRPCMethods{path: p})
+		}
+		gg[i].methods = append(gg[i].methods, m)
+	})
+
+	return gg
+}
+
+func withoutTaggedMethods(mm []*catalog.JsonRpcInteraction, c *catalog.Catalog, excluded []string) []*catalog.JsonRpcInteraction {
+	if len(excluded) == 0 {
+		return mm
+	}
+
+	res := make([]*catalog.JsonRpcInteraction, 0, len(mm))
+	for _, m := range mm {
+		tagged := false
+		for _, t := range appendTagTitles(nil, c, m.Tags) {
+			tagged = tagged || containsString(excluded, t)
+		}
+		if !tagged {
+			res = append(res, m)
+		}
+	}
+	return res
+}
+
+func jsonRPCOperation(mm jsonRPCMethods, c *catalog.Catalog) *openapi.Operation {
+	requests := make([]sc.SchemaObject, 0, len(mm.methods))
+	results := make([]sc.SchemaObject, 0, len(mm.methods)+1)
+	names := make([]string, 0, len(mm.methods))
+	var tags []string
+
+	for _, m := range mm.methods {
+		requests = append(requests, jsonRPCRequestEnvelope(m))
+		results = append(results, jsonRPCResultEnvelope(m))
+		names = append(names, m.Method)
+		tags = appendTagTitles(tags, c, m.Tags)
+	}
+	results = append(results, jsonRPCErrorEnvelope())
+
+	op := &openapi.Operation{
+		Summary:     "JSON-RPC 2.0 methods: " + strings.Join(names, ", "),
+		Description: jsonRPCDescription(mm.methods),
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content: &openapi.Content{
+				openapi.MediaTypeJson: &openapi.MediaTypeObject{Schema: anyOfSchema(requests)},
+			},
+		},
+		Responses: &openapi.Responses{
+			"200": &openapi.ResponseObject{
+				Description: "JSON-RPC 2.0 response",
+				Content: &openapi.Content{
+					openapi.MediaTypeJson: &openapi.MediaTypeObject{Schema: anyOfSchema(results)},
+				},
+			},
+		},
+		Tags: tags,
+	}
+
+	if len(mm.methods) == 1 && mm.methods[0].Annotation != nil {
+		op.Summary = *mm.methods[0].Annotation
+	}
+	return op
+}
+
+func jsonRPCDescription(mm []*catalog.JsonRpcInteraction) string {
+	var b strings.Builder
+	for _, m := range mm {
+		b.WriteString("- `" + m.Method + "`")
+		if m.Annotation != nil {
+			b.WriteString(" — " + *m.Annotation)
+		}
+		b.WriteString("\n")
+		if m.Description != nil {
+			b.WriteString("\n  " + strings.ReplaceAll(*m.Description, "\n", "\n  ") + "\n\n")
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func jsonRPCRequestEnvelope(m *catalog.JsonRpcInteraction) sc.SchemaObject {
+	props := jsonSchemaObject{
+		"jsonrpc": jsonRPCVersionSchema(),
+		"method": jsonSchemaObject{
+			"type": "string",
+			"enum": []string{m.Method},
+		},
+		"id": jsonRPCIDSchema(),
+	}
+	required := []string{"jsonrpc", "method"}
+
+	if m.Params != nil && m.Params.Schema != nil {
+		props["params"] = sc.NewJSchemaInfo(m.Params.Schema.JSchema).SchemaObject()
+		required = append(required, "params")
+	}
+
+	o := jsonSchemaObject{
+		"title":      m.Method,
+		"type":       "object",
+		"properties": props,
+		"required":   required,
+	}
+	if m.Annotation != nil {
+		o.SetDescription(*m.Annotation)
+	}
+	return o
+}
+
+func jsonRPCResultEnvelope(m *catalog.JsonRpcInteraction) sc.SchemaObject {
+	var result sc.SchemaObject = jsonSchemaObject{}
+	if m.Result != nil && m.Result.Schema != nil {
+		result = sc.NewJSchemaInfo(m.Result.Schema.JSchema).SchemaObject()
+	}
+
+	return jsonSchemaObject{
+		"title": m.Method + " result",
+		"type":  "object",
+		"properties": jsonSchemaObject{
+			"jsonrpc": jsonRPCVersionSchema(),
+			"result":  result,
+			"id":      jsonRPCIDSchema(),
+		},
+		"required": []string{"jsonrpc", "result", "id"},
+	}
+}
+
+func jsonRPCErrorEnvelope() sc.SchemaObject {
+	id := jsonRPCIDSchema()
+	id["nullable"] = true
+
+	return jsonSchemaObject{
+		"title": "error",
+		"type":  "object",
+		"properties": jsonSchemaObject{
+			"jsonrpc": jsonRPCVersionSchema(),
+			"error": jsonSchemaObject{
+				"type": "object",
+				"properties": jsonSchemaObject{
+					"code":    jsonSchemaObject{"type": "integer"},
+					"message": jsonSchemaObject{"type": "string"},
+					"data":    jsonSchemaObject{},
+				},
+				"required": []string{"code", "message"},
+			},
+			"id": id,
+		},
+		"required": []string{"jsonrpc", "error", "id"},
+	}
+}
+
+func jsonRPCVersionSchema() jsonSchemaObject {
+	return jsonSchemaObject{
+		"type": "string",
+		"enum": []string{"2.0"},
+	}
+}
+
+func jsonRPCIDSchema() jsonSchemaObject {
+	return jsonSchemaObject{
+		"oneOf": []jsonSchemaObject{
+			{"type": "string"},
+			{"type": "integer"},
+		},
+	}
+}
+
+func anyOfSchema(oo []sc.SchemaObject) sc.SchemaObject {
+	if len(oo) == 1 {
+		return oo[0]
+	}
+	return jsonSchemaObject{"anyOf": oo}
+}
+
+// appendTagTitles appends titles of the tags which are not in the list yet.
+func appendTagTitles(titles []string, c *catalog.Catalog, names []catalog.TagName) []string {
+	for _, n := range names {
+		t, ok := c.Tags.Get(n)
+		if !ok || containsString(titles, t.Title) {
+			continue
+		}
+		titles = append(titles, t.Title)
+	}
+	return titles
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsightapi/jsight-schema-core/fs"
+
+	"github.com/jsightapi/jsight-api-core/kit"
+)
+
+const testJSONRPCAPI = `JSIGHT 0.3
+
+TYPE @cat
+{
+  "id": 1
+}
+
+URL /api/rpc
+  Protocol json-rpc-2.0
+
+  Method getCat // Get a cat.
+    Params
+    {
+      "id": 1
+    }
+    Result
+      @cat
+
+  Method ping
+    Result
+      "pong"
+`
+
+func Test_addJSONRPCOperations(t *testing.T) {
+	t.Run("positive", func(t *testing.T) {
+		t.Run("several methods", func(t *testing.T) {
+			op := openapiOperation(t, testJSONRPCAPI, "/api/rpc", "post")
+
+			assert.Equal(t, "JSON-RPC 2.0 methods: getCat, ping", op["summary"])
+			assert.Equal(t, "- `getCat` — Get a cat.\n- `ping`", op["description"])
+
+			requests := op["requestBody"].(map[string]any)["content"].(map[string]any)["application/json"].(map[string]any)["schema"].(map[string]any)["anyOf"].([]any)
+			require.Len(t, requests, 2)
+			assert.JSONEq(t, `{
+				"title": "getCat",
+				"description": "Get a cat.",
+				"type": "object",
+				"properties": {
+					"jsonrpc": {"type": "string", "enum": ["2.0"]},
+					"method": {"type": "string", "enum": ["getCat"]},
+					"params": {
+						"type": "object",
+						"properties": {"id": {"type": "integer", "example": 1}},
+						"required": ["id"],
+						"additionalProperties": false
+					},
+					"id": {"oneOf": [{"type": "string"}, {"type": "integer"}]}
+				},
+				"required": ["jsonrpc", "method", "params"]
+			}`, mustMarshal(t, requests[0]))
+
+			results := op["responses"].(map[string]any)["200"].(map[string]any)["content"].(map[string]any)["application/json"].(map[string]any)["schema"].(map[string]any)["anyOf"].([]any)
+			require.Len(t, results, 3)
+			assert.JSONEq(t, `{
+				"title": "getCat result",
+				"type": "object",
+				"properties": {
+					"jsonrpc": {"type": "string", "enum": ["2.0"]},
+					"result": {"$ref": "#/components/schemas/cat"},
+					"id": {"oneOf": [{"type": "string"}, {"type": "integer"}]}
+				},
+				"required": ["jsonrpc", "result", "id"]
+			}`, mustMarshal(t, results[0]))
+			assert.Equal(t, "ping result", results[1].(map[string]any)["title"])
+			assert.Equal(t, "error", results[2].(map[string]any)["title"])
+		})
+
+		t.Run("single method", func(t *testing.T) {
+			op := openapiOperation(t, `JSIGHT 0.3
+
+URL /rpc
+  Protocol json-rpc-2.0
+  Method ping // Ping.
+`, "/rpc", "post")
+
+			assert.Equal(t, "Ping.", op["summary"])
+			schema := op["requestBody"].(map[string]any)["content"].(map[string]any)["application/json"].(map[string]any)["schema"].(map[string]any)
+			assert.Equal(t, "ping", schema["title"])
+		})
+
+		t.Run("HTTP interactions on the same path", func(t *testing.T) {
+			op := openapiOperation(t, testJSONRPCAPI+`
+GET /api/rpc
+  200 any
+`, "/api/rpc", "get")
+
+			assert.Contains(t, op, "responses")
+		})
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		jAPI, je := kit.NewJApiFromFile(fs.NewFile("root", []byte(testJSONRPCAPI+`
+POST /api/rpc
+  200 any
+`)))
+		require.Nil(t, je)
+
+		_, err := openapiJSON(jAPI, openapiOptions{})
+		assert.EqualError(t, err, `the JSON-RPC methods of the path "/api/rpc" conflict with the HTTP POST interaction`)
+	})
+}
+
+func openapiOperation(t *testing.T, jsight, path, method string) map[string]any {
+	return documentOperation(t, openapiWithOptions(t, jsight, openapiOptions{}), path, method)
+}
+
+func documentOperation(t *testing.T, oa map[string]any, path, method string) map[string]any {
+	op, ok := oa["paths"].(map[string]any)[path].(map[string]any)[method].(map[string]any)
+	require.True(t, ok)
+	return op
+}
+
+func mustMarshal(t *testing.T, v any) string {
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(b)
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/jsightapi/jsight-api-core/catalog"
+)
+
+// openapiOptions tune the OpenAPI document. The zero value gives the default
+// document.
+type openapiOptions struct {
+	// omitExamples removes all examples from the document, even from schemas.
+	omitExamples bool
+
+	// inline replaces references to user types with their schemas. Recursive
+	// user types are still referenced.
+	inline bool
+
+	// operationIDs is the way to make the operationId of operations without
+	// the OperationId directive: operationIDsNone or operationIDsMethodPath.
+	operationIDs string
+
+	// extensions adds JSight rules which OpenAPI can't express exactly to
+	// Schema Objects as x-jsight-* extensions.
+	extensions bool
+
+	// server is the name of the server to list first. OpenAPI tools use the
+	// first server by default.
+	server string
+
+	// excludeTags are names or titles of the tags whose interactions are left
+	// out of the document.
+	excludeTags []string
+
+	// validate checks the document against the OpenAPI 3.0 meta-schema before
+	// it is returned. It isn't supported for OpenAPI 3.1.
+	validate bool
+}
+
+const (
+	openapiTypesRef    = "ref"
+	openapiTypesInline = "inline"
+
+	operationIDsNone       = "none"
+	operationIDsMethodPath = "method-path"
+)
+
+// newOpenapiOptions reads the options from the parameters of the request.
+func newOpenapiOptions(r *http.Request) (openapiOptions, error) {
+	o := openapiOptions{
+		omitExamples: r.FormValue("examples") == "false",
+		extensions:   r.FormValue("extensions") == "true",
+		server:       r.FormValue("server"),
+		validate:     r.FormValue("validate") == "true",
+	}
+
+	switch t := r.FormValue("types"); t {
+	case "", openapiTypesRef:
+	case openapiTypesInline:
+		o.inline = true
+	default:
+		return openapiOptions{}, newRequestError(errorCodeInvalidParameter, "unknown types %q, %q or %q expected", t, openapiTypesRef, openapiTypesInline)
+	}
+
+	switch s := r.FormValue("operationIds"); s {
+	case "", operationIDsNone:
+	case operationIDsMethodPath:
+		o.operationIDs = s
+	default:
+		return openapiOptions{}, newRequestError(errorCodeInvalidParameter, "unknown operationIds %q, %q or %q expected", s, operationIDsNone, operationIDsMethodPath)
+	}
+
+	for _, t := range strings.Split(r.FormValue("excludeTags"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			o.excludeTags = append(o.excludeTags, t)
+		}
+	}
+
+	return o, nil
+}
+
+// excludedTagTitles returns titles of the tags to exclude. Operations refer to
+// tags by their titles.
+func excludedTagTitles(c *catalog.Catalog, names []string) ([]string, error) {
+	titles := make([]string, 0, len(names))
+	for _, n := range names {
+		t, ok := findTag(c.Tags, n)
+		if !ok {
+			return nil, newRequestError(errorCodeTagNotFound, "the tag %q not found", n)
+		}
+		titles = append(titles, t.Title)
+	}
+	return titles, nil
+}
+
+// findTag looks for the tag by its name or title among the tags and their
+// children.
+func findTag(tt *catalog.Tags, n string) (*catalog.Tag, bool) {
+	var found *catalog.Tag
+	tt.EachSafe(func(k catalog.TagName, t *catalog.Tag) {
+		if found != nil {
+			return
+		}
+		if string(k) == n || t.Title == n {
+			found = t
+			return
+		}
+		found, _ = findTag(t.Children, n)
+	})
+	return found, found != nil
+}
+
+// openapiOperationKeys are the keys of Operation Objects in Path Item Objects.
+var openapiOperationKeys = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// eachOperation calls fn for every operation of the document. Operations
+// returned by fn replace the original ones, nil removes the operation.
+func eachOperation(doc jsonObject, fn func(path, method string, op jsonObject) jsonObject) {
+	paths := doc.object("paths")
+	for i := range paths {
+		pi, ok := paths[i].Value.(jsonObject)
+		if !ok {
+			continue
+		}
+
+		for _, m := range openapiOperationKeys {
+			op := pi.object(m)
+			if op == nil {
+				continue
+			}
+
+			if op = fn(paths[i].Key, m, op); op != nil {
+				pi.set(m, op)
+				continue
+			}
+			pi.del(m)
+		}
+		paths[i].Value = pi
+	}
+}
+
+// removeTaggedOperations removes operations with any of the tags, and paths
+// which have no operations left.
+func removeTaggedOperations(doc *jsonObject, titles []string) {
+	if len(titles) == 0 {
+		return
+	}
+
+	eachOperation(*doc, func(_, _ string, op jsonObject) jsonObject {
+		tags, _ := op.get("tags").([]any)
+		for _, t := range tags {
+			if s, ok := t.(string); ok && containsString(titles, s) {
+				return nil
+			}
+		}
+		return op
+	})
+
+	paths := jsonObject{}
+	for _, m := range doc.object("paths") {
+		pi, _ := m.Value.(jsonObject)
+		for _, k := range openapiOperationKeys {
+			if pi.has(k) {
+				paths = append(paths, m)
+				break
+			}
+		}
+	}
+	doc.set("paths", paths)
+}
+
+// setDefaultServer moves the server to the beginning of the list of servers.
+func setDefaultServer(doc jsonObject, c *catalog.Catalog, name string) error {
+	if name == "" {
+		return nil
+	}
+
+	// The servers are in the same order as in the catalog.
+	i := -1
+	n := 0
+	c.Servers.EachSafe(func(k string, _ *catalog.Server) {
+		if k == name {
+			i = n
+		}
+		n++
+	})
+
+	servers, _ := doc.get("servers").([]any)
+	if i == -1 || i >= len(servers) {
+		return newRequestError(errorCodeServerNotFound, "the server %q not found", name)
+	}
+
+	s := servers[i]
+	copy(servers[1:i+1], servers[:i])
+	servers[0] = s
+	return nil
+}
+
+// addOperationIDs adds operationId made of the method and the path to the
+// operations which don't have it, e.g. getCatOwnersId for GET
+// /cat-owners/{id}. A number is added to the identifier if it's taken.
+func addOperationIDs(doc jsonObject) {
+	used := map[string]bool{}
+	eachOperation(doc, func(_, _ string, op jsonObject) jsonObject {
+		if id, ok := op.get("operationId").(string); ok {
+			used[id] = true
+		}
+		return op
+	})
+
+	eachOperation(doc, func(path, method string, op jsonObject) jsonObject {
+		if op.has("operationId") {
+			return op
+		}
+
+		base := methodPathOperationID(method, path)
+		id := base
+		for n := 2; used[id]; n++ {
+			id = base + strconv.Itoa(n)
+		}
+		used[id] = true
+
+		op.put("operationId", id)
+		return op
+	})
+}
+
+func methodPathOperationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(method)
+
+	upper := true
+	for _, r := range path {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
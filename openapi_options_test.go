@@ -0,0 +1,366 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsightapi/jsight-schema-core/fs"
+
+	"github.com/jsightapi/jsight-api-core/kit"
+)
+
+func openapiWithOptions(t *testing.T, jsight string, o openapiOptions) map[string]any {
+	jAPI, je := kit.NewJApiFromFile(fs.NewFile("root", []byte(jsight)))
+	require.Nil(t, je)
+
+	b, err := openapiJSON(jAPI, o)
+	require.NoError(t, err)
+
+	var oa map[string]any
+	require.NoError(t, json.Unmarshal(b, &oa))
+	return oa
+}
+
+func Test_newOpenapiOptions(t *testing.T) {
+	newOptions := func(t *testing.T, query string) (openapiOptions, error) {
+		r, err := http.NewRequest(http.MethodPost, "/?"+query, http.NoBody)
+		require.NoError(t, err)
+		return newOpenapiOptions(r)
+	}
+
+	t.Run("positive", func(t *testing.T) {
+		cc := map[string]openapiOptions{
+			"": {},
+			"types=ref&operationIds=none&examples=true": {},
+			"types=inline&operationIds=method-path&examples=false&extensions=true&server=@test&excludeTags=@internal,%20Deprecated&validate=true": {
+				omitExamples: true,
+				inline:       true,
+				operationIDs: operationIDsMethodPath,
+				extensions:   true,
+				server:       "@test",
+				excludeTags:  []string{"@internal", "Deprecated"},
+				validate:     true,
+			},
+		}
+
+		for query, expected := range cc {
+			o, err := newOptions(t, query)
+			require.NoError(t, err)
+			assert.Equal(t, expected, o, query)
+		}
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		cc := map[string]string{
+			"types=copy":          `unknown types "copy", "ref" or "inline" expected`,
+			"operationIds=random": `unknown operationIds "random", "none" or "method-path" expected`,
+		}
+
+		for query, expected := range cc {
+			_, err := newOptions(t, query)
+			assert.EqualError(t, err, expected, query)
+		}
+	})
+}
+
+func Test_setDefaultServer(t *testing.T) {
+	const jsight = `JSIGHT 0.3
+
+SERVER @prod
+  BaseUrl "https://example.com"
+
+SERVER @test
+  BaseUrl "https://test.example.com"
+
+SERVER @local
+  BaseUrl "https://localhost"
+
+GET /cats
+  200 any
+`
+
+	t.Run("positive", func(t *testing.T) {
+		oa := openapiWithOptions(t, jsight, openapiOptions{server: "@local"})
+		assert.JSONEq(t, `[
+			{"url": "https://localhost"},
+			{"url": "https://example.com"},
+			{"url": "https://test.example.com"}
+		]`, mustMarshal(t, oa["servers"]))
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		jAPI, je := kit.NewJApiFromFile(fs.NewFile("root", []byte(jsight)))
+		require.Nil(t, je)
+
+		_, err := openapiJSON(jAPI, openapiOptions{server: "@stage"})
+		assert.EqualError(t, err, `the server "@stage" not found`)
+	})
+}
+
+func Test_removeTaggedOperations(t *testing.T) {
+	const jsight = `JSIGHT 0.3
+
+TAG @internal // Internal
+
+TAG @deprecated
+
+GET /cats
+  200 any
+
+POST /cats
+  Tags @internal
+  200 any
+
+GET /health
+  Tags @internal @deprecated
+  200 any
+
+URL /rpc
+  Protocol json-rpc-2.0
+
+  Method getCats
+    Result
+      []
+
+  Method reindex
+    Tags @internal
+    Result
+      true
+`
+
+	t.Run("positive", func(t *testing.T) {
+		oa := openapiWithOptions(t, jsight, openapiOptions{excludeTags: []string{"Internal"}})
+
+		paths := oa["paths"].(map[string]any)
+		assert.Len(t, paths, 2)
+		assert.Contains(t, paths["/cats"], "get")
+		assert.NotContains(t, paths["/cats"], "post")
+		assert.Equal(t, "JSON-RPC 2.0 methods: getCats",
+			paths["/rpc"].(map[string]any)["post"].(map[string]any)["summary"])
+
+		assert.JSONEq(t, `[{"name": "@deprecated"}, {"name": "/cats"}, {"name": "/rpc"}]`, mustMarshal(t, oa["tags"]))
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		jAPI, je := kit.NewJApiFromFile(fs.NewFile("root", []byte(jsight)))
+		require.Nil(t, je)
+
+		_, err := openapiJSON(jAPI, openapiOptions{excludeTags: []string{"@beta"}})
+		assert.EqualError(t, err, `the tag "@beta" not found`)
+	})
+}
+
+func Test_addOperationIDs(t *testing.T) {
+	oa := openapiWithOptions(t, `JSIGHT 0.3
+
+GET /cat-owners/{id}
+  200 any
+
+GET /catOwners/{id}
+  200 any
+
+POST /cats
+  OperationId getCatOwnersId
+  200 any
+`, openapiOptions{operationIDs: operationIDsMethodPath})
+
+	ids := map[string]any{}
+	for p, pi := range oa["paths"].(map[string]any) {
+		for m, op := range pi.(map[string]any) {
+			if op, ok := op.(map[string]any); ok {
+				ids[m+" "+p] = op["operationId"]
+			}
+		}
+	}
+
+	assert.Equal(t, map[string]any{
+		"get /cat-owners/{id}": "getCatOwnersId2",
+		"get /catOwners/{id}":  "getCatOwnersId3",
+		"post /cats":           "getCatOwnersId",
+	}, ids)
+}
+
+func Test_methodPathOperationID(t *testing.T) {
+	cc := map[[2]string]string{
+		{"get", "/"}:                       "get",
+		{"get", "/cats"}:                   "getCats",
+		{"delete", "/cats/{catId}"}:        "deleteCatsCatId",
+		{"put", "/user_profiles/{id}/pet"}: "putUserProfilesIdPet",
+		{"post", "/api/rpc"}:               "postApiRpc",
+	}
+
+	for given, expected := range cc {
+		assert.Equal(t, expected, methodPathOperationID(given[0], given[1]), given)
+	}
+}
+
+func Test_inlineUserTypes(t *testing.T) {
+	oa := openapiWithOptions(t, `JSIGHT 0.3
+
+TYPE @cat
+{
+  "id": 1,
+  "owner": @owner
+}
+
+TYPE @owner
+{
+  "name": "Tom"
+}
+
+TYPE @node
+{
+  "value": 1,
+  "children": [@node]
+}
+
+TYPE @unused
+{}
+
+GET /cats
+  200 [@cat]
+
+GET /nodes
+  200 @node
+`, openapiOptions{inline: true, omitExamples: true})
+
+	assert.JSONEq(t, `{
+		"type": "array",
+		"items": {
+			"type": "object",
+			"properties": {
+				"id": {"type": "integer"},
+				"owner": {
+					"type": "object",
+					"properties": {"name": {"type": "string"}},
+					"required": ["name"],
+					"additionalProperties": false
+				}
+			},
+			"required": ["id", "owner"],
+			"additionalProperties": false
+		}
+	}`, mustMarshal(t, documentOperation(t, oa, "/cats", "get")["responses"].(map[string]any)["200"].(map[string]any)["content"].(map[string]any)["application/json"].(map[string]any)["schema"]))
+
+	assert.JSONEq(t, `{
+		"schemas": {
+			"node": {
+				"type": "object",
+				"properties": {
+					"value": {"type": "integer"},
+					"children": {"type": "array", "items": {"$ref": "#/components/schemas/node"}}
+				},
+				"required": ["value", "children"],
+				"additionalProperties": false
+			}
+		}
+	}`, mustMarshal(t, oa["components"]))
+}
+
+func Test_inlineUserTypes_shared(t *testing.T) {
+	ref := func(n string) string {
+		return `{"$ref": "#/components/schemas/` + n + `"}`
+	}
+
+	t.Run("positive", func(t *testing.T) {
+		doc, err := decodeJSONObject([]byte(`{
+			"paths": {"a": ` + ref("a") + `, "b": ` + ref("b") + `, "pair": [` + ref("leaf") + `, ` + ref("leaf") + `]},
+			"components": {"schemas": {
+				"a": {"items": ` + ref("b") + `},
+				"b": {"items": ` + ref("a") + `, "leaf": ` + ref("leaf") + `},
+				"leaf": {"type": "integer"}
+			}}
+		}`))
+		require.NoError(t, err)
+
+		require.NoError(t, inlineUserTypes(&doc))
+
+		// The expanded types are reused, references to recursive types are
+		// kept, so the recursive types stay in the components.
+		a := `{"items": {"items": ` + ref("a") + `, "leaf": {"type": "integer"}}}`
+		assert.JSONEq(t, `{
+			"paths": {
+				"a": `+a+`,
+				"b": {"items": `+a+`, "leaf": {"type": "integer"}},
+				"pair": [{"type": "integer"}, {"type": "integer"}]
+			},
+			"components": {"schemas": {
+				"a": {"items": {"items": `+a+`, "leaf": {"type": "integer"}}}
+			}}
+		}`, mustMarshal(t, doc))
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		// Every type refers to the next one twice, so the inlined document
+		// would have 2^40 copies of the last type.
+		var b strings.Builder
+		for i := 0; i < 40; i++ {
+			fmt.Fprintf(&b, `"t%d": {"allOf": [%s, %s]},`, i, ref(fmt.Sprintf("t%d", i+1)), ref(fmt.Sprintf("t%d", i+1)))
+		}
+		doc, err := decodeJSONObject([]byte(`{
+			"paths": {"t": ` + ref("t0") + `},
+			"components": {"schemas": {` + b.String() + `"t40": {"type": "integer"}}}
+		}`))
+		require.NoError(t, err)
+
+		err = inlineUserTypes(&doc)
+		assert.EqualError(t, err, "the OpenAPI document with inlined types exceeds 1048576 JSON values")
+		code, _ := errorCode(err)
+		assert.Equal(t, errorCodeTooLarge, code)
+	})
+}
+
+func Test_addRuleExtensions(t *testing.T) {
+	const jsight = `JSIGHT 0.3
+
+TYPE @cat
+{
+  "id": 1,
+  "email": "cat@example.com", // {type: "email"}
+  "price": 1.23, // {precision: 2}
+  "color": "red", // {enum: @color}
+  "size": "S" // {enum: ["S", "M"]}
+}
+
+ENUM @color
+[
+  "red",
+  "black"
+]
+
+GET /cats/{id}
+  Query
+  {
+    "sum": 1.2 // {precision: 1}
+  }
+  200 [@cat]
+`
+
+	t.Run("positive", func(t *testing.T) {
+		oa := openapiWithOptions(t, jsight, openapiOptions{extensions: true, omitExamples: true})
+
+		cat := oa["components"].(map[string]any)["schemas"].(map[string]any)["cat"].(map[string]any)
+		assert.JSONEq(t, `{
+			"id": {"type": "integer"},
+			"email": {"type": "string", "format": "email", "x-jsight-type": "email"},
+			"price": {"type": "number", "multipleOf": 0.01, "x-jsight-precision": 2},
+			"color": {"x-jsight-enum": "@color"},
+			"size": {"enum": ["S", "M"]}
+		}`, mustMarshal(t, cat["properties"]))
+
+		op := documentOperation(t, oa, "/cats/{id}", "get")
+		assert.JSONEq(t, `{"type": "number", "multipleOf": 0.1, "x-jsight-precision": 1}`,
+			mustMarshal(t, op["parameters"].([]any)[0].(map[string]any)["schema"]))
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		oa := openapiWithOptions(t, jsight, openapiOptions{omitExamples: true})
+		assert.NotContains(t, mustMarshal(t, oa), "x-jsight-")
+	})
+}
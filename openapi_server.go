@@ -0,0 +1,46 @@
+package main
+
+import (
+	"regexp"
+
+	"github.com/jsightapi/jsight-api-core/catalog"
+)
+
+// baseURLVariableRe matches the variables of the base URL, e.g. "{env}".
+var baseURLVariableRe = regexp.MustCompile(`\{([^{}/]+)\}`)
+
+// addServerVariables adds "variables" to the servers of the OpenAPI document
+// whose base URLs have variables. OpenAPI requires every variable of the URL to
+// be described.
+func addServerVariables(doc jsonObject, c *catalog.Catalog) {
+	// The servers are in the same order as in the catalog.
+	servers, _ := doc.get("servers").([]any)
+	i := 0
+
+	c.Servers.EachSafe(func(_ string, s *catalog.Server) {
+		defer func() { i++ }()
+
+		vars := serverVariables(s.BaseUrl)
+		if vars == nil || i >= len(servers) {
+			return
+		}
+		if so, ok := servers[i].(jsonObject); ok {
+			so.put("variables", vars)
+			servers[i] = so
+		}
+	})
+}
+
+// serverVariables builds OpenAPI Server Variable Objects for the variables of
+// the base URL. JSight 0.3 has no syntax for values of the variables, so the
+// default value of a variable is its name.
+func serverVariables(baseURL string) jsonObject {
+	var vars jsonObject
+	for _, m := range baseURLVariableRe.FindAllStringSubmatch(baseURL, -1) {
+		if vars.has(m[1]) {
+			continue
+		}
+		vars = append(vars, jsonMember{Key: m[1], Value: jsonObject{{Key: "default", Value: m[1]}}})
+	}
+	return vars
+}
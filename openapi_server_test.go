@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_addServerVariables(t *testing.T) {
+	t.Run("variables", func(t *testing.T) {
+		oa := openapiWithOptions(t, `JSIGHT 0.3
+
+SERVER @prod // Production.
+  BaseUrl "https://{env}.example.com:{port}/{env}/v{version}"
+
+SERVER @local
+  BaseUrl "https://localhost"
+
+GET /cats
+  200 any
+`, openapiOptions{validate: true})
+
+		assert.JSONEq(t, `[
+			{
+				"url": "https://{env}.example.com:{port}/{env}/v{version}",
+				"description": "Production.",
+				"variables": {
+					"env": {"default": "env"},
+					"port": {"default": "port"},
+					"version": {"default": "version"}
+				}
+			},
+			{
+				"url": "https://localhost"
+			}
+		]`, mustMarshal(t, oa["servers"]))
+	})
+
+	t.Run("no variables", func(t *testing.T) {
+		oa := openapiWithOptions(t, `JSIGHT 0.3
+
+SERVER @local
+  BaseUrl "https://localhost"
+
+GET /cats
+  200 any
+`, openapiOptions{})
+
+		assert.JSONEq(t, `[{"url": "https://localhost"}]`, mustMarshal(t, oa["servers"]))
+	})
+}
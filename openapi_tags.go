@@ -0,0 +1,79 @@
+package main
+
+import (
+	"github.com/jsightapi/jsight-api-core/catalog"
+)
+
+// addTags adds the tags of the catalog to the OpenAPI document. Operations
+// refer to tags by their titles, so titles are used as names of the tags.
+//
+// OpenAPI tags are flat, so if a tag has children, the hierarchy is kept in
+// the "x-tagGroups" extension: every top-level tag becomes a group of its own
+// and all its descendants. The group includes the tag itself only if it has
+// interactions.
+//
+// The excluded tags are left out.
+func addTags(doc *jsonObject, c *catalog.Catalog, excluded []string) {
+	tags := []any{}
+	// The excluded tags are skipped as if they were added already.
+	titles := append([]string(nil), excluded...)
+	nested := false
+
+	var add func(tt *catalog.Tags)
+	add = func(tt *catalog.Tags) {
+		tt.EachSafe(func(_ catalog.TagName, t *catalog.Tag) {
+			if !containsString(titles, t.Title) {
+				titles = append(titles, t.Title)
+
+				to := jsonObject{{Key: "name", Value: t.Title}}
+				if t.Description != nil && *t.Description != "" {
+					to = append(to, jsonMember{Key: "description", Value: *t.Description})
+				}
+				tags = append(tags, to)
+			}
+
+			nested = nested || t.Children.Len() > 0
+			add(t.Children)
+		})
+	}
+	add(c.Tags)
+
+	if len(tags) == 0 {
+		return
+	}
+	doc.put("tags", tags)
+
+	if !nested {
+		return
+	}
+
+	groups := []any{}
+	c.Tags.EachSafe(func(_ catalog.TagName, t *catalog.Tag) {
+		var gt []string
+		if len(t.InteractionGroups) != 0 && !containsString(excluded, t.Title) {
+			gt = append(gt, t.Title)
+		}
+		gt = appendDescendantTitles(gt, t.Children, excluded)
+
+		if len(gt) != 0 {
+			groups = append(groups, jsonObject{
+				{Key: "name", Value: t.Title},
+				{Key: "tags", Value: gt},
+			})
+		}
+	})
+
+	doc.put("x-tagGroups", groups)
+}
+
+// appendDescendantTitles appends titles of the tags and their children, depth
+// first, which are neither in the list nor excluded.
+func appendDescendantTitles(titles []string, tt *catalog.Tags, excluded []string) []string {
+	tt.EachSafe(func(_ catalog.TagName, t *catalog.Tag) {
+		if !containsString(titles, t.Title) && !containsString(excluded, t.Title) {
+			titles = append(titles, t.Title)
+		}
+		titles = appendDescendantTitles(titles, t.Children, excluded)
+	})
+	return titles
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsightapi/jsight-schema-core/fs"
+
+	"github.com/jsightapi/jsight-api-core/catalog"
+	"github.com/jsightapi/jsight-api-core/kit"
+)
+
+const testTagsAPI = `JSIGHT 0.3
+
+TAG @animals // Animals
+  Description
+    All the animals.
+
+TAG @cats // Cats
+  Description
+    Cats only.
+
+TAG @dogs
+
+GET /cats
+  Tags @cats
+  200 any
+
+GET /dogs
+  Tags @dogs
+  200 any
+
+GET /health
+  200 any
+`
+
+func Test_addTags(t *testing.T) {
+	openapiDoc := func(t *testing.T, jAPI kit.JApi) map[string]any {
+		b, err := openapiJSON(jAPI, openapiOptions{})
+		require.NoError(t, err)
+
+		var oa map[string]any
+		require.NoError(t, json.Unmarshal(b, &oa))
+		return oa
+	}
+
+	newJAPI := func(t *testing.T) kit.JApi {
+		jAPI, je := kit.NewJApiFromFile(fs.NewFile("root", []byte(testTagsAPI)))
+		require.Nil(t, je)
+		return jAPI
+	}
+
+	t.Run("flat", func(t *testing.T) {
+		oa := openapiDoc(t, newJAPI(t))
+
+		assert.JSONEq(t, `[
+			{"name": "Animals", "description": "All the animals."},
+			{"name": "Cats", "description": "Cats only."},
+			{"name": "@dogs"},
+			{"name": "/health"}
+		]`, mustMarshal(t, oa["tags"]))
+		assert.NotContains(t, oa, "x-tagGroups")
+
+		op := oa["paths"].(map[string]any)["/cats"].(map[string]any)["get"].(map[string]any)
+		assert.Equal(t, []any{"Cats"}, op["tags"])
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		// JSight 0.3 has no syntax for nested tags yet, so the hierarchy is
+		// built by hand: @cats and @dogs are the children of @animals.
+		jAPI := newJAPI(t)
+		c := jAPI.Catalog()
+		tags := c.Tags
+		c.Tags = &catalog.Tags{}
+
+		tags.EachSafe(func(k catalog.TagName, v *catalog.Tag) {
+			switch k {
+			case "@cats", "@dogs":
+				animals, ok := c.Tags.Get("@animals")
+				require.True(t, ok)
+				animals.Children.Set(k, v)
+			default:
+				c.Tags.Set(k, v)
+			}
+		})
+
+		oa := openapiDoc(t, jAPI)
+
+		assert.JSONEq(t, `[
+			{"name": "Animals", "description": "All the animals."},
+			{"name": "Cats", "description": "Cats only."},
+			{"name": "@dogs"},
+			{"name": "/health"}
+		]`, mustMarshal(t, oa["tags"]))
+
+		assert.JSONEq(t, `[
+			{"name": "Animals", "tags": ["Cats", "@dogs"]},
+			{"name": "/health", "tags": ["/health"]}
+		]`, mustMarshal(t, oa["x-tagGroups"]))
+	})
+}
@@ -0,0 +1,474 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// openapi30MetaSchema is the JSON Schema of OpenAPI 3.0 documents published by
+// the OpenAPI Initiative (https://spec.openapis.org/oas/3.0/schema/2021-09-28).
+//
+//go:embed schemas/openapi-3.0.json
+var openapi30MetaSchema []byte
+
+// openapiValidationError describes the place in the generated OpenAPI document
+// which violates the OpenAPI meta-schema. The document is generated by the
+// server, so it is a server error rather than an error in the JSight code.
+type openapiValidationError struct {
+	// Pointer a JSON Pointer (RFC 6901) to the invalid value.
+	Pointer string
+
+	// Message a human-readable description of the violation.
+	Message string
+}
+
+func (e *openapiValidationError) Error() string {
+	return fmt.Sprintf("the generated OpenAPI document is invalid at %q: %s", e.Pointer, e.Message)
+}
+
+var (
+	openapiMetaSchema     *metaSchema
+	openapiMetaSchemaErr  error
+	openapiMetaSchemaOnce sync.Once
+)
+
+// validateOpenAPIDocument validates the OpenAPI 3.0 document against the
+// meta-schema. The first violation found is returned as
+// *openapiValidationError.
+func validateOpenAPIDocument(b []byte) error {
+	openapiMetaSchemaOnce.Do(func() {
+		openapiMetaSchema, openapiMetaSchemaErr = newMetaSchema(openapi30MetaSchema)
+	})
+	if openapiMetaSchemaErr != nil {
+		return fmt.Errorf("the OpenAPI meta-schema: %w", openapiMetaSchemaErr)
+	}
+
+	doc, err := decodeJSONObject(b)
+	if err != nil {
+		return err
+	}
+
+	if e := openapiMetaSchema.validate(openapiMetaSchema.root, doc, ""); e != nil {
+		return e
+	}
+	return nil
+}
+
+// metaSchema validates JSON documents against the JSON Schema draft 4. Only
+// the keywords used by the OpenAPI meta-schema are supported. Formats aren't
+// checked, they are annotations only.
+type metaSchema struct {
+	root jsonObject
+
+	// patterns are compiled values of "pattern" and keys of
+	// "patternProperties".
+	patterns map[string]*regexp.Regexp
+}
+
+func newMetaSchema(b []byte) (*metaSchema, error) {
+	root, err := decodeJSONObject(b)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &metaSchema{
+		root:     root,
+		patterns: map[string]*regexp.Regexp{},
+	}
+	if err := s.compilePatterns(root); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *metaSchema) compilePatterns(v any) error {
+	switch vv := v.(type) {
+	case jsonObject:
+		for _, m := range vv {
+			if p, ok := m.Value.(string); ok && m.Key == "pattern" {
+				if err := s.compilePattern(p); err != nil {
+					return err
+				}
+			}
+			if pp, ok := m.Value.(jsonObject); ok && m.Key == "patternProperties" {
+				for _, p := range pp {
+					if err := s.compilePattern(p.Key); err != nil {
+						return err
+					}
+				}
+			}
+			if err := s.compilePatterns(m.Value); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, i := range vv {
+			if err := s.compilePatterns(i); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *metaSchema) compilePattern(p string) error {
+	re, err := regexp.Compile(p)
+	if err != nil {
+		return err
+	}
+	s.patterns[p] = re
+	return nil
+}
+
+// resolve returns the schema of the local reference, e.g.
+// "#/definitions/Info".
+func (s *metaSchema) resolve(ref string) (jsonObject, bool) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, false
+	}
+
+	o := s.root
+	for _, t := range strings.Split(strings.TrimPrefix(ref[1:], "/"), "/") {
+		if t == "" {
+			continue
+		}
+		if o = o.object(unescapeJSONPointer(t)); o == nil {
+			return nil, false
+		}
+	}
+	return o, true
+}
+
+func (s *metaSchema) validate(sv, v any, ptr string) *openapiValidationError {
+	so, ok := sv.(jsonObject)
+	if !ok {
+		return nil
+	}
+
+	// Other keywords next to "$ref" are ignored in the draft 4.
+	if ref, ok := so.get("$ref").(string); ok {
+		rs, ok := s.resolve(ref)
+		if !ok {
+			return &openapiValidationError{Pointer: ptr, Message: fmt.Sprintf("the meta-schema reference %q not found", ref)}
+		}
+		return s.validate(rs, v, ptr)
+	}
+
+	checks := []func(jsonObject, any, string) *openapiValidationError{
+		s.validateType,
+		s.validateEnum,
+		s.validateString,
+		s.validateNumber,
+		s.validateArray,
+		s.validateObject,
+		s.validateAllOf,
+		s.validateOneOf,
+		s.validateNot,
+	}
+	for _, c := range checks {
+		if e := c(so, v, ptr); e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+func (*metaSchema) validateType(so jsonObject, v any, ptr string) *openapiValidationError {
+	var tt []string
+	switch t := so.get("type").(type) {
+	case string:
+		tt = []string{t}
+	case []any:
+		for _, i := range t {
+			if s, ok := i.(string); ok {
+				tt = append(tt, s)
+			}
+		}
+	default:
+		return nil
+	}
+
+	for _, t := range tt {
+		if jsonValueIs(v, t) {
+			return nil
+		}
+	}
+	return &openapiValidationError{
+		Pointer: ptr,
+		Message: fmt.Sprintf("the value must be of the %q type", strings.Join(tt, `" or "`)),
+	}
+}
+
+func (*metaSchema) validateEnum(so jsonObject, v any, ptr string) *openapiValidationError {
+	ee, ok := so.get("enum").([]any)
+	if !ok {
+		return nil
+	}
+
+	for _, e := range ee {
+		if jsonValuesEqual(e, v) {
+			return nil
+		}
+	}
+
+	b, _ := json.Marshal(ee)
+	return &openapiValidationError{Pointer: ptr, Message: fmt.Sprintf("the value must be one of %s", b)}
+}
+
+func (s *metaSchema) validateString(so jsonObject, v any, ptr string) *openapiValidationError {
+	str, ok := v.(string)
+	if !ok {
+		return nil
+	}
+
+	if p, ok := so.get("pattern").(string); ok && !s.patterns[p].MatchString(str) {
+		return &openapiValidationError{Pointer: ptr, Message: fmt.Sprintf("the value must match the regular expression %q", p)}
+	}
+	return nil
+}
+
+func (*metaSchema) validateNumber(so jsonObject, v any, ptr string) *openapiValidationError {
+	f, ok := jsonFloat(v)
+	if !ok {
+		return nil
+	}
+
+	minimum := so.get("minimum")
+	m, ok := jsonFloat(minimum)
+	if !ok {
+		return nil
+	}
+
+	if exclusive, _ := so.get("exclusiveMinimum").(bool); exclusive {
+		if f <= m {
+			return &openapiValidationError{Pointer: ptr, Message: fmt.Sprintf("the value must be greater than %s", minimum)}
+		}
+		return nil
+	}
+	if f < m {
+		return &openapiValidationError{Pointer: ptr, Message: fmt.Sprintf("the value must be greater than or equal to %s", minimum)}
+	}
+	return nil
+}
+
+func (s *metaSchema) validateArray(so jsonObject, v any, ptr string) *openapiValidationError {
+	a, ok := v.([]any)
+	if !ok {
+		return nil
+	}
+
+	if n, ok := jsonInt(so.get("minItems")); ok && int64(len(a)) < n {
+		return &openapiValidationError{Pointer: ptr, Message: fmt.Sprintf("the array must have at least %d items", n)}
+	}
+
+	if unique, _ := so.get("uniqueItems").(bool); unique {
+		for i := range a {
+			for j := 0; j < i; j++ {
+				if jsonValuesEqual(a[i], a[j]) {
+					return &openapiValidationError{
+						Pointer: ptr + "/" + strconv.Itoa(i),
+						Message: fmt.Sprintf("the item is the same as the item %d", j),
+					}
+				}
+			}
+		}
+	}
+
+	if items, ok := so.get("items").(jsonObject); ok {
+		for i, item := range a {
+			if e := s.validate(items, item, ptr+"/"+strconv.Itoa(i)); e != nil {
+				return e
+			}
+		}
+	}
+	return nil
+}
+
+func (s *metaSchema) validateObject(so jsonObject, v any, ptr string) *openapiValidationError {
+	o, ok := v.(jsonObject)
+	if !ok {
+		return nil
+	}
+
+	required, _ := so.get("required").([]any)
+	for _, r := range required {
+		if k, ok := r.(string); ok && !o.has(k) {
+			return &openapiValidationError{Pointer: ptr, Message: fmt.Sprintf("the required property %q is missing", k)}
+		}
+	}
+
+	if n, ok := jsonInt(so.get("minProperties")); ok && int64(len(o)) < n {
+		return &openapiValidationError{Pointer: ptr, Message: fmt.Sprintf("the object must have at least %d properties", n)}
+	}
+	if n, ok := jsonInt(so.get("maxProperties")); ok && int64(len(o)) > n {
+		return &openapiValidationError{Pointer: ptr, Message: fmt.Sprintf("the object must have at most %d properties", n)}
+	}
+
+	properties := so.object("properties")
+	patternProperties := so.object("patternProperties")
+	additional := so.get("additionalProperties")
+
+	for _, m := range o {
+		mPtr := ptr + "/" + escapeJSONPointer(m.Key)
+		matched := false
+
+		if ps := properties.get(m.Key); ps != nil {
+			matched = true
+			if e := s.validate(ps, m.Value, mPtr); e != nil {
+				return e
+			}
+		}
+
+		for _, pp := range patternProperties {
+			if !s.patterns[pp.Key].MatchString(m.Key) {
+				continue
+			}
+			matched = true
+			if e := s.validate(pp.Value, m.Value, mPtr); e != nil {
+				return e
+			}
+		}
+
+		if matched {
+			continue
+		}
+		if allowed, ok := additional.(bool); ok && !allowed {
+			return &openapiValidationError{Pointer: mPtr, Message: "the property is not allowed"}
+		}
+		if e := s.validate(additional, m.Value, mPtr); e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+func (s *metaSchema) validateAllOf(so jsonObject, v any, ptr string) *openapiValidationError {
+	ss, _ := so.get("allOf").([]any)
+	for _, sub := range ss {
+		if e := s.validate(sub, v, ptr); e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+// validateOneOf reports the error of the schema which the value matches best,
+// if it matches none. The best one is the schema with the deepest error: most
+// likely the value is meant to be of this schema.
+func (s *metaSchema) validateOneOf(so jsonObject, v any, ptr string) *openapiValidationError {
+	ss, ok := so.get("oneOf").([]any)
+	if !ok {
+		return nil
+	}
+
+	var best *openapiValidationError
+	matched := 0
+	for _, sub := range ss {
+		e := s.validate(sub, v, ptr)
+		if e == nil {
+			matched++
+			continue
+		}
+		if best == nil || len(e.Pointer) > len(best.Pointer) {
+			best = e
+		}
+	}
+
+	switch {
+	case matched == 0 && best != nil:
+		return best
+	case matched > 1:
+		return &openapiValidationError{Pointer: ptr, Message: fmt.Sprintf("the value must match exactly one schema, but it matches %d", matched)}
+	}
+	return nil
+}
+
+func (s *metaSchema) validateNot(so jsonObject, v any, ptr string) *openapiValidationError {
+	not, ok := so.get("not").(jsonObject)
+	if !ok || s.validate(not, v, ptr) != nil {
+		return nil
+	}
+
+	msg := "the value must not match the schema"
+	if d, ok := not.get("description").(string); ok {
+		msg = d
+	} else if d, ok := so.get("description").(string); ok {
+		msg = d
+	}
+	return &openapiValidationError{Pointer: ptr, Message: msg}
+}
+
+// jsonValueIs checks that the decoded JSON value is of the JSON Schema type.
+func jsonValueIs(v any, t string) bool {
+	switch vv := v.(type) {
+	case jsonObject:
+		return t == "object"
+	case []any:
+		return t == "array"
+	case string:
+		return t == "string"
+	case bool:
+		return t == "boolean"
+	case nil:
+		return t == "null"
+	case json.Number:
+		if t == "number" {
+			return true
+		}
+		f, err := vv.Float64()
+		return t == "integer" && err == nil && f == math.Trunc(f)
+	}
+	return false
+}
+
+// jsonValuesEqual compares decoded JSON values. The order of object properties
+// doesn't matter, numbers are compared by their values.
+func jsonValuesEqual(a, b any) bool {
+	switch av := a.(type) {
+	case jsonObject:
+		bv, ok := b.(jsonObject)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for _, m := range av {
+			i := bv.index(m.Key)
+			if i == -1 || !jsonValuesEqual(m.Value, bv[i].Value) {
+				return false
+			}
+		}
+		return true
+
+	case []any:
+		bv, ok := b.([]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !jsonValuesEqual(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+
+	case json.Number:
+		bv, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		af, aErr := av.Float64()
+		bf, bErr := bv.Float64()
+		return aErr == nil && bErr == nil && af == bf
+	}
+
+	switch b.(type) {
+	case jsonObject, []any, json.Number:
+		return false
+	}
+	return a == b
+}
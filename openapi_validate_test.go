@@ -0,0 +1,125 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsightapi/jsight-schema-core/fs"
+
+	"github.com/jsightapi/jsight-api-core/kit"
+)
+
+func Test_validateOpenAPIDocument(t *testing.T) {
+	t.Run("positive", func(t *testing.T) {
+		t.Run("generated document", func(t *testing.T) {
+			jAPI, je := kit.NewJApiFromFile(fs.NewFile("root", []byte(`JSIGHT 0.3
+
+INFO
+  Title "Cats"
+
+SERVER @prod
+  BaseUrl "https://example.com"
+
+TAG @cats // Cats
+
+TYPE @cat
+{
+  "id": 1,
+  "name": "Tom", // {optional: true, maxLength: 10}
+  "color": "red" // {enum: ["red", "black"]}
+}
+
+GET /cats/{id}
+  Tags @cats
+  Query
+  {
+    "page": 1 // {min: 1}
+  }
+  200 @cat
+  404 empty
+
+POST /cats
+  Request
+    Headers
+    {
+      "X-Trace": "abc"
+    }
+    Body @cat
+  201 @cat // {nullable: true}
+
+URL /rpc
+  Protocol json-rpc-2.0
+
+  Method getCats
+    Params
+    {
+      "limit": 10
+    }
+    Result
+      [@cat]
+`)))
+			require.Nil(t, je)
+
+			for _, o := range []openapiOptions{
+				{validate: true},
+				{validate: true, omitExamples: true, inline: true, extensions: true, operationIDs: operationIDsMethodPath},
+			} {
+				_, err := openapiJSON(jAPI, o)
+				assert.NoError(t, err)
+			}
+		})
+
+		t.Run("extensions", func(t *testing.T) {
+			assert.NoError(t, validateOpenAPIDocument([]byte(`{
+				"openapi": "3.0.3",
+				"info": {"title": "", "version": "", "x-logo": {}},
+				"paths": {},
+				"x-tagGroups": []
+			}`)))
+		})
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		cc := map[string]openapiValidationError{
+			`{"openapi": "3.0.3", "info": {"title": "", "version": ""}}`: {
+				Pointer: "",
+				Message: `the required property "paths" is missing`,
+			},
+			`{"openapi": "3.1.0", "info": {"title": "", "version": ""}, "paths": {}}`: {
+				Pointer: "/openapi",
+				Message: `the value must match the regular expression "^3\\.0\\.\\d(-.+)?$"`,
+			},
+			`{"openapi": "3.0.3", "info": {"title": "", "version": ""}, "paths": {}, "tags": [{"name": "a"}, {"name": "a"}]}`: {
+				Pointer: "/tags/1",
+				Message: "the item is the same as the item 0",
+			},
+			`{"openapi": "3.0.3", "info": {"title": "", "version": ""}, "paths": {"/cats": {"get": {}}}}`: {
+				Pointer: "/paths/~1cats/get",
+				Message: `the required property "responses" is missing`,
+			},
+			`{"openapi": "3.0.3", "info": {"title": "", "version": ""}, "paths": {"/cats": {"get": {"responses": {"200": {"description": "", "content": {"application/json": {"schema": {"type": "object", "properties": {"id": {"type": "int"}}}}}}}}}}}`: {
+				Pointer: "/paths/~1cats/get/responses/200/content/application~1json/schema/properties/id/type",
+				Message: `the value must be one of ["array","boolean","integer","number","object","string"]`,
+			},
+			`{"openapi": "3.0.3", "info": {"title": "", "version": ""}, "paths": {"/cats": {"get": {"responses": {"200": {"description": "", "content": {"application/json": {"example": 1, "examples": {}}}}}}}}}`: {
+				Pointer: "/paths/~1cats/get/responses/200/content/application~1json",
+				Message: "Example and examples are mutually exclusive",
+			},
+			`{"openapi": "3.0.3", "info": {"title": "", "version": ""}, "paths": {"/cats": {"get": {"responses": {"200": {"description": ""}}, "nullable": true}}}}`: {
+				Pointer: "/paths/~1cats/get/nullable",
+				Message: "the property is not allowed",
+			},
+			`{"openapi": "3.0.3", "info": {"title": "", "version": ""}, "paths": {}, "components": {"schemas": {"cat": {"multipleOf": 0}}}}`: {
+				Pointer: "/components/schemas/cat/multipleOf",
+				Message: "the value must be greater than 0",
+			},
+		}
+
+		for doc, expected := range cc {
+			err := validateOpenAPIDocument([]byte(doc))
+			assert.Equal(t, &expected, err, doc)
+		}
+	})
+}
@@ -0,0 +1,127 @@
+package main
+
+// openapiMethods are the keys of the operations in the Path Item Object.
+var openapiMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// openapiWalker visits the Schema Objects and the Parameter and Header Objects
+// of the OpenAPI document. Only the places where the OpenAPI specification
+// allows these objects are looked into, so examples and extensions are never
+// taken for them, whatever keys they have.
+//
+// The callbacks return the new value of the object, nil callbacks are skipped.
+type openapiWalker struct {
+	schema    func(any) any
+	parameter func(any) any
+}
+
+func (w openapiWalker) document(doc jsonObject) {
+	for _, m := range doc.object("paths") {
+		w.pathItem(m.Value)
+	}
+
+	components := doc.object("components")
+	w.schemas(components.object("schemas"))
+	for _, m := range components.object("responses") {
+		w.response(m.Value)
+	}
+	pp := components.object("parameters")
+	for i := range pp {
+		pp[i].Value = w.parameterObject(pp[i].Value)
+	}
+	for _, m := range components.object("requestBodies") {
+		rb, _ := m.Value.(jsonObject)
+		w.mediaTypes(rb.object("content"))
+	}
+	hh := components.object("headers")
+	for i := range hh {
+		hh[i].Value = w.parameterObject(hh[i].Value)
+	}
+	for _, m := range components.object("callbacks") {
+		w.callback(m.Value)
+	}
+}
+
+func (w openapiWalker) pathItem(v any) {
+	item, _ := v.(jsonObject)
+	w.parameters(item.get("parameters"))
+
+	for _, method := range openapiMethods {
+		op := item.object(method)
+		if op == nil {
+			continue
+		}
+
+		w.parameters(op.get("parameters"))
+		if rb := op.object("requestBody"); rb != nil {
+			w.mediaTypes(rb.object("content"))
+		}
+		for _, m := range op.object("responses") {
+			w.response(m.Value)
+		}
+		for _, m := range op.object("callbacks") {
+			w.callback(m.Value)
+		}
+	}
+}
+
+func (w openapiWalker) callback(v any) {
+	c, _ := v.(jsonObject)
+	for _, m := range c {
+		w.pathItem(m.Value)
+	}
+}
+
+func (w openapiWalker) response(v any) {
+	r, _ := v.(jsonObject)
+	hh := r.object("headers")
+	for i := range hh {
+		hh[i].Value = w.parameterObject(hh[i].Value)
+	}
+	w.mediaTypes(r.object("content"))
+}
+
+func (w openapiWalker) parameters(v any) {
+	pp, _ := v.([]any)
+	for i := range pp {
+		pp[i] = w.parameterObject(pp[i])
+	}
+}
+
+// parameterObject visits the Parameter Object or the Header Object.
+func (w openapiWalker) parameterObject(v any) any {
+	p, ok := v.(jsonObject)
+	if !ok {
+		return v
+	}
+
+	if w.schema != nil && p.has("schema") {
+		p.set("schema", w.schema(p.get("schema")))
+	}
+	w.mediaTypes(p.object("content"))
+
+	if w.parameter != nil {
+		return w.parameter(p)
+	}
+	return p
+}
+
+func (w openapiWalker) mediaTypes(content jsonObject) {
+	if w.schema == nil {
+		return
+	}
+	for _, m := range content {
+		mt, _ := m.Value.(jsonObject)
+		if mt.has("schema") {
+			mt.set("schema", w.schema(mt.get("schema")))
+		}
+	}
+}
+
+func (w openapiWalker) schemas(ss jsonObject) {
+	if w.schema == nil {
+		return
+	}
+	for i := range ss {
+		ss[i].Value = w.schema(ss[i].Value)
+	}
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	schema "github.com/jsightapi/jsight-schema-core"
+	sc "github.com/jsightapi/jsight-schema-core/openapi"
+	"github.com/jsightapi/jsight-schema-core/panics"
+
+	"github.com/jsightapi/jsight-api-core/catalog"
+	"github.com/jsightapi/jsight-api-core/catalog/ser/openapi"
+	"github.com/jsightapi/jsight-api-core/kit"
+)
+
+const openRPCVersion = "1.2.6"
+
+// openRPC is an OpenRPC document describing JSON-RPC 2.0 methods of the JSight
+// API.
+type openRPC struct {
+	OpenRPC    string             `json:"openrpc"`
+	Info       *openapi.Info      `json:"info"`
+	Servers    []openRPCServer    `json:"servers,omitempty"`
+	Methods    []openRPCMethod    `json:"methods"`
+	Components *openRPCComponents `json:"components,omitempty"`
+}
+
+type openRPCServer struct {
+	Name        string `json:"name"`
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+type openRPCMethod struct {
+	Name           string                     `json:"name"`
+	Summary        string                     `json:"summary,omitempty"`
+	Description    string                     `json:"description,omitempty"`
+	Tags           []openRPCTag               `json:"tags,omitempty"`
+	Servers        []openRPCServer            `json:"servers,omitempty"`
+	ParamStructure string                     `json:"paramStructure,omitempty"`
+	Params         []openRPCContentDescriptor `json:"params"`
+	Result         *openRPCContentDescriptor  `json:"result,omitempty"`
+}
+
+type openRPCTag struct {
+	Name string `json:"name"`
+}
+
+type openRPCContentDescriptor struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+	Schema      any    `json:"schema"`
+}
+
+type openRPCComponents struct {
+	Schemas openapi.ComponentsSchemas `json:"schemas,omitempty"`
+}
+
+func openrpcJSON(jAPI kit.JApi) ([]byte, error) {
+	doc, err := newOpenRPC(jAPI.Catalog())
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func openrpcYAML(jAPI kit.JApi) ([]byte, error) {
+	js, err := openrpcJSON(jAPI)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonToYAML(js)
+}
+
+// newOpenRPC builds the OpenRPC document. The info and the schemas of user
+// types are taken from the OpenAPI document, so references to user types
+// ("#/components/schemas/...") are the same in both documents.
+//
+// OpenRPC has no paths, so the path of the methods is appended to the base URL
+// of the servers. When methods are served on different paths, every method
+// gets its own servers.
+func newOpenRPC(c *catalog.Catalog) (doc *openRPC, err error) {
+	defer func() {
+		err = panics.Handle(recover(), err)
+	}()
+
+	oa, err := openapi.NewOpenAPI(c)
+	if err != nil {
+		return nil, err
+	}
+
+	doc = &openRPC{
+		OpenRPC: openRPCVersion,
+		Info:    oa.Info,
+		Methods: []openRPCMethod{},
+	}
+	if oa.Components != nil && len(oa.Components.Schemas) != 0 {
+		doc.Components = &openRPCComponents{Schemas: oa.Components.Schemas}
+	}
+
+	gg := groupJSONRPCMethods(c)
+	if len(gg) == 1 {
+		doc.Servers = openRPCServers(c, gg[0].path)
+	}
+
+	for _, mm := range gg {
+		for _, m := range mm.methods {
+			om, err := newOpenRPCMethod(c, m)
+			if err != nil {
+				return nil, err
+			}
+			if len(gg) > 1 {
+				om.Servers = openRPCServers(c, mm.path)
+			}
+			doc.Methods = append(doc.Methods, om)
+		}
+	}
+
+	return doc, nil
+}
+
+func openRPCServers(c *catalog.Catalog, path string) []openRPCServer {
+	if c.Servers.Len() == 0 {
+		return []openRPCServer{{Name: path, URL: path}}
+	}
+
+	ss := make([]openRPCServer, 0, c.Servers.Len())
+	_ = c.Servers.Each(func(k string, v *catalog.Server) error {
+		ss = append(ss, openRPCServer{
+			Name:        strings.TrimPrefix(k, "@"),
+			URL:         strings.TrimSuffix(v.BaseUrl, "/") + path,
+			Description: v.Annotation,
+		})
+		return nil
+	})
+	return ss
+}
+
+func newOpenRPCMethod(c *catalog.Catalog, m *catalog.JsonRpcInteraction) (openRPCMethod, error) {
+	om := openRPCMethod{
+		Name:   m.Method,
+		Params: []openRPCContentDescriptor{},
+	}
+	if m.Annotation != nil {
+		om.Summary = *m.Annotation
+	}
+	if m.Description != nil {
+		om.Description = *m.Description
+	}
+	for _, t := range appendTagTitles(nil, c, m.Tags) {
+		om.Tags = append(om.Tags, openRPCTag{Name: t})
+	}
+
+	if m.Params != nil && m.Params.Schema != nil {
+		var err error
+		om.ParamStructure, om.Params, err = openRPCParams(m.Params.Schema)
+		if err != nil {
+			return openRPCMethod{}, fmt.Errorf("method %q: %w", m.Method, err)
+		}
+	}
+
+	if m.Result != nil && m.Result.Schema != nil {
+		om.Result = &openRPCContentDescriptor{
+			Name:   "result",
+			Schema: sc.NewJSchemaInfo(m.Result.Schema.JSchema).SchemaObject(),
+		}
+	}
+
+	return om, nil
+}
+
+// openRPCParams returns the parameter structure and the parameters of the
+// method. Properties of the params object become parameters passed by name,
+// items of the params array become parameters passed by position.
+func openRPCParams(s *catalog.ExchangeJSightSchema) (string, []openRPCContentDescriptor, error) {
+	ii := sc.Dereference(s.JSchema)
+	if len(ii) != 1 {
+		return "", nil, fmt.Errorf("the params must be either an object or an array")
+	}
+
+	switch i := ii[0].(type) {
+	case sc.ObjectInformer:
+		pp := i.PropertiesInfos()
+		dd := make([]openRPCContentDescriptor, 0, len(pp))
+		for _, p := range pp {
+			dd = append(dd, openRPCContentDescriptor{
+				Name:        p.Key(),
+				Description: p.Annotation(),
+				Required:    !p.Optional(),
+				Schema:      p.SchemaObject(),
+			})
+		}
+		return "by-name", dd, nil
+
+	case sc.SchemaInfo:
+		if i.Type() != sc.SchemaInfoTypeArray {
+			break
+		}
+		dd, err := positionalParams(i)
+		if err != nil {
+			return "", nil, err
+		}
+		return "by-position", dd, nil
+	}
+
+	return "", nil, fmt.Errorf("the params must be either an object or an array")
+}
+
+// positionalParams takes schemas of the array items from the array schema,
+// which lists them in the "anyOf" in the order of the items.
+func positionalParams(i sc.SchemaInfo) ([]openRPCContentDescriptor, error) {
+	items := i.Children()
+
+	b, err := json.Marshal(i.SchemaObject())
+	if err != nil {
+		return nil, err
+	}
+
+	var arr struct {
+		Items json.RawMessage `json:"items"`
+	}
+	if err := json.Unmarshal(b, &arr); err != nil {
+		return nil, err
+	}
+
+	ss := []json.RawMessage{arr.Items}
+	if len(items) > 1 {
+		var anyOf struct {
+			AnyOf []json.RawMessage `json:"anyOf"`
+		}
+		if err := json.Unmarshal(arr.Items, &anyOf); err != nil {
+			return nil, err
+		}
+		if len(anyOf.AnyOf) != len(items) {
+			return nil, fmt.Errorf("unexpected schema of the params array")
+		}
+		ss = anyOf.AnyOf
+	}
+
+	dd := make([]openRPCContentDescriptor, 0, len(items))
+	for k, item := range items {
+		dd = append(dd, openRPCContentDescriptor{
+			Name:        fmt.Sprintf("param%d", k+1),
+			Description: itemAnnotation(item),
+			Required:    true,
+			Schema:      ss[k],
+		})
+	}
+	return dd, nil
+}
+
+func itemAnnotation(n schema.ASTNode) string {
+	return strings.TrimSpace(n.Comment)
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsightapi/jsight-schema-core/fs"
+
+	"github.com/jsightapi/jsight-api-core/kit"
+)
+
+func Test_openrpcJSON(t *testing.T) {
+	t.Run("positive", func(t *testing.T) {
+		t.Run("by-name params", func(t *testing.T) {
+			doc := openrpcDocument(t, `JSIGHT 0.3
+
+INFO
+  Title "Cats"
+  Version 1.0
+
+SERVER @prod // Production.
+  BaseUrl "https://cats.com/"
+
+TYPE @cat
+{
+  "id": 1
+}
+
+URL /api/rpc
+  Protocol json-rpc-2.0
+
+  Method getCat // Get a cat.
+    Params
+    {
+      "id": 1, // The cat ID.
+      "full": true // {optional: true}
+    }
+    Result
+      @cat
+
+  Method ping
+`)
+
+			assert.Equal(t, "1.2.6", doc["openrpc"])
+			assert.JSONEq(t, `{"title": "Cats", "version": "1.0"}`, mustMarshal(t, doc["info"]))
+			assert.JSONEq(t, `[{
+				"name": "prod",
+				"url": "https://cats.com/api/rpc",
+				"description": "Production."
+			}]`, mustMarshal(t, doc["servers"]))
+			assert.JSONEq(t, `{"schemas": {"cat": {
+				"type": "object",
+				"properties": {"id": {"type": "integer", "example": 1}},
+				"required": ["id"],
+				"additionalProperties": false
+			}}}`, mustMarshal(t, doc["components"]))
+
+			assert.JSONEq(t, `[
+				{
+					"name": "getCat",
+					"summary": "Get a cat.",
+					"tags": [{"name": "/api"}],
+					"paramStructure": "by-name",
+					"params": [
+						{
+							"name": "id",
+							"description": "The cat ID.",
+							"required": true,
+							"schema": {"type": "integer", "example": 1, "description": "The cat ID."}
+						},
+						{
+							"name": "full",
+							"schema": {"type": "boolean", "example": true}
+						}
+					],
+					"result": {
+						"name": "result",
+						"schema": {"$ref": "#/components/schemas/cat"}
+					}
+				},
+				{
+					"name": "ping",
+					"tags": [{"name": "/api"}],
+					"params": []
+				}
+			]`, mustMarshal(t, doc["methods"]))
+		})
+
+		t.Run("by-position params", func(t *testing.T) {
+			doc := openrpcDocument(t, `JSIGHT 0.3
+
+URL /api/rpc
+  Protocol json-rpc-2.0
+
+  Method sum
+    Params
+    [
+      1, // The first term.
+      "2"
+    ]
+    Result
+      3
+`)
+
+			assert.JSONEq(t, `[{"name": "/api/rpc", "url": "/api/rpc"}]`, mustMarshal(t, doc["servers"]))
+			assert.JSONEq(t, `[{
+				"name": "sum",
+				"tags": [{"name": "/api"}],
+				"paramStructure": "by-position",
+				"params": [
+					{
+						"name": "param1",
+						"description": "The first term.",
+						"required": true,
+						"schema": {"type": "integer", "example": 1, "description": "The first term."}
+					},
+					{
+						"name": "param2",
+						"required": true,
+						"schema": {"type": "string", "example": "2"}
+					}
+				],
+				"result": {
+					"name": "result",
+					"schema": {"type": "integer", "example": 3}
+				}
+			}]`, mustMarshal(t, doc["methods"]))
+		})
+
+		t.Run("several paths", func(t *testing.T) {
+			doc := openrpcDocument(t, `JSIGHT 0.3
+
+SERVER @prod
+  BaseUrl "https://cats.com"
+
+URL /cats
+  Protocol json-rpc-2.0
+
+  Method getCat
+
+URL /dogs
+  Protocol json-rpc-2.0
+
+  Method getDog
+`)
+
+			assert.Nil(t, doc["servers"])
+
+			mm := doc["methods"].([]any)
+			require.Len(t, mm, 2)
+			assert.JSONEq(t, `[{"name": "prod", "url": "https://cats.com/cats"}]`,
+				mustMarshal(t, mm[0].(map[string]any)["servers"]))
+			assert.JSONEq(t, `[{"name": "prod", "url": "https://cats.com/dogs"}]`,
+				mustMarshal(t, mm[1].(map[string]any)["servers"]))
+		})
+
+		t.Run("no methods", func(t *testing.T) {
+			doc := openrpcDocument(t, `JSIGHT 0.3
+
+GET /cats
+  200 any
+`)
+
+			assert.Equal(t, []any{}, doc["methods"])
+			assert.Nil(t, doc["servers"])
+		})
+
+		t.Run("yaml", func(t *testing.T) {
+			jAPI, je := kit.NewJApiFromFile(fs.NewFile("root", []byte(testJSONRPCAPI)))
+			require.Nil(t, je)
+
+			b, err := openrpcYAML(jAPI)
+			require.NoError(t, err)
+			assert.Contains(t, string(b), "openrpc: 1.2.6\n")
+			assert.Contains(t, string(b), "name: getCat\n")
+		})
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		jAPI, je := kit.NewJApiFromFile(fs.NewFile("root", []byte(`JSIGHT 0.3
+
+URL /api/rpc
+  Protocol json-rpc-2.0
+
+  Method getCat
+    Params
+      "id"
+`)))
+		require.Nil(t, je)
+
+		_, err := openrpcJSON(jAPI)
+		assert.EqualError(t, err, `method "getCat": the params must be either an object or an array`)
+	})
+}
+
+func openrpcDocument(t *testing.T, jsight string) map[string]any {
+	jAPI, je := kit.NewJApiFromFile(fs.NewFile("root", []byte(jsight)))
+	require.Nil(t, je)
+
+	b, err := openrpcJSON(jAPI)
+	require.NoError(t, err)
+
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(b, &doc))
+	return doc
+}
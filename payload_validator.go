@@ -0,0 +1,634 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	schema "github.com/jsightapi/jsight-schema-core"
+	jbytes "github.com/jsightapi/jsight-schema-core/bytes"
+	"github.com/jsightapi/jsight-schema-core/notations/jschema"
+	"github.com/jsightapi/jsight-schema-core/notations/jschema/ischema/constraint"
+	"github.com/jsightapi/jsight-schema-core/notations/regex"
+	"github.com/jsightapi/jsight-schema-core/panics"
+
+	"github.com/jsightapi/jsight-api-core/catalog"
+	"github.com/jsightapi/jsight-api-core/notation"
+)
+
+// payloadError describes a place in a payload which violates the schema.
+type payloadError struct {
+	// Pointer a JSON Pointer (RFC 6901) to the invalid value.
+	Pointer string `json:"pointer"`
+
+	// Message a human-readable description of the violation.
+	Message string `json:"message"`
+}
+
+// validateExchangeSchema validates the payload against the exchange schema.
+// The payload is a JSON document for JSight schemas and a plain text for
+// regular expressions.
+func validateExchangeSchema(es catalog.ExchangeSchema, payload []byte, stringScalars bool) ([]payloadError, error) {
+	switch s := es.(type) {
+	case *catalog.ExchangeJSightSchema:
+		return validateJSON(s.JSchema, payload, stringScalars)
+
+	case *catalog.ExchangeRegexSchema:
+		return validateRegex(s.RSchema, payload)
+
+	case *catalog.ExchangePseudoSchema:
+		if s.Notation() == notation.SchemaNotationEmpty && len(bytes.TrimSpace(payload)) != 0 {
+			return []payloadError{{Message: "the payload must be empty"}}, nil
+		}
+		return nil, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported schema %T", es)
+	}
+}
+
+func validateRegex(s *regex.RSchema, payload []byte) ([]payloadError, error) {
+	pattern, err := s.Pattern()
+	if err != nil {
+		return nil, err
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	if !re.Match(payload) {
+		return []payloadError{{Message: "the value does not match the regular expression"}}, nil
+	}
+	return nil, nil
+}
+
+// validateJSON validates the JSON document against the JSight schema.
+//
+// If stringScalars is true, numbers, booleans and nulls are also accepted when
+// they are passed as strings. This is the case of headers, query parameters and
+// path variables, which are always strings on the wire.
+func validateJSON(s *jschema.JSchema, payload []byte, stringScalars bool) ([]payloadError, error) {
+	root, err := s.GetAST()
+	if err != nil {
+		return nil, err
+	}
+
+	if !json.Valid(payload) {
+		return []payloadError{{Message: "the payload is not a valid JSON"}}, nil
+	}
+
+	v := payloadValidator{
+		userTypes:     s.UserTypeCollection,
+		rules:         s.Rules,
+		stringScalars: stringScalars,
+	}
+	v.validate(root, bytes.TrimSpace(payload), "")
+	return v.errors, v.err
+}
+
+// payloadValidator walks through the schema AST and the payload at the same
+// time and collects all found violations.
+type payloadValidator struct {
+	userTypes     map[string]schema.Schema
+	rules         map[string]schema.Rule
+	stringScalars bool
+
+	errors []payloadError
+
+	// err an error in the schema itself, if any.
+	err error
+}
+
+func (v *payloadValidator) addError(pointer, format string, args ...any) {
+	v.errors = append(v.errors, payloadError{
+		Pointer: pointer,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// matches checks the value against the node without reporting violations.
+func (v *payloadValidator) matches(node schema.ASTNode, value []byte) bool {
+	sub := payloadValidator{
+		userTypes:     v.userTypes,
+		rules:         v.rules,
+		stringScalars: v.stringScalars,
+	}
+	sub.validate(node, value, "")
+	if sub.err != nil && v.err == nil {
+		v.err = sub.err
+	}
+	return len(sub.errors) == 0
+}
+
+func (v *payloadValidator) validate(node schema.ASTNode, value []byte, pointer string) {
+	if isJSONNull(value) && (ruleIsTrue(node, "nullable") || node.SchemaType == string(schema.SchemaTypeNull)) {
+		return
+	}
+
+	if or, ok := rule(node, "or"); ok {
+		v.validateOr(or, value, pointer)
+		return
+	}
+
+	switch schema.SchemaType(node.SchemaType) { //nolint:exhaustive // Other types are literals.
+	case schema.SchemaTypeAny, schema.SchemaTypeMixed:
+		return
+	case schema.SchemaTypeObject:
+		v.validateObject(node, value, pointer)
+	case schema.SchemaTypeArray:
+		v.validateArray(node, value, pointer)
+	default:
+		if strings.HasPrefix(node.SchemaType, "@") {
+			v.validateUserType(node.SchemaType, value, pointer)
+			return
+		}
+		v.validateLiteral(node, value, pointer)
+	}
+}
+
+func (v *payloadValidator) validateOr(or schema.RuleASTNode, value []byte, pointer string) {
+	for _, item := range or.Items {
+		if v.matches(orItemNode(item), value) {
+			return
+		}
+	}
+	v.addError(pointer, "the value does not match any of the allowed types")
+}
+
+// orItemNode converts an item of the "or" rule into an AST node.
+func orItemNode(item schema.RuleASTNode) schema.ASTNode {
+	if item.TokenType != schema.TokenTypeObject {
+		return typeNode(item.Value)
+	}
+
+	n := schema.ASTNode{Rules: item.Properties}
+	if t, ok := item.Properties.Get("type"); ok {
+		n.SchemaType = t.Value
+		n.TokenType = schema.SchemaType(t.Value).ToTokenType()
+	}
+	return n
+}
+
+// typeNode returns an AST node of the given type without any other rules.
+func typeNode(typ string) schema.ASTNode {
+	n := schema.ASTNode{
+		SchemaType: typ,
+		TokenType:  schema.SchemaType(typ).ToTokenType(),
+		Rules:      &schema.RuleASTNodes{},
+	}
+	if strings.HasPrefix(typ, "@") {
+		n.TokenType = schema.TokenTypeShortcut
+	}
+	return n
+}
+
+func (v *payloadValidator) validateUserType(name string, value []byte, pointer string) {
+	ut, ok := v.userTypes[name]
+	if !ok {
+		v.err = fmt.Errorf("the type %q not found", name)
+		return
+	}
+
+	if rs, ok := ut.(*regex.RSchema); ok {
+		var s string
+		if err := json.Unmarshal(value, &s); err != nil {
+			v.addError(pointer, "the value must be a string")
+			return
+		}
+
+		ee, err := validateRegex(rs, []byte(s))
+		if err != nil {
+			v.err = err
+			return
+		}
+		for _, e := range ee {
+			v.addError(pointer, e.Message)
+		}
+		return
+	}
+
+	node, err := ut.GetAST()
+	if err != nil {
+		v.err = err
+		return
+	}
+	v.validate(node, value, pointer)
+}
+
+func (v *payloadValidator) validateObject(node schema.ASTNode, value []byte, pointer string) {
+	var obj map[string]json.RawMessage
+	if !isJSONObject(value) || json.Unmarshal(value, &obj) != nil {
+		v.addError(pointer, "the value must be an object")
+		return
+	}
+
+	props, err := v.objectProperties(node)
+	if err != nil {
+		v.err = err
+		return
+	}
+
+	known := make(map[string]struct{}, len(props))
+	var shortcuts []schema.ASTNode
+	for _, p := range props {
+		if p.IsKeyShortcut {
+			shortcuts = append(shortcuts, p)
+			continue
+		}
+
+		known[p.Key] = struct{}{}
+
+		pv, ok := obj[p.Key]
+		if !ok {
+			if !ruleIsTrue(p, "optional") {
+				v.addError(pointer, "the required property %q is missing", p.Key)
+			}
+			continue
+		}
+		v.validate(p, pv, pointer+"/"+escapeJSONPointer(p.Key))
+	}
+
+	additional, hasAdditional := rule(node, "additionalProperties")
+
+	for _, k := range sortedKeys(obj) {
+		if _, ok := known[k]; ok {
+			continue
+		}
+
+		kp := pointer + "/" + escapeJSONPointer(k)
+
+		if p, ok := v.shortcutProperty(shortcuts, k); ok {
+			v.validate(p, obj[k], kp)
+			continue
+		}
+
+		switch {
+		case !hasAdditional || additional.Value == "false":
+			v.addError(kp, "the property is not allowed")
+		case additional.Value == "true" || additional.Value == string(schema.SchemaTypeAny):
+		default:
+			v.validate(typeNode(additional.Value), obj[k], kp)
+		}
+	}
+}
+
+// objectProperties returns properties of the object including the inherited
+// ones.
+func (v *payloadValidator) objectProperties(node schema.ASTNode) ([]schema.ASTNode, error) {
+	allOf, ok := rule(node, "allOf")
+	if !ok {
+		return node.Children, nil
+	}
+
+	names := []string{allOf.Value}
+	if allOf.TokenType == schema.TokenTypeArray {
+		names = names[:0]
+		for _, i := range allOf.Items {
+			names = append(names, i.Value)
+		}
+	}
+
+	var props []schema.ASTNode
+	for _, n := range names {
+		ut, ok := v.userTypes[n]
+		if !ok {
+			return nil, fmt.Errorf("the type %q not found", n)
+		}
+
+		an, err := ut.GetAST()
+		if err != nil {
+			return nil, err
+		}
+
+		pp, err := v.objectProperties(an)
+		if err != nil {
+			return nil, err
+		}
+		props = append(props, pp...)
+	}
+
+	// The properties of the object itself override the inherited ones.
+	for _, c := range node.Children {
+		for i := range props {
+			if props[i].Key == c.Key && !props[i].IsKeyShortcut && !c.IsKeyShortcut {
+				props = append(props[:i], props[i+1:]...)
+				break
+			}
+		}
+		props = append(props, c)
+	}
+	return props, nil
+}
+
+// shortcutProperty finds a property which key is a user type the given key
+// conforms to.
+func (v *payloadValidator) shortcutProperty(shortcuts []schema.ASTNode, key string) (schema.ASTNode, bool) {
+	if len(shortcuts) == 0 {
+		return schema.ASTNode{}, false
+	}
+
+	k, err := json.Marshal(key)
+	if err != nil {
+		return schema.ASTNode{}, false
+	}
+
+	for _, p := range shortcuts {
+		if v.matches(typeNode(p.Key), k) {
+			return p, true
+		}
+	}
+	return schema.ASTNode{}, false
+}
+
+func (v *payloadValidator) validateArray(node schema.ASTNode, value []byte, pointer string) {
+	var arr []json.RawMessage
+	if !isJSONArray(value) || json.Unmarshal(value, &arr) != nil {
+		v.addError(pointer, "the value must be an array")
+		return
+	}
+
+	n := uint(len(arr))
+	if r, ok := rule(node, "minItems"); ok && !passes(func() {
+		constraint.NewMinItems(jbytes.NewBytes(r.Value)).ValidateTheArray(n)
+	}) {
+		v.addError(pointer, `the array violates the rule "minItems": %s`, r.Value)
+	}
+	if r, ok := rule(node, "maxItems"); ok && !passes(func() {
+		constraint.NewMaxItems(jbytes.NewBytes(r.Value)).ValidateTheArray(n)
+	}) {
+		v.addError(pointer, `the array violates the rule "maxItems": %s`, r.Value)
+	}
+
+	switch len(node.Children) {
+	case 0:
+		// An empty array in the schema allows only empty arrays.
+		if len(arr) != 0 {
+			v.addError(pointer, "the array must be empty")
+		}
+
+	case 1:
+		for i, item := range arr {
+			v.validate(node.Children[0], item, pointer+"/"+strconv.Itoa(i))
+		}
+
+	default:
+		// Each item must conform to any of the items in the schema.
+	Items:
+		for i, item := range arr {
+			for _, c := range node.Children {
+				if v.matches(c, item) {
+					continue Items
+				}
+			}
+			v.addError(pointer+"/"+strconv.Itoa(i), "the value does not match any of the array items in the schema")
+		}
+	}
+}
+
+func (v *payloadValidator) validateLiteral(node schema.ASTNode, value []byte, pointer string) {
+	value = v.coerce(node, value)
+
+	if !literalTypeMatches(schema.SchemaType(node.SchemaType), value) {
+		v.addError(pointer, "the value must be of the %q type", node.SchemaType)
+		return
+	}
+
+	b := jbytes.NewBytes(value)
+
+	var formatCheck constraint.LiteralValidator
+	switch schema.SchemaType(node.SchemaType) { //nolint:exhaustive // Other types have no format.
+	case schema.SchemaTypeEmail:
+		formatCheck = constraint.NewEmail()
+	case schema.SchemaTypeURI:
+		formatCheck = constraint.NewUri()
+	case schema.SchemaTypeUUID:
+		formatCheck = constraint.NewUuid()
+	case schema.SchemaTypeDate:
+		formatCheck = constraint.NewDate()
+	case schema.SchemaTypeDateTime:
+		formatCheck = constraint.NewDateTime()
+	}
+	if formatCheck != nil {
+		if validateLiteral(formatCheck, b) != nil {
+			v.addError(pointer, "the value must be of the %q type", node.SchemaType)
+			return
+		}
+	}
+
+	if node.Rules == nil {
+		return
+	}
+
+	exclusiveMin := ruleIsTrue(node, "exclusiveMinimum")
+	exclusiveMax := ruleIsTrue(node, "exclusiveMaximum")
+
+	node.Rules.EachSafe(func(name string, r schema.RuleASTNode) {
+		var c constraint.LiteralValidator
+		switch name {
+		case "min":
+			m := constraint.NewMin(jbytes.NewBytes(r.Value))
+			m.SetExclusive(exclusiveMin)
+			c = m
+		case "max":
+			m := constraint.NewMax(jbytes.NewBytes(r.Value))
+			m.SetExclusive(exclusiveMax)
+			c = m
+		case "minLength":
+			c = constraint.NewMinLength(jbytes.NewBytes(r.Value))
+		case "maxLength":
+			c = constraint.NewMaxLength(jbytes.NewBytes(r.Value))
+		case "regex":
+			q, err := json.Marshal(r.Value)
+			if err != nil {
+				return
+			}
+			c = constraint.NewRegex(jbytes.NewBytes(q))
+		case "precision":
+			c = constraint.NewPrecision(jbytes.NewBytes(r.Value))
+		case "const":
+			c = constraint.NewConst(jbytes.NewBytes(r.Value), jbytes.NewBytes(node.Value))
+		case "enum":
+			e, err := v.enumConstraint(r)
+			if err != nil {
+				v.err = err
+				return
+			}
+			c = e
+		default:
+			return
+		}
+
+		if validateLiteral(c, b) != nil {
+			v.addError(pointer, "the value violates the rule %q: %s", name, ruleString(r))
+		}
+		return
+	})
+}
+
+// coerce converts a string to the literal of the node type if the validator
+// accepts scalars as strings.
+func (v *payloadValidator) coerce(node schema.ASTNode, value []byte) []byte {
+	if !v.stringScalars || !isJSONString(value) {
+		return value
+	}
+
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return value
+	}
+
+	switch schema.SchemaType(node.SchemaType) { //nolint:exhaustive // Other types are strings.
+	case schema.SchemaTypeInteger, schema.SchemaTypeFloat, schema.SchemaTypeDecimal,
+		schema.SchemaTypeBoolean, schema.SchemaTypeNull:
+		if json.Valid([]byte(s)) {
+			return []byte(s)
+		}
+	case schema.SchemaTypeEnum:
+		// Enums can contain values of any literal types.
+		if json.Valid([]byte(s)) && !isJSONString([]byte(s)) && !isJSONObject([]byte(s)) && !isJSONArray([]byte(s)) {
+			if ok := v.matches(node, []byte(s)); ok {
+				return []byte(s)
+			}
+		}
+	}
+	return value
+}
+
+func (v *payloadValidator) enumConstraint(r schema.RuleASTNode) (*constraint.Enum, error) {
+	e := constraint.NewEnum()
+
+	if r.TokenType != schema.TokenTypeArray {
+		rule, ok := v.rules[r.Value]
+		if !ok {
+			return nil, fmt.Errorf("the enum %q not found", r.Value)
+		}
+
+		an, err := rule.GetAST()
+		if err != nil {
+			return nil, err
+		}
+
+		// Values of the enum rule are already JSON literals.
+		for _, c := range an.Children {
+			e.Append(constraint.NewEnumItem(jbytes.NewBytes(c.Value), ""))
+		}
+		return e, nil
+	}
+
+	for _, i := range r.Items {
+		b := []byte(i.Value)
+		if i.TokenType == schema.TokenTypeString {
+			var err error
+			if b, err = json.Marshal(i.Value); err != nil {
+				return nil, err
+			}
+		}
+		e.Append(constraint.NewEnumItem(jbytes.NewBytes(b), ""))
+	}
+	return e, nil
+}
+
+// passes reports whether the check passes. Schema-core constraints report
+// violations via panics.
+func passes(fn func()) (ok bool) {
+	defer func() {
+		ok = panics.Handle(recover(), nil) == nil
+	}()
+
+	fn()
+	return true
+}
+
+// validateLiteral runs the constraint and returns the violation, if any.
+func validateLiteral(c constraint.LiteralValidator, value jbytes.Bytes) (err error) {
+	defer func() {
+		err = panics.Handle(recover(), err)
+	}()
+
+	c.Validate(value)
+	return nil
+}
+
+func literalTypeMatches(t schema.SchemaType, value []byte) bool {
+	switch t { //nolint:exhaustive // Other types are strings.
+	case schema.SchemaTypeInteger:
+		return isJSONNumber(value) && !bytes.ContainsAny(value, ".eE")
+	case schema.SchemaTypeFloat, schema.SchemaTypeDecimal:
+		return isJSONNumber(value)
+	case schema.SchemaTypeBoolean:
+		return string(value) == "true" || string(value) == "false"
+	case schema.SchemaTypeNull:
+		return isJSONNull(value)
+	case schema.SchemaTypeEnum:
+		return !isJSONObject(value) && !isJSONArray(value)
+	default:
+		return isJSONString(value)
+	}
+}
+
+func rule(node schema.ASTNode, name string) (schema.RuleASTNode, bool) {
+	if node.Rules == nil {
+		return schema.RuleASTNode{}, false
+	}
+	return node.Rules.Get(name)
+}
+
+func ruleIsTrue(node schema.ASTNode, name string) bool {
+	r, ok := rule(node, name)
+	return ok && r.Value == "true"
+}
+
+func ruleString(r schema.RuleASTNode) string {
+	switch r.TokenType { //nolint:exhaustive // Other types are printed as is.
+	case schema.TokenTypeString:
+		return strconv.Quote(r.Value)
+	case schema.TokenTypeArray:
+		ss := make([]string, 0, len(r.Items))
+		for _, i := range r.Items {
+			ss = append(ss, ruleString(i))
+		}
+		return "[" + strings.Join(ss, ", ") + "]"
+	default:
+		return r.Value
+	}
+}
+
+func isJSONNull(b []byte) bool {
+	return string(b) == "null"
+}
+
+func isJSONString(b []byte) bool {
+	return len(b) != 0 && b[0] == '"'
+}
+
+func isJSONObject(b []byte) bool {
+	return len(b) != 0 && b[0] == '{'
+}
+
+func isJSONArray(b []byte) bool {
+	return len(b) != 0 && b[0] == '['
+}
+
+func isJSONNumber(b []byte) bool {
+	return len(b) != 0 && (b[0] == '-' || (b[0] >= '0' && b[0] <= '9'))
+}
+
+// escapeJSONPointer escapes a reference token of the JSON Pointer.
+func escapeJSONPointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
+
+func sortedKeys(m map[string]json.RawMessage) []string {
+	kk := make([]string, 0, len(m))
+	for k := range m {
+		kk = append(kk, k)
+	}
+	sort.Strings(kk)
+	return kk
+}
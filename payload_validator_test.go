@@ -0,0 +1,194 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsightapi/jsight-schema-core/fs"
+
+	"github.com/jsightapi/jsight-api-core/catalog"
+	"github.com/jsightapi/jsight-api-core/kit"
+)
+
+const testValidatorAPI = `JSIGHT 0.3
+
+TYPE @owner
+{
+  "age": 20 // {min: 18}
+}
+
+TYPE @extra
+{
+  @key: 1
+}
+
+TYPE @key regex
+  /^[a-z]$/
+
+ENUM @color
+[
+  "red",
+  "green"
+]
+
+POST /cats/{id}
+  Query
+  {
+    "page": 1, // {min: 1}
+    "active": true,
+    "color": "red" // {enum: @color}
+  }
+  Request
+  {
+    "id": 1, // {min: 1}
+    "name": "Tom", // {maxLength: 5}
+    "email": "tom@cats.com", // {type: "email"}
+    "color": "red", // {enum: @color}
+    "size": "s", // {enum: ["s", "m"]}
+    "tags": [ // {minItems: 1}
+      "a"
+    ],
+    "owner": @owner, // {nullable: true}
+    "pet": 1, // {or: ["integer", "@owner"]}
+    "extra": @extra, // {optional: true}
+    "note": "x" // {optional: true}
+  }
+  200 regex
+    /^ok|fine$/
+`
+
+func Test_validateExchangeSchema(t *testing.T) {
+	jAPI, je := kit.NewJApiFromFile(fs.NewFile("root", []byte(testValidatorAPI)))
+	require.Nil(t, je)
+
+	i, err := findHTTPInteraction(jAPI.Catalog(), "http POST /cats/{id}")
+	require.NoError(t, err)
+
+	body := i.Request.HTTPRequestBody.Schema
+	query := i.Query.Schema
+	response := i.Responses[0].Body.Schema
+
+	t.Run("positive", func(t *testing.T) {
+		cc := map[string]struct {
+			schema        catalog.ExchangeSchema
+			payload       string
+			stringScalars bool
+		}{
+			"body": {
+				body,
+				`{"id": 1, "name": "Tom", "email": "tom@cats.com", "color": "red", "size": "m", "tags": ["a", "b"], "owner": null, "pet": {"age": 30}}`,
+				false,
+			},
+			"body with optional properties": {
+				body,
+				`{"id": 2, "name": "Tom", "email": "tom@cats.com", "color": "green", "size": "s", "tags": ["a"], "owner": {"age": 18}, "pet": 1, "extra": {"a": 1, "b": 2}, "note": ""}`,
+				false,
+			},
+			"query with typed values": {
+				query,
+				`{"page": 2, "active": false, "color": "red"}`,
+				true,
+			},
+			"query with string values": {
+				query,
+				`{"page": "2", "active": "false", "color": "red"}`,
+				true,
+			},
+			"regex": {
+				response,
+				"fine",
+				false,
+			},
+		}
+
+		for n, c := range cc {
+			t.Run(n, func(t *testing.T) {
+				ee, err := validateExchangeSchema(c.schema, []byte(c.payload), c.stringScalars)
+				require.NoError(t, err)
+				assert.Empty(t, ee)
+			})
+		}
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		cc := map[string]struct {
+			schema        catalog.ExchangeSchema
+			payload       string
+			stringScalars bool
+			expected      []payloadError
+		}{
+			"body": {
+				body,
+				`{"id": 1.5, "name": "Tomasz", "email": "tom", "color": "blue", "size": "x", "tags": [], "owner": {"age": 17}, "pet": "x", "extra": {"a": "1", "ab": 1}, "foo~/": 1}`,
+				false,
+				[]payloadError{
+					{Pointer: "/id", Message: `the value must be of the "integer" type`},
+					{Pointer: "/name", Message: `the value violates the rule "maxLength": 5`},
+					{Pointer: "/email", Message: `the value must be of the "email" type`},
+					{Pointer: "/color", Message: `the value violates the rule "enum": @color`},
+					{Pointer: "/size", Message: `the value violates the rule "enum": ["s", "m"]`},
+					{Pointer: "/tags", Message: `the array violates the rule "minItems": 1`},
+					{Pointer: "/owner/age", Message: `the value violates the rule "min": 18`},
+					{Pointer: "/pet", Message: "the value does not match any of the allowed types"},
+					{Pointer: "/extra/a", Message: `the value must be of the "integer" type`},
+					{Pointer: "/extra/ab", Message: "the property is not allowed"},
+					{Pointer: "/foo~0~1", Message: "the property is not allowed"},
+				},
+			},
+			"missing properties": {
+				body,
+				`{"id": 1, "name": "Tom", "email": "tom@cats.com", "color": "red", "size": "m", "tags": ["a"], "owner": null}`,
+				false,
+				[]payloadError{
+					{Pointer: "", Message: `the required property "pet" is missing`},
+				},
+			},
+			"not an object": {
+				body,
+				`[]`,
+				false,
+				[]payloadError{{Pointer: "", Message: "the value must be an object"}},
+			},
+			"invalid JSON": {
+				body,
+				`{`,
+				false,
+				[]payloadError{{Pointer: "", Message: "the payload is not a valid JSON"}},
+			},
+			"query": {
+				query,
+				`{"page": "x", "active": "1", "color": "blue"}`,
+				true,
+				[]payloadError{
+					{Pointer: "/page", Message: `the value must be of the "integer" type`},
+					{Pointer: "/active", Message: `the value must be of the "boolean" type`},
+					{Pointer: "/color", Message: `the value violates the rule "enum": @color`},
+				},
+			},
+			"strings are not allowed instead of scalars": {
+				query,
+				`{"page": "2", "active": true, "color": "red"}`,
+				false,
+				[]payloadError{
+					{Pointer: "/page", Message: `the value must be of the "integer" type`},
+				},
+			},
+			"regex": {
+				response,
+				"bad",
+				false,
+				[]payloadError{{Pointer: "", Message: "the value does not match the regular expression"}},
+			},
+		}
+
+		for n, c := range cc {
+			t.Run(n, func(t *testing.T) {
+				ee, err := validateExchangeSchema(c.schema, []byte(c.payload), c.stringScalars)
+				require.NoError(t, err)
+				assert.Equal(t, c.expected, ee)
+			})
+		}
+	})
+}
@@ -0,0 +1,380 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	jbytes "github.com/jsightapi/jsight-schema-core/bytes"
+	"github.com/jsightapi/jsight-schema-core/fs"
+
+	"github.com/jsightapi/jsight-api-core/jerr"
+	"github.com/jsightapi/jsight-api-core/kit"
+)
+
+// maxProjectSize limits the total size of all project files in bytes.
+const maxProjectSize = 32 << 20
+
+// maxMultipartOverhead limits the size of multipart headers and boundaries in
+// bytes, in addition to the project files.
+const maxMultipartOverhead = 1 << 20
+
+// defaultRootFileName is used when the project consists of a request body only.
+const defaultRootFileName = "root"
+
+// project is a set of JSight files received in a single request.
+// The root file is the entry point, other files can be added to it with the
+// INCLUDE directive.
+type project struct {
+	// root a path to the root file.
+	root string
+
+	// files contains all the project files.
+	files memFS
+}
+
+func newSingleFileProject(content []byte) project {
+	return project{
+		root:  defaultRootFileName,
+		files: memFS{defaultRootFileName: content},
+	}
+}
+
+// readProject reads a project from the request.
+// A multipart form, a zip archive or a tar archive is treated as a project of
+// many files, otherwise the whole request body is the root file.
+func readProject(w http.ResponseWriter, r *http.Request) (project, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		mediaType = ""
+	}
+
+	var p project
+	switch mediaType {
+	case "multipart/form-data":
+		p, err = readMultipartProject(w, r)
+	case "application/zip", "application/x-zip-compressed":
+		p, err = readArchiveProject(r, readZip)
+	case "application/x-tar", "application/tar":
+		p, err = readArchiveProject(r, readTar)
+	default:
+		var body []byte
+		body, err = io.ReadAll(io.LimitReader(r.Body, maxProjectSize+1))
+		if err == nil && len(body) > maxProjectSize {
+			err = errProjectTooLarge
+		}
+		p = newSingleFileProject(body)
+	}
+	if err != nil {
+		return project{}, err
+	}
+
+	return p, nil
+}
+
+var errProjectTooLarge = newRequestError(errorCodeTooLarge, "the project size exceeds %d bytes", maxProjectSize)
+
+// limitProjectBody limits the size of the request body with the project. It
+// must be called before form values are read, because they are parsed from the
+// whole multipart body.
+func limitProjectBody(w http.ResponseWriter, r *http.Request) {
+	if r.Body != nil {
+		r.Body = http.MaxBytesReader(w, r.Body, maxProjectSize+maxMultipartOverhead)
+	}
+}
+
+func readMultipartProject(w http.ResponseWriter, r *http.Request) (project, error) {
+	limitProjectBody(w, r)
+	if err := r.ParseMultipartForm(maxProjectSize); err != nil {
+		var me *http.MaxBytesError
+		if errors.As(err, &me) {
+			return project{}, errProjectTooLarge
+		}
+		return project{}, err
+	}
+
+	p := project{files: memFS{}}
+	size := 0
+	for name, headers := range r.MultipartForm.File {
+		// The form field name is the file path, because file names of the
+		// multipart parts lose their directories.
+		if len(headers) != 1 {
+			return project{}, newRequestError(errorCodeInvalidProject, "the file %q is specified more than once", name)
+		}
+
+		content, err := readMultipartFile(headers[0])
+		if err != nil {
+			return project{}, err
+		}
+
+		size += len(content)
+		if size > maxProjectSize {
+			return project{}, errProjectTooLarge
+		}
+
+		if err := p.addFile(name, content); err != nil {
+			return project{}, err
+		}
+	}
+
+	return p.withRoot(r.FormValue("root"))
+}
+
+func readMultipartFile(h *multipart.FileHeader) ([]byte, error) {
+	f, err := h.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	return io.ReadAll(f)
+}
+
+func readArchiveProject(r *http.Request, read func([]byte, *project) error) (project, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxProjectSize+1))
+	if err != nil {
+		return project{}, err
+	}
+	if len(body) > maxProjectSize {
+		return project{}, errProjectTooLarge
+	}
+
+	p := project{files: memFS{}}
+	if err := read(body, &p); err != nil {
+		return project{}, err
+	}
+
+	return p.withRoot(r.FormValue("root"))
+}
+
+func readZip(body []byte, p *project) error {
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		return err
+	}
+
+	size := uint64(0)
+	for _, f := range zr.File {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+
+		size += f.UncompressedSize64
+		if size > maxProjectSize {
+			return errProjectTooLarge
+		}
+
+		content, err := readZipFile(f)
+		if err != nil {
+			return err
+		}
+
+		if err := p.addFile(f.Name, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rc.Close()
+	}()
+
+	// The declared size could be forged, so we don't trust it.
+	content, err := io.ReadAll(io.LimitReader(rc, maxProjectSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(content) > maxProjectSize {
+		return nil, errProjectTooLarge
+	}
+	return content, nil
+}
+
+func readTar(body []byte, p *project) error {
+	tr := tar.NewReader(bytes.NewReader(body))
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if h.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+
+		if err := p.addFile(h.Name, content); err != nil {
+			return err
+		}
+	}
+}
+
+// addFile adds a file to the project. The name must be a relative
+// slash-separated path which doesn't leave the project directory.
+func (p *project) addFile(name string, content []byte) error {
+	if strings.ContainsRune(name, '\\') {
+		return newRequestError(errorCodeInvalidProject, "invalid file path %q", name)
+	}
+
+	clean := path.Clean(strings.TrimPrefix(name, "./"))
+	if path.IsAbs(clean) || clean == "." || clean == ".." || strings.HasPrefix(clean, "../") {
+		return newRequestError(errorCodeInvalidProject, "invalid file path %q", name)
+	}
+
+	if _, ok := p.files[clean]; ok {
+		return newRequestError(errorCodeInvalidProject, "the file %q is specified more than once", clean)
+	}
+
+	p.files[clean] = content
+	return nil
+}
+
+// withRoot sets the project root file. The root can be omitted if the project
+// contains only one file.
+func (p project) withRoot(root string) (project, error) {
+	if root == "" {
+		if len(p.files) != 1 {
+			return project{}, newRequestError(errorCodeParameterRequired, `you must specify the "root" parameter`)
+		}
+		for name := range p.files {
+			root = name
+		}
+	}
+
+	root = path.Clean(root)
+	if _, ok := p.files[root]; !ok {
+		return project{}, newRequestError(errorCodeFileNotFound, "the root file %q not found in the project", root)
+	}
+
+	p.root = root
+	return p, nil
+}
+
+// size returns the total size of all project files in bytes.
+func (p project) size() int {
+	return p.files.size()
+}
+
+// build builds the JSight API from the project.
+//
+// Projects without the INCLUDE directive are built in memory. JSight API Core
+// resolves the INCLUDE directive against the disk and has no way to use another
+// file system, so for other projects the root file and the files reachable from
+// it are written into a temporary directory which contains nothing else. Thus,
+// the core can't read files from anywhere else on the host.
+func (p project) build() (kit.JApi, *jerr.JApiError) {
+	jAPI, _, je := p.buildInDir()
+	return jAPI, je
+}
+
+// buildInDir builds the JSight API from the project the same way as build. It
+// also returns the temporary directory the project was built in, or an empty
+// string if it was built in memory. The directory is already removed, but the
+// catalog directives refer to the files in it, so their errors need
+// trimProjectDir.
+func (p project) buildInDir() (kit.JApi, string, *jerr.JApiError) {
+	rootContent := p.files[p.root]
+
+	files, err := includedFiles(p.files, p.root)
+	if err != nil {
+		return kit.JApi{}, "", jerr.NewJApiError(err.Error(), fs.NewFile(p.root, rootContent), 0)
+	}
+
+	if !usesInclude(p.root, rootContent) {
+		jAPI, je := kit.NewJApiFromFile(fs.NewFile(p.root, rootContent))
+		return jAPI, "", je
+	}
+
+	dir, err := os.MkdirTemp("", "jsight-project-")
+	if err != nil {
+		return kit.JApi{}, "", jerr.NewJApiError(err.Error(), fs.NewFile(p.root, rootContent), 0)
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	for name, content := range files {
+		if err := writeProjectFile(dir, name, content); err != nil {
+			return kit.JApi{}, "", jerr.NewJApiError(err.Error(), fs.NewFile(p.root, rootContent), 0)
+		}
+	}
+
+	jAPI, je := kit.NewJApiFromFile(fs.NewFile(projectFilePath(dir, p.root), rootContent))
+	if je != nil {
+		return jAPI, dir, trimProjectDir(je, dir, files)
+	}
+	return jAPI, dir, nil
+}
+
+func writeProjectFile(dir, name string, content []byte) error {
+	p := projectFilePath(dir, name)
+	if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(p, content, 0o600)
+}
+
+func projectFilePath(dir, name string) string {
+	return filepath.Join(dir, filepath.FromSlash(name))
+}
+
+// trimProjectDir removes the temporary project directory from file paths
+// mentioned in the error. The include trace is kept, with project paths.
+func trimProjectDir(je *jerr.JApiError, dir string, files memFS) *jerr.JApiError {
+	if dir == "" {
+		return je
+	}
+
+	prefix := dir + string(filepath.Separator)
+	trim := func(p string) string {
+		return filepath.ToSlash(strings.TrimPrefix(p, prefix))
+	}
+
+	msg := strings.ReplaceAll(je.Msg, prefix, "")
+
+	if je.File == nil {
+		return &jerr.JApiError{Msg: msg, Location: je.Location}
+	}
+
+	r := jerr.NewJApiError(msg, fs.NewFile(trim(je.File.Name()), je.File.Content()), je.Index)
+	for _, f := range includeTrace(je) {
+		name := trim(f.File)
+		content := files[name]
+		r.OccurredInFile(fs.NewFile(name, content), lineIndex(content, f.Line))
+	}
+	return r
+}
+
+// lineIndex returns the index of the beginning of the line with the given
+// number. Lines are numbered from 1.
+func lineIndex(content []byte, line int) jbytes.Index {
+	i := 0
+	for n := 1; n < line && i < len(content); n++ {
+		i = nextLine(content, i)
+	}
+	return jbytes.Index(i)
+}
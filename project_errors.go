@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/jsightapi/jsight-api-core/directive"
+	"github.com/jsightapi/jsight-api-core/jerr"
+)
+
+// maxProjectErrors limits the number of errors collected from one project.
+// Every error costs one more build of the project.
+const maxProjectErrors = 20
+
+// japiErrors is a list of independent errors found in one project. The first
+// one is the error the project build returns.
+type japiErrors []*jerr.JApiError
+
+var _ error = japiErrors{}
+
+func (ee japiErrors) Error() string {
+	return ee[0].Error()
+}
+
+func (ee japiErrors) Unwrap() error {
+	return ee[0]
+}
+
+// topLevelDirectives can start a directive at the beginning of a line. The
+// rest of the directives are nested in them.
+var topLevelDirectives = map[string]struct{}{
+	directive.Jsight.String():  {},
+	directive.Info.String():    {},
+	directive.Server.String():  {},
+	directive.URL.String():     {},
+	directive.Get.String():     {},
+	directive.Post.String():    {},
+	directive.Put.String():     {},
+	directive.Patch.String():   {},
+	directive.Delete.String():  {},
+	directive.Type.String():    {},
+	directive.Enum.String():    {},
+	directive.Macro.String():   {},
+	directive.Paste.String():   {},
+	directive.Include.String(): {},
+	directive.TAG.String():     {},
+}
+
+// collectErrors finds the errors of the project following the first one.
+//
+// JSight API Core stops at the first error. So the top-level directive where
+// the error occurred is blanked out, and the project is built again, until
+// the project is valid, there is nothing to blank out, or there are too many
+// errors. Blanking keeps lines and indexes of the rest of the code.
+//
+// Errors caused by blanking, e.g. a reference to the blanked user type, aren't
+// reported.
+func (p project) collectErrors(first *jerr.JApiError) japiErrors {
+	ee := japiErrors{first}
+
+	files := make(memFS, len(p.files))
+	for name, content := range p.files {
+		files[name] = content
+	}
+
+	var blankedNames []string
+	je := first
+	for i := 0; i < maxProjectErrors; i++ {
+		name, ok := blankDirective(files, je)
+		if !ok {
+			break
+		}
+		if name != "" {
+			blankedNames = append(blankedNames, name)
+		}
+
+		_, je = project{root: p.root, files: files}.build()
+		if je == nil {
+			break
+		}
+
+		if !causedByBlanking(je, blankedNames) {
+			ee = append(ee, je)
+		}
+	}
+
+	return ee
+}
+
+// blankDirective replaces the top-level directive where the error occurred
+// with spaces. It returns the name of the directive (e.g. the user type name)
+// if any.
+func blankDirective(files memFS, je *jerr.JApiError) (string, bool) {
+	if je.File == nil {
+		return "", false
+	}
+
+	content, ok := files[je.File.Name()]
+	if !ok {
+		return "", false
+	}
+
+	begin, end, ok := topLevelDirectiveBounds(content, je.Index.Int())
+	if !ok {
+		return "", false
+	}
+
+	header := strings.Fields(string(lineAt(content, begin)))
+	if header[0] == directive.Jsight.String() {
+		// Nothing can be built without the JSIGHT directive.
+		return "", false
+	}
+
+	blanked := make([]byte, len(content))
+	copy(blanked, content)
+	changed := false
+	for i := begin; i < end; i++ {
+		switch blanked[i] {
+		case '\n', '\r', ' ':
+		default:
+			blanked[i] = ' '
+			changed = true
+		}
+	}
+	if !changed {
+		return "", false
+	}
+	files[je.File.Name()] = blanked
+
+	if len(header) > 1 && strings.HasPrefix(header[1], "@") {
+		return header[1], true
+	}
+	return "", true
+}
+
+// topLevelDirectiveBounds returns the bounds of the top-level directive which
+// contains the byte with the given index. The directive lasts until the next
+// top-level directive.
+func topLevelDirectiveBounds(content []byte, index int) (begin, end int, ok bool) {
+	begin = -1
+	end = len(content)
+	for i := 0; i < len(content); i = nextLine(content, i) {
+		if !startsTopLevelDirective(lineAt(content, i)) {
+			continue
+		}
+		if i > index {
+			end = i
+			break
+		}
+		begin = i
+	}
+	return begin, end, begin != -1
+}
+
+// lineAt returns the line which starts at the given index, without the line
+// break.
+func lineAt(content []byte, i int) []byte {
+	line := content[i:]
+	if n := bytes.IndexByte(line, '\n'); n != -1 {
+		line = line[:n]
+	}
+	return bytes.TrimSuffix(line, []byte("\r"))
+}
+
+// nextLine returns the index of the beginning of the next line.
+func nextLine(content []byte, i int) int {
+	if n := bytes.IndexByte(content[i:], '\n'); n != -1 {
+		return i + n + 1
+	}
+	return len(content)
+}
+
+func startsTopLevelDirective(line []byte) bool {
+	ff := strings.Fields(string(line))
+	if len(ff) == 0 || !strings.HasPrefix(string(line), ff[0]) {
+		return false
+	}
+	_, ok := topLevelDirectives[ff[0]]
+	return ok
+}
+
+// causedByBlanking reports whether the error refers to one of the blanked
+// directives by its name.
+func causedByBlanking(je *jerr.JApiError, names []string) bool {
+	for _, n := range names {
+		if strings.Contains(je.Msg, `"`+n+`"`) || strings.Contains(je.Msg, "("+n+")") {
+			return true
+		}
+	}
+	return false
+}
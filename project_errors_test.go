@@ -0,0 +1,137 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_project_collectErrors(t *testing.T) {
+	collect := func(t *testing.T, p project) []string {
+		_, je := p.build()
+		require.NotNil(t, je)
+
+		var ss []string
+		for _, e := range p.collectErrors(je) {
+			ss = append(ss, e.File.Name()+":"+e.Line.String()+": "+e.Error())
+		}
+		return ss
+	}
+
+	t.Run("positive", func(t *testing.T) {
+		t.Run("independent errors", func(t *testing.T) {
+			p := newSingleFileProject([]byte(`JSIGHT 0.3
+
+TYPE @cat
+{
+  "id": 1 // {min: "a"}
+}
+
+TYPE @dog
+{
+  "name": @unknown
+}
+
+POST /birds
+  Request
+  {
+    "a": 1,
+  }
+`))
+
+			assert.Equal(t, []string{
+				`root:17: Invalid character "}" — string literal expected (starting with the quotation mark ` + "`\"`)",
+				`root:5: Incorrect number value "\"a\""`,
+				`root:10: Type "@unknown" not found`,
+			}, collect(t, p))
+		})
+
+		t.Run("errors caused by blanking are skipped", func(t *testing.T) {
+			p := newSingleFileProject([]byte(`JSIGHT 0.3
+
+TYPE @cat
+{
+  "id": 1 // {min: "a"}
+}
+
+GET /cats
+  200 @cat
+
+GET /dogs
+  200 [@cat]
+`))
+
+			assert.Equal(t, []string{
+				`root:5: Incorrect number value "\"a\""`,
+			}, collect(t, p))
+		})
+
+		t.Run("errors in included files", func(t *testing.T) {
+			p := project{
+				root: "main.jst",
+				files: memFS{
+					"main.jst": []byte(`JSIGHT 0.3
+
+INCLUDE types/cat.jst
+
+GET /dogs
+  200 @dog
+`),
+					"types/cat.jst": []byte(`TYPE @cat
+{
+  "id": 1 // {min: "a"}
+}
+`),
+				},
+			}
+
+			assert.Equal(t, []string{
+				"types/cat.jst:3: Incorrect number value \"\\\"a\\\"\"\ntypes/cat.jst:3\nmain.jst:3",
+				`main.jst:6: Type "@dog" not found`,
+			}, collect(t, p))
+		})
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		t.Run("error in the JSIGHT directive", func(t *testing.T) {
+			p := newSingleFileProject([]byte("JSIGHT 0.1\n\nGET /cats\n  200 @cat\n"))
+
+			assert.Equal(t, []string{
+				"root:1: The specified JSight version is not supported",
+			}, collect(t, p))
+		})
+
+		t.Run("error before directives", func(t *testing.T) {
+			p := newSingleFileProject([]byte("invalid"))
+
+			assert.Equal(t, []string{
+				"root:1: invalid character 'i' at the directive beginning",
+			}, collect(t, p))
+		})
+	})
+}
+
+func Test_topLevelDirectiveBounds(t *testing.T) {
+	content := []byte("JSIGHT 0.3\n\nURL /cats\n  GET\n    200 any\n\nTYPE @cat\n{\n  \"id\": 1\n}\n")
+
+	t.Run("positive", func(t *testing.T) {
+		cc := map[int][2]int{
+			0:  {0, 12},
+			14: {12, 41},
+			30: {12, 41},
+			50: {41, len(content)},
+		}
+
+		for index, expected := range cc {
+			begin, end, ok := topLevelDirectiveBounds(content, index)
+			require.True(t, ok)
+			assert.Equal(t, expected, [2]int{begin, end}, index)
+		}
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		_, _, ok := topLevelDirectiveBounds([]byte("  \n{}\n"), 4)
+		assert.False(t, ok)
+	})
+}
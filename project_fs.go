@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+
+	jfs "github.com/jsightapi/jsight-schema-core/fs"
+
+	"github.com/jsightapi/jsight-api-core/directive"
+	"github.com/jsightapi/jsight-api-core/scanner"
+)
+
+// memFS is a read-only in-memory file system. Keys are slash-separated paths
+// of files, directories are not stored.
+type memFS map[string][]byte
+
+var (
+	_ fs.FS         = memFS{}
+	_ fs.ReadFileFS = memFS{}
+)
+
+func (m memFS) Open(name string) (fs.File, error) {
+	c, ok := m.lookup(name)
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &memFile{
+		Reader: bytes.NewReader(c),
+		info:   memFileInfo{name: path.Base(name), size: int64(len(c))},
+	}, nil
+}
+
+func (m memFS) ReadFile(name string) ([]byte, error) {
+	c, ok := m.lookup(name)
+	if !ok {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+	return append([]byte(nil), c...), nil
+}
+
+func (m memFS) lookup(name string) ([]byte, bool) {
+	if !fs.ValidPath(name) {
+		return nil, false
+	}
+	c, ok := m[name]
+	return c, ok
+}
+
+// size returns the total size of all files in bytes.
+func (m memFS) size() int {
+	s := 0
+	for _, c := range m {
+		s += len(c)
+	}
+	return s
+}
+
+type memFile struct {
+	*bytes.Reader
+	info memFileInfo
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) {
+	return f.info, nil
+}
+
+func (*memFile) Close() error {
+	return nil
+}
+
+type memFileInfo struct {
+	name string
+	size int64
+}
+
+func (i memFileInfo) Name() string     { return i.name }
+func (i memFileInfo) Size() int64      { return i.size }
+func (memFileInfo) Mode() fs.FileMode  { return 0o444 }
+func (memFileInfo) ModTime() time.Time { return time.Time{} }
+func (memFileInfo) IsDir() bool        { return false }
+func (memFileInfo) Sys() any           { return nil }
+
+// includedFiles returns the root file and all the files which can be reached
+// from it through the INCLUDE directive.
+//
+// Only paths which stay inside the directory of the including file are
+// followed. Invalid or missing paths are skipped here, JSight API Core reports
+// them with proper positions later.
+func includedFiles(fsys fs.FS, root string) (memFS, error) {
+	content, err := fs.ReadFile(fsys, root)
+	if err != nil {
+		return nil, err
+	}
+
+	files := memFS{root: content}
+	queue := []string{root}
+
+	for len(queue) != 0 {
+		name := queue[0]
+		queue = queue[1:]
+
+		for _, inc := range includePaths(name, files[name]) {
+			if _, ok := files[inc]; ok {
+				continue
+			}
+
+			c, err := fs.ReadFile(fsys, inc)
+			if err != nil {
+				continue
+			}
+
+			files[inc] = c
+			queue = append(queue, inc)
+		}
+	}
+
+	return files, nil
+}
+
+// usesInclude reports whether the file has the INCLUDE directive. If the file
+// can't be scanned to the end, the core stops at the same place, so directives
+// after it don't matter.
+func usesInclude(name string, content []byte) bool {
+	s := scanner.NewJApiScanner(jfs.NewFile(name, content))
+	for {
+		lex, je := s.Next()
+		if je != nil || lex == nil {
+			return false
+		}
+		if lex.Type() == scanner.Keyword && lex.Value().String() == directive.Include.String() {
+			return true
+		}
+	}
+}
+
+// includePaths returns paths of files included into the given file. Paths are
+// resolved relative to the directory of the file.
+func includePaths(name string, content []byte) []string {
+	var pp []string
+
+	s := scanner.NewJApiScanner(jfs.NewFile(name, content))
+	for {
+		lex, je := s.Next()
+		if je != nil || lex == nil {
+			return pp
+		}
+
+		if lex.Type() != scanner.Keyword || lex.Value().String() != directive.Include.String() {
+			continue
+		}
+
+		param, je := s.Next()
+		if je != nil || param == nil {
+			return pp
+		}
+
+		if param.Type() != scanner.Parameter {
+			continue
+		}
+
+		// Like JSight API Core, we don't allow to go up the directory tree.
+		inc := param.Value().Unquote().String()
+		if fs.ValidPath(inc) && !strings.ContainsRune(inc, '\\') {
+			pp = append(pp, path.Join(path.Dir(name), inc))
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"io"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_memFS(t *testing.T) {
+	m := memFS{
+		"main.jst":      []byte("JSIGHT 0.3"),
+		"types/cat.jst": []byte("TYPE @cat"),
+	}
+
+	t.Run("positive", func(t *testing.T) {
+		t.Run("Open", func(t *testing.T) {
+			f, err := m.Open("types/cat.jst")
+			require.NoError(t, err)
+
+			info, err := f.Stat()
+			require.NoError(t, err)
+			assert.Equal(t, "cat.jst", info.Name())
+			assert.Equal(t, int64(9), info.Size())
+
+			c, err := io.ReadAll(f)
+			require.NoError(t, err)
+			assert.Equal(t, "TYPE @cat", string(c))
+		})
+
+		t.Run("ReadFile", func(t *testing.T) {
+			c, err := fs.ReadFile(m, "main.jst")
+			require.NoError(t, err)
+			assert.Equal(t, "JSIGHT 0.3", string(c))
+		})
+
+		t.Run("size", func(t *testing.T) {
+			assert.Equal(t, 19, m.size())
+		})
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		for _, name := range []string{"foo.jst", "types", "../main.jst", "/main.jst", "./main.jst", "types/../main.jst"} {
+			t.Run(name, func(t *testing.T) {
+				_, err := m.Open(name)
+				assert.ErrorIs(t, err, fs.ErrNotExist)
+
+				_, err = m.ReadFile(name)
+				assert.ErrorIs(t, err, fs.ErrNotExist)
+			})
+		}
+	})
+}
+
+func Test_includedFiles(t *testing.T) {
+	t.Run("positive", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"api/main.jst": {Data: []byte(`JSIGHT 0.3
+INCLUDE types/cat.jst
+INCLUDE missing.jst
+INCLUDE ../secret.jst
+INCLUDE types/../../secret.jst
+`)},
+			"api/types/cat.jst": {Data: []byte("INCLUDE dog.jst\nTYPE @cat {}")},
+			"api/types/dog.jst": {Data: []byte("INCLUDE cat.jst\nTYPE @dog {}")},
+			"api/unused.jst":    {Data: []byte("TYPE @unused {}")},
+			"secret.jst":        {Data: []byte("secret")},
+		}
+
+		actual, err := includedFiles(fsys, "api/main.jst")
+		require.NoError(t, err)
+
+		assert.Equal(t, memFS{
+			"api/main.jst":      fsys["api/main.jst"].Data,
+			"api/types/cat.jst": fsys["api/types/cat.jst"].Data,
+			"api/types/dog.jst": fsys["api/types/dog.jst"].Data,
+		}, actual)
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		_, err := includedFiles(memFS{}, "main.jst")
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+	})
+}
@@ -0,0 +1,408 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testProjectRoot = `JSIGHT 0.3
+
+INCLUDE types/cat.jst
+
+GET /cats/{id}
+  200 @cat
+`
+	testProjectCat = `TYPE @cat
+{
+  "id": 1
+}
+`
+)
+
+func Test_readProject(t *testing.T) {
+	t.Run("positive", func(t *testing.T) {
+		cc := map[string]struct {
+			request  func(*testing.T) *http.Request
+			expected project
+		}{
+			"plain body": {
+				func(t *testing.T) *http.Request {
+					return newProjectRequest(t, "text/plain", []byte("JSIGHT 0.3"))
+				},
+				project{
+					root:  "root",
+					files: memFS{"root": []byte("JSIGHT 0.3")},
+				},
+			},
+
+			"multipart": {
+				func(t *testing.T) *http.Request {
+					return newMultipartProjectRequest(t, "main.jst", map[string]string{
+						"main.jst":      testProjectRoot,
+						"types/cat.jst": testProjectCat,
+					})
+				},
+				project{
+					root: "main.jst",
+					files: memFS{
+						"main.jst":      []byte(testProjectRoot),
+						"types/cat.jst": []byte(testProjectCat),
+					},
+				},
+			},
+
+			"multipart, single file without root": {
+				func(t *testing.T) *http.Request {
+					return newMultipartProjectRequest(t, "", map[string]string{
+						"main.jst": "JSIGHT 0.3",
+					})
+				},
+				project{
+					root:  "main.jst",
+					files: memFS{"main.jst": []byte("JSIGHT 0.3")},
+				},
+			},
+
+			"zip": {
+				func(t *testing.T) *http.Request {
+					return newProjectRequest(t, "application/zip", newZip(t, map[string]string{
+						"main.jst":        testProjectRoot,
+						"./types/cat.jst": testProjectCat,
+					}), "main.jst")
+				},
+				project{
+					root: "main.jst",
+					files: memFS{
+						"main.jst":      []byte(testProjectRoot),
+						"types/cat.jst": []byte(testProjectCat),
+					},
+				},
+			},
+
+			"tar": {
+				func(t *testing.T) *http.Request {
+					return newProjectRequest(t, "application/x-tar", newTar(t, map[string]string{
+						"main.jst":      testProjectRoot,
+						"types/cat.jst": testProjectCat,
+					}), "main.jst")
+				},
+				project{
+					root: "main.jst",
+					files: memFS{
+						"main.jst":      []byte(testProjectRoot),
+						"types/cat.jst": []byte(testProjectCat),
+					},
+				},
+			},
+		}
+
+		for n, c := range cc {
+			t.Run(n, func(t *testing.T) {
+				actual, err := readProject(httptest.NewRecorder(), c.request(t))
+				require.NoError(t, err)
+				assert.Equal(t, c.expected, actual)
+			})
+		}
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		cc := map[string]struct {
+			request  func(*testing.T) *http.Request
+			expected string
+		}{
+			"root is not specified": {
+				func(t *testing.T) *http.Request {
+					return newMultipartProjectRequest(t, "", map[string]string{
+						"main.jst":      testProjectRoot,
+						"types/cat.jst": testProjectCat,
+					})
+				},
+				`you must specify the "root" parameter`,
+			},
+
+			"root not found": {
+				func(t *testing.T) *http.Request {
+					return newMultipartProjectRequest(t, "foo.jst", map[string]string{
+						"main.jst": testProjectRoot,
+					})
+				},
+				`the root file "foo.jst" not found in the project`,
+			},
+
+			"parent directory": {
+				func(t *testing.T) *http.Request {
+					return newProjectRequest(t, "application/zip", newZip(t, map[string]string{
+						"../main.jst": testProjectRoot,
+					}), "main.jst")
+				},
+				`invalid file path "../main.jst"`,
+			},
+
+			"absolute path": {
+				func(t *testing.T) *http.Request {
+					return newProjectRequest(t, "application/x-tar", newTar(t, map[string]string{
+						"/etc/main.jst": testProjectRoot,
+					}), "main.jst")
+				},
+				`invalid file path "/etc/main.jst"`,
+			},
+
+			"backslash": {
+				func(t *testing.T) *http.Request {
+					return newMultipartProjectRequest(t, "main.jst", map[string]string{
+						`types\cat.jst`: testProjectCat,
+					})
+				},
+				`invalid file path "types\\cat.jst"`,
+			},
+
+			"invalid archive": {
+				func(t *testing.T) *http.Request {
+					return newProjectRequest(t, "application/zip", []byte("invalid"), "main.jst")
+				},
+				"zip: not a valid zip file",
+			},
+		}
+
+		for n, c := range cc {
+			t.Run(n, func(t *testing.T) {
+				_, err := readProject(httptest.NewRecorder(), c.request(t))
+				assert.EqualError(t, err, c.expected)
+			})
+		}
+
+		t.Run("endless multipart form", func(t *testing.T) {
+			body := &countingReader{r: io.MultiReader(
+				strings.NewReader("--b\r\nContent-Disposition: form-data; name=\"main.jst\"; filename=\"main.jst\"\r\n\r\n"),
+				endlessReader{},
+			)}
+			r, err := http.NewRequest(http.MethodPost, "/?root=main.jst", body)
+			require.NoError(t, err)
+			r.Header.Set("Content-Type", "multipart/form-data; boundary=b")
+
+			_, err = readProject(httptest.NewRecorder(), r)
+			assert.Equal(t, errProjectTooLarge, err)
+			assert.LessOrEqual(t, body.n, int64(maxProjectSize+maxMultipartOverhead+1))
+		})
+	})
+}
+
+func Test_limitProjectBody(t *testing.T) {
+	hh := map[string]http.HandlerFunc{
+		"/convert-jsight?to=jdoc-2.0": convertJSight,
+		"/lint-jsight?enable=":        lintJSight,
+		"/editor/hover?line=1":        editorHandler(editorHoverFeature),
+	}
+
+	for u, h := range hh {
+		t.Run(u, func(t *testing.T) {
+			body := &countingReader{r: io.MultiReader(
+				strings.NewReader("--b\r\nContent-Disposition: form-data; name=\"main.jst\"; filename=\"main.jst\"\r\n\r\n"),
+				endlessReader{},
+			)}
+			r, err := http.NewRequest(http.MethodPost, u+"&root=main.jst", body)
+			require.NoError(t, err)
+			r.Header.Set("Content-Type", "multipart/form-data; boundary=b")
+
+			w := httptest.NewRecorder()
+			h(w, r)
+
+			assert.Equal(t, http.StatusConflict, w.Code)
+			assert.Contains(t, w.Body.String(), `"Message":"the project size exceeds 33554432 bytes"`)
+			assert.LessOrEqual(t, body.n, int64(maxProjectSize+maxMultipartOverhead+1))
+		})
+	}
+}
+
+// countingReader counts the bytes read.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// endlessReader never ends.
+type endlessReader struct{}
+
+func (endlessReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 'a'
+	}
+	return len(p), nil
+}
+
+func Test_project_build(t *testing.T) {
+	t.Run("positive", func(t *testing.T) {
+		p := project{
+			root: "main.jst",
+			files: memFS{
+				"main.jst":      []byte(testProjectRoot),
+				"types/cat.jst": []byte(testProjectCat),
+			},
+		}
+
+		jAPI, je := p.build()
+		require.Nil(t, je)
+
+		_, ok := jAPI.Catalog().UserTypes.Get("@cat")
+		assert.True(t, ok)
+	})
+
+	t.Run("in memory", func(t *testing.T) {
+		// The project can't be written to the disk.
+		t.Setenv("TMPDIR", filepath.Join(t.TempDir(), "absent"))
+
+		_, je := newSingleFileProject([]byte("JSIGHT 0.3\n\nGET /cats\n  200 any\n")).build()
+		assert.Nil(t, je)
+
+		p := project{
+			root: "main.jst",
+			files: memFS{
+				"main.jst":      []byte(testProjectRoot),
+				"types/cat.jst": []byte(testProjectCat),
+			},
+		}
+		_, je = p.build()
+		require.NotNil(t, je)
+		assert.Contains(t, je.Error(), "absent")
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		t.Run("included file is outside the project", func(t *testing.T) {
+			p := newSingleFileProject([]byte("JSIGHT 0.3\n\nINCLUDE main.go\n"))
+
+			_, je := p.build()
+			require.NotNil(t, je)
+			assert.Equal(t, `incorrect parameter (Filename) "main.go": does not exist`, je.Error())
+			assert.Equal(t, "root", je.File.Name())
+		})
+
+		t.Run("path escape", func(t *testing.T) {
+			cc := map[string]string{
+				"../go.mod":          "cannot contain `..` or `.`",
+				"types/../../go.mod": "cannot contain `..` or `.`",
+				"./go.mod":           "cannot contain `..` or `.`",
+				"/etc/passwd":        "cannot not start with `/`",
+				`types\..\go.mod`:    "directories must be separated by slashes `/`",
+			}
+
+			for include, expected := range cc {
+				t.Run(include, func(t *testing.T) {
+					p := project{
+						root: "types/main.jst",
+						files: memFS{
+							"types/main.jst": []byte("JSIGHT 0.3\n\nINCLUDE " + include + "\n"),
+							"go.mod":         []byte("TYPE @mod {}"),
+						},
+					}
+
+					_, je := p.build()
+					require.NotNil(t, je)
+					assert.Contains(t, je.Error(), expected)
+				})
+			}
+		})
+
+		t.Run("error in included file", func(t *testing.T) {
+			p := project{
+				root: "main.jst",
+				files: memFS{
+					"main.jst":      []byte(testProjectRoot),
+					"types/cat.jst": []byte("TYPE @cat\n  {\n"),
+				},
+			}
+
+			_, je := p.build()
+			require.NotNil(t, je)
+			assert.Equal(t, "Unexpected end of file\ntypes/cat.jst:2\nmain.jst:3", je.Error())
+			assert.Equal(t, "types/cat.jst", je.File.Name())
+			assert.Equal(t, 2, je.Line.Int())
+			assert.Equal(t, []includeFrame{{File: "main.jst", Line: 3}}, includeTrace(je))
+		})
+	})
+}
+
+func newProjectRequest(t *testing.T, contentType string, body []byte, root ...string) *http.Request {
+	u := "/?to=jdoc-2.0"
+	if len(root) != 0 {
+		u += "&root=" + root[0]
+	}
+
+	r, err := http.NewRequest(http.MethodPost, u, bytes.NewReader(body)) //nolint:noctx
+	require.NoError(t, err)
+	r.Header.Set("Content-Type", contentType)
+	return r
+}
+
+func newMultipartProjectRequest(t *testing.T, root string, files map[string]string) *http.Request {
+	b := &bytes.Buffer{}
+	w := multipart.NewWriter(b)
+
+	if root != "" {
+		require.NoError(t, w.WriteField("root", root))
+	}
+
+	for name, content := range files {
+		fw, err := w.CreateFormFile(name, name)
+		require.NoError(t, err)
+
+		_, err = fw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	return newProjectRequest(t, w.FormDataContentType(), b.Bytes())
+}
+
+func newZip(t *testing.T, files map[string]string) []byte {
+	b := &bytes.Buffer{}
+	w := zip.NewWriter(b)
+
+	for name, content := range files {
+		fw, err := w.Create(name)
+		require.NoError(t, err)
+
+		_, err = fw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	return b.Bytes()
+}
+
+func newTar(t *testing.T, files map[string]string) []byte {
+	b := &bytes.Buffer{}
+	w := tar.NewWriter(b)
+
+	for name, content := range files {
+		require.NoError(t, w.WriteHeader(&tar.Header{
+			Name:     name,
+			Typeflag: tar.TypeReg,
+			Mode:     0o600,
+			Size:     int64(len(content)),
+		}))
+
+		_, err := w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	return b.Bytes()
+}
@@ -0,0 +1,355 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/jsightapi/jsight-api-core/catalog"
+	"github.com/jsightapi/jsight-api-core/kit"
+)
+
+// maxProxyBodySize limits the size of bodies validated by the proxy.
+const maxProxyBodySize = 32 << 20
+
+// contractViolation describes a mismatch between the traffic and the JSight
+// API.
+type contractViolation struct {
+	// Target a part of the interaction, e.g. "request.body".
+	Target string `json:"target"`
+
+	// Pointer a JSON Pointer (RFC 6901) to the invalid value.
+	Pointer string `json:"pointer"`
+
+	// Message a human-readable description of the violation.
+	Message string `json:"message"`
+}
+
+type contractViolations []contractViolation
+
+func (vv *contractViolations) add(target string, ee []payloadError) {
+	for _, e := range ee {
+		*vv = append(*vv, contractViolation{Target: target, Pointer: e.Pointer, Message: e.Message})
+	}
+}
+
+func (vv contractViolations) Error() string {
+	ss := make([]string, 0, len(vv))
+	for _, v := range vv {
+		ss = append(ss, fmt.Sprintf("%s %s: %s", v.Target, v.Pointer, v.Message))
+	}
+	return "contract violation: " + strings.Join(ss, "; ")
+}
+
+// validatingProxy forwards requests to the upstream server and validates the
+// requests and the upstream responses against the JSight API.
+type validatingProxy struct {
+	router *httpRouter
+	proxy  *httputil.ReverseProxy
+
+	// reject rejects invalid requests and responses instead of logging them.
+	reject bool
+}
+
+func newValidatingProxy(jAPI kit.JApi, upstream *url.URL, reject bool) *validatingProxy {
+	p := &validatingProxy{
+		router: newHTTPRouter(jAPI.Catalog()),
+		proxy:  httputil.NewSingleHostReverseProxy(upstream),
+		reject: reject,
+	}
+	p.proxy.ModifyResponse = p.validateResponse
+	p.proxy.ErrorHandler = p.handleError
+	return p
+}
+
+// interactionContextKey is a key of the request context holding the matched
+// interaction.
+type interactionContextKey struct{}
+
+func contextWithInteraction(ctx context.Context, i *catalog.HTTPInteraction) context.Context {
+	return context.WithValue(ctx, interactionContextKey{}, i)
+}
+
+func interactionFromContext(ctx context.Context) (*catalog.HTTPInteraction, bool) {
+	i, ok := ctx.Value(interactionContextKey{}).(*catalog.HTTPInteraction)
+	return i, ok
+}
+
+func (p *validatingProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s (proxy)", r.Method, r.URL.Path)
+
+	i, vv, err := p.validateRequest(r)
+	if err != nil {
+		log.Print("... request " + err.Error())
+		if p.reject {
+			httpResponseWriter{writer: w}.internalServerError(err)
+			return
+		}
+	}
+
+	if len(vv) != 0 {
+		log.Print("... request " + vv.Error())
+		if p.reject {
+			writeContractViolations(w, http.StatusBadRequest, vv)
+			return
+		}
+	}
+
+	if i != nil {
+		r = r.WithContext(contextWithInteraction(r.Context(), i))
+	}
+	p.proxy.ServeHTTP(w, r)
+}
+
+func (p *validatingProxy) validateRequest(r *http.Request) (*catalog.HTTPInteraction, contractViolations, error) {
+	var vv contractViolations
+
+	i, _, _ := p.router.match(r.Method, r.URL.Path)
+	if i == nil {
+		var (
+			ee  []payloadError
+			err error
+		)
+		i, ee, err = p.router.matchInvalid(r.Method, r.URL.Path)
+		if err != nil {
+			return nil, nil, err
+		}
+		if i == nil {
+			return nil, contractViolations{{
+				Target:  "request",
+				Message: fmt.Sprintf("the interaction %s %s is not described", r.Method, r.URL.Path),
+			}}, nil
+		}
+		vv.add(targetPathVariables, ee)
+	}
+
+	if i.Query != nil && i.Query.Schema != nil {
+		ee, err := validateJSON(i.Query.Schema.JSchema, queryObject(r.URL.Query()), true)
+		if err != nil {
+			return i, nil, err
+		}
+		vv.add(targetQuery, ee)
+	}
+
+	if i.Request == nil {
+		return i, vv, nil
+	}
+
+	if h := i.Request.HTTPRequestHeaders; h != nil && h.Schema != nil {
+		ee, err := validateHeaders(h.Schema, r.Header)
+		if err != nil {
+			return i, nil, err
+		}
+		vv.add(targetRequestHeaders, ee)
+	}
+
+	if b := i.Request.HTTPRequestBody; b != nil && b.Schema != nil {
+		body, err := readBody(&r.Body)
+		if err != nil {
+			return i, nil, err
+		}
+
+		ee, err := validateExchangeSchema(b.Schema, body, false)
+		if err != nil {
+			return i, nil, err
+		}
+		vv.add(targetRequestBody, ee)
+	}
+
+	return i, vv, nil
+}
+
+func (p *validatingProxy) validateResponse(resp *http.Response) error {
+	i, ok := interactionFromContext(resp.Request.Context())
+	if !ok {
+		return nil
+	}
+
+	vv, err := validateHTTPResponse(i, resp)
+	if err != nil {
+		log.Print("... response " + err.Error())
+		if p.reject {
+			return err
+		}
+		return nil
+	}
+	if len(vv) == 0 {
+		return nil
+	}
+
+	log.Print("... response " + vv.Error())
+	if p.reject {
+		return vv
+	}
+	return nil
+}
+
+func (p *validatingProxy) handleError(w http.ResponseWriter, _ *http.Request, err error) {
+	var vv contractViolations
+	if errors.As(err, &vv) {
+		writeContractViolations(w, http.StatusBadGateway, vv)
+		return
+	}
+
+	log.Print("... " + err.Error())
+	w.WriteHeader(http.StatusBadGateway)
+}
+
+// validateHTTPResponse validates the response against responses of the
+// interaction with the same code. The response is valid if it conforms to any
+// of them, otherwise violations of the first one are returned.
+func validateHTTPResponse(i *catalog.HTTPInteraction, resp *http.Response) (contractViolations, error) {
+	code := strconv.Itoa(resp.StatusCode)
+
+	var body []byte
+	bodyRead := false
+
+	var first contractViolations
+	found := false
+	for _, r := range i.Responses {
+		if r.Code != code {
+			continue
+		}
+
+		var vv contractViolations
+
+		if r.Headers != nil && r.Headers.Schema != nil {
+			ee, err := validateHeaders(r.Headers.Schema, resp.Header)
+			if err != nil {
+				return nil, err
+			}
+			vv.add(targetResponseHeaders, ee)
+		}
+
+		if r.Body != nil && r.Body.Schema != nil && isIdentityEncoding(resp.Header) {
+			if !bodyRead {
+				var err error
+				if body, err = readBody(&resp.Body); err != nil {
+					return nil, err
+				}
+				bodyRead = true
+			}
+
+			ee, err := validateExchangeSchema(r.Body.Schema, body, false)
+			if err != nil {
+				return nil, err
+			}
+			vv.add(targetResponseBody, ee)
+		}
+
+		if len(vv) == 0 {
+			return nil, nil
+		}
+		if !found {
+			first = vv
+			found = true
+		}
+	}
+
+	if !found {
+		return contractViolations{{
+			Target:  "response",
+			Message: fmt.Sprintf("the response code %s is not described", code),
+		}}, nil
+	}
+	return first, nil
+}
+
+// validateHeaders validates only headers described in the schema, because
+// clients and proxies add a lot of other headers.
+func validateHeaders(s *catalog.ExchangeJSightSchema, h http.Header) ([]payloadError, error) {
+	an, err := s.GetAST()
+	if err != nil {
+		return nil, err
+	}
+
+	obj := make(map[string]string, len(an.Children))
+	for _, c := range an.Children {
+		if c.IsKeyShortcut {
+			continue
+		}
+		if vv := h.Values(c.Key); len(vv) != 0 {
+			obj[c.Key] = strings.Join(vv, ", ")
+		}
+	}
+
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	return validateJSON(s.JSchema, b, true)
+}
+
+// queryObject converts query parameters into a JSON object. Repeated parameters
+// become arrays.
+func queryObject(q url.Values) []byte {
+	obj := make(map[string]any, len(q))
+	for k, vv := range q {
+		if len(vv) == 1 {
+			obj[k] = vv[0]
+		} else {
+			obj[k] = vv
+		}
+	}
+
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return []byte("{}")
+	}
+	return b
+}
+
+// readBody reads the whole body and replaces it with a copy, so it can be read
+// again. If the body can't be read, the read part is put back in front of the
+// rest of the body, so the body can still be forwarded as is.
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+
+	b, err := io.ReadAll(io.LimitReader(*body, maxProxyBodySize+1))
+	if err == nil && len(b) > maxProxyBodySize {
+		err = fmt.Errorf("the body size exceeds %d bytes", maxProxyBodySize)
+	}
+	if err != nil {
+		*body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(b), *body), *body}
+		return nil, err
+	}
+
+	_ = (*body).Close()
+	*body = io.NopCloser(bytes.NewReader(b))
+	return b, nil
+}
+
+// isIdentityEncoding reports whether the body is not compressed, only such
+// bodies are validated.
+func isIdentityEncoding(h http.Header) bool {
+	e := h.Get("Content-Encoding")
+	return e == "" || e == "identity"
+}
+
+func writeContractViolations(w http.ResponseWriter, code int, vv contractViolations) {
+	b, err := json.Marshal(struct {
+		Errors contractViolations `json:"errors"`
+	}{vv})
+	if err != nil {
+		httpResponseWriter{writer: w}.internalServerError(err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	_, _ = w.Write(b)
+}
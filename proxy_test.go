@@ -0,0 +1,240 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsightapi/jsight-schema-core/fs"
+
+	"github.com/jsightapi/jsight-api-core/kit"
+)
+
+const testProxyAPI = `JSIGHT 0.3
+
+POST /cats/{id}
+  Path
+  {
+    "id": 1
+  }
+  Query
+  {
+    "notify": true // {optional: true}
+  }
+  Request
+    Headers
+    {
+      "X-Token": "abc" // {minLength: 3}
+    }
+    Body
+    {
+      "name": "Tom"
+    }
+  200
+    Headers
+    {
+      "X-Count": 1
+    }
+    Body
+    {
+      "id": 1
+    }
+
+POST /pattern
+  Request
+    Body regex
+      /^(?=a)b$/
+  200 any
+
+POST /pattern-response
+  200 regex
+    /^(?=a)b$/
+`
+
+func Test_validatingProxy(t *testing.T) {
+	jAPI, je := kit.NewJApiFromFile(fs.NewFile("root", []byte(testProxyAPI)))
+	require.Nil(t, je)
+
+	largeBody := `{"name": "` + strings.Repeat("a", maxProxyBodySize) + `"}`
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		// The upstream answers with the response from the request body.
+		switch string(b) {
+		case `{"name": "invalid response"}`:
+			w.Header().Set("X-Count", "many")
+			_, _ = w.Write([]byte(`{"id": "1"}`))
+		case `{"name": "unknown code"}`:
+			w.WriteHeader(http.StatusTeapot)
+		case `{"name": "large response"}`:
+			w.Header().Set("X-Count", "2")
+			_, _ = w.Write([]byte(largeBody))
+		default:
+			w.Header().Set("X-Count", "2")
+			_, _ = w.Write([]byte(`{"id": 1}`))
+		}
+	}))
+	defer upstream.Close()
+
+	u, err := url.Parse(upstream.URL)
+	require.NoError(t, err)
+
+	newRequest := func(t *testing.T, path, token, body string) *http.Request {
+		r, err := http.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		require.NoError(t, err)
+		r.Header.Set("X-Token", token)
+		r.Header.Set("User-Agent", "test")
+		return r
+	}
+
+	t.Run("positive", func(t *testing.T) {
+		cc := map[string]struct {
+			reject  bool
+			request *http.Request
+		}{
+			"valid": {
+				true,
+				newRequest(t, "/cats/1?notify=false", "abcd", `{"name": "Tom"}`),
+			},
+			"invalid request is forwarded": {
+				false,
+				newRequest(t, "/cats/1?notify=yes", "a", `{"name": 1}`),
+			},
+		}
+
+		for n, c := range cc {
+			t.Run(n, func(t *testing.T) {
+				w := httptest.NewRecorder()
+				newValidatingProxy(jAPI, u, c.reject).ServeHTTP(w, c.request)
+
+				assert.Equal(t, http.StatusOK, w.Code)
+				assert.Equal(t, `{"id": 1}`, w.Body.String())
+			})
+		}
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		cc := map[string]struct {
+			request  *http.Request
+			code     int
+			expected string
+		}{
+			"invalid request": {
+				newRequest(t, "/cats/1?notify=yes", "a", `{"name": 1}`),
+				http.StatusBadRequest,
+				`{"errors":[{"target":"query","pointer":"/notify","message":"the value must be of the \"boolean\" type"},{"target":"request.headers","pointer":"/X-Token","message":"the value violates the rule \"minLength\": 3"},{"target":"request.body","pointer":"/name","message":"the value must be of the \"string\" type"}]}`,
+			},
+			"invalid path variable": {
+				newRequest(t, "/cats/tom", "abc", `{"name": 1}`),
+				http.StatusBadRequest,
+				`{"errors":[{"target":"path","pointer":"/id","message":"the value must be of the \"integer\" type"},{"target":"request.body","pointer":"/name","message":"the value must be of the \"string\" type"}]}`,
+			},
+			"unknown interaction": {
+				newRequest(t, "/dogs/1", "abc", `{"name": "Tom"}`),
+				http.StatusBadRequest,
+				`{"errors":[{"target":"request","pointer":"","message":"the interaction POST /dogs/1 is not described"}]}`,
+			},
+			"invalid response": {
+				newRequest(t, "/cats/1", "abc", `{"name": "invalid response"}`),
+				http.StatusBadGateway,
+				`{"errors":[{"target":"response.headers","pointer":"/X-Count","message":"the value must be of the \"integer\" type"},{"target":"response.body","pointer":"/id","message":"the value must be of the \"integer\" type"}]}`,
+			},
+			"unknown response code": {
+				newRequest(t, "/cats/1", "abc", `{"name": "unknown code"}`),
+				http.StatusBadGateway,
+				`{"errors":[{"target":"response","pointer":"","message":"the response code 418 is not described"}]}`,
+			},
+		}
+
+		for n, c := range cc {
+			t.Run(n, func(t *testing.T) {
+				w := httptest.NewRecorder()
+				newValidatingProxy(jAPI, u, true).ServeHTTP(w, c.request)
+
+				assert.Equal(t, c.code, w.Code)
+				assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+				assert.Equal(t, c.expected, w.Body.String())
+			})
+		}
+	})
+
+	t.Run("internal errors", func(t *testing.T) {
+		cc := map[string]struct {
+			reject   bool
+			request  *http.Request
+			code     int
+			expected string
+		}{
+			"large request is forwarded": {
+				false,
+				newRequest(t, "/cats/1", "abc", largeBody),
+				http.StatusOK,
+				`{"id": 1}`,
+			},
+			"large request is rejected": {
+				true,
+				newRequest(t, "/cats/1", "abc", largeBody),
+				http.StatusInternalServerError,
+				"the body size exceeds 33554432 bytes",
+			},
+			"large response is forwarded": {
+				false,
+				newRequest(t, "/cats/1", "abc", `{"name": "large response"}`),
+				http.StatusOK,
+				largeBody,
+			},
+			"large response is rejected": {
+				true,
+				newRequest(t, "/cats/1", "abc", `{"name": "large response"}`),
+				http.StatusBadGateway,
+				"",
+			},
+			"request validation error is forwarded": {
+				false,
+				newRequest(t, "/pattern", "abc", "b"),
+				http.StatusOK,
+				`{"id": 1}`,
+			},
+			"request validation error is rejected": {
+				true,
+				newRequest(t, "/pattern", "abc", "b"),
+				http.StatusInternalServerError,
+				"ERROR (code 1502): The regular expression is invalid: /^(?=a)b$/",
+			},
+			"response validation error is forwarded": {
+				false,
+				newRequest(t, "/pattern-response", "abc", ""),
+				http.StatusOK,
+				`{"id": 1}`,
+			},
+			"response validation error is rejected": {
+				true,
+				newRequest(t, "/pattern-response", "abc", ""),
+				http.StatusBadGateway,
+				"",
+			},
+		}
+
+		for n, c := range cc {
+			t.Run(n, func(t *testing.T) {
+				w := httptest.NewRecorder()
+				newValidatingProxy(jAPI, u, c.reject).ServeHTTP(w, c.request)
+
+				assert.Equal(t, c.code, w.Code)
+
+				// Only the first line of errors is checked, the rest is a
+				// source snippet.
+				body, _, _ := strings.Cut(w.Body.String(), "\n")
+				assert.True(t, body == c.expected, "unexpected body %.100q", body)
+			})
+		}
+	})
+}
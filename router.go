@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/jsightapi/jsight-api-core/catalog"
+)
+
+// httpRouter finds HTTP interactions of the JSight API by requests.
+type httpRouter struct {
+	routes []httpRoute
+}
+
+type httpRoute struct {
+	interaction *catalog.HTTPInteraction
+
+	// path a regular expression compiled from the interaction path.
+	path *regexp.Regexp
+
+	// vars names of the path variables in the order of their appearance.
+	vars []string
+
+	// literals a number of literal characters in the path. Paths with more
+	// literals are more specific, so "/cats/new" wins "/cats/{id}".
+	literals int
+}
+
+var pathVariableRegexp = regexp.MustCompile(`\{([^}/]+)\}`)
+
+func newHTTPRouter(c *catalog.Catalog) *httpRouter {
+	r := &httpRouter{}
+
+	c.Interactions.EachSafe(func(_ catalog.InteractionID, v catalog.Interaction) {
+		i, ok := v.(*catalog.HTTPInteraction)
+		if !ok {
+			return
+		}
+
+		p := i.PathVal.String()
+
+		var (
+			expr strings.Builder
+			vars []string
+			last int
+		)
+		expr.WriteString("^")
+		for _, m := range pathVariableRegexp.FindAllStringSubmatchIndex(p, -1) {
+			expr.WriteString(regexp.QuoteMeta(p[last:m[0]]))
+			expr.WriteString("([^/]+)")
+			vars = append(vars, p[m[2]:m[3]])
+			last = m[1]
+		}
+		expr.WriteString(regexp.QuoteMeta(p[last:]))
+		expr.WriteString("/?$")
+
+		r.routes = append(r.routes, httpRoute{
+			interaction: i,
+			path:        regexp.MustCompile(expr.String()),
+			vars:        vars,
+			literals:    len(pathVariableRegexp.ReplaceAllString(p, "")),
+		})
+	})
+
+	return r
+}
+
+// match returns the most specific interaction matching the method and the path
+// of the request along with values of the path variables. If there is no such
+// interaction, methods allowed for the path are returned.
+func (r *httpRouter) match(method, path string) (*catalog.HTTPInteraction, map[string]string, []string) {
+	var (
+		found   *httpRoute
+		vars    map[string]string
+		allowed []string
+	)
+
+	for i := range r.routes {
+		route := &r.routes[i]
+
+		vv, ok := route.matchPath(path)
+		if !ok {
+			continue
+		}
+
+		m := route.interaction.HttpMethod.String()
+		if m != method {
+			allowed = append(allowed, m)
+			continue
+		}
+
+		if found == nil || route.literals > found.literals {
+			found = route
+			vars = vv
+		}
+	}
+
+	if found == nil {
+		return nil, nil, allowed
+	}
+	return found.interaction, vars, nil
+}
+
+// matchInvalid returns the most specific interaction which matches the method
+// and the path template of the request, though values of its path variables
+// violate their schema, along with the violations. It is called when match
+// finds nothing, so the request is reported as invalid rather than unknown.
+func (r *httpRouter) matchInvalid(method, path string) (*catalog.HTTPInteraction, []payloadError, error) {
+	var found *httpRoute
+	for i := range r.routes {
+		route := &r.routes[i]
+		if route.interaction.HttpMethod.String() != method {
+			continue
+		}
+		if _, ok := route.matchTemplate(path); !ok {
+			continue
+		}
+		if found == nil || route.literals > found.literals {
+			found = route
+		}
+	}
+
+	if found == nil {
+		return nil, nil, nil
+	}
+
+	vars, _ := found.matchTemplate(path)
+	ee, err := found.pathVariableErrors(vars)
+	if err != nil {
+		return nil, nil, err
+	}
+	return found.interaction, ee, nil
+}
+
+// matchPath matches the path against the route. Values of path variables must
+// conform to their schema.
+func (r httpRoute) matchPath(path string) (map[string]string, bool) {
+	vars, ok := r.matchTemplate(path)
+	if !ok {
+		return nil, false
+	}
+
+	ee, err := r.pathVariableErrors(vars)
+	if err != nil || len(ee) != 0 {
+		return nil, false
+	}
+	return vars, true
+}
+
+// matchTemplate matches the path against the path template of the route and
+// returns values of path variables without validating them.
+func (r httpRoute) matchTemplate(path string) (map[string]string, bool) {
+	m := r.path.FindStringSubmatch(path)
+	if m == nil {
+		return nil, false
+	}
+
+	vars := make(map[string]string, len(r.vars))
+	for i, name := range r.vars {
+		vars[name] = m[i+1]
+	}
+	return vars, true
+}
+
+// pathVariableErrors validates values of path variables against their schema.
+func (r httpRoute) pathVariableErrors(vars map[string]string) ([]payloadError, error) {
+	pv := r.interaction.PathVariables
+	if pv == nil || pv.Schema == nil {
+		return nil, nil
+	}
+
+	b, err := json.Marshal(vars)
+	if err != nil {
+		return nil, err
+	}
+	return validateJSON(pv.Schema.JSchema, b, true)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsightapi/jsight-schema-core/fs"
+
+	"github.com/jsightapi/jsight-api-core/kit"
+)
+
+func Test_httpRouter_match(t *testing.T) {
+	jAPI, je := kit.NewJApiFromFile(fs.NewFile("root", []byte(testMockAPI)))
+	require.Nil(t, je)
+
+	r := newHTTPRouter(jAPI.Catalog())
+
+	t.Run("positive", func(t *testing.T) {
+		cc := map[string]struct {
+			method string
+			path   string
+			id     string
+			vars   map[string]string
+		}{
+			"path variable":  {http.MethodGet, "/cats/12", "http GET /cats/{id}", map[string]string{"id": "12"}},
+			"static path":    {http.MethodGet, "/cats/new", "http GET /cats/new", map[string]string{}},
+			"trailing slash": {http.MethodPost, "/cats/", "http POST /cats", map[string]string{}},
+			"another method": {http.MethodDelete, "/cats/3", "http DELETE /cats/{id}", map[string]string{"id": "3"}},
+		}
+
+		for n, c := range cc {
+			t.Run(n, func(t *testing.T) {
+				i, vars, allowed := r.match(c.method, c.path)
+				require.NotNil(t, i)
+				assert.Equal(t, c.id, i.Id)
+				assert.Equal(t, c.vars, vars)
+				assert.Nil(t, allowed)
+			})
+		}
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		cc := map[string]struct {
+			method  string
+			path    string
+			allowed []string
+		}{
+			"unknown path":         {http.MethodGet, "/dogs", nil},
+			"invalid variable":     {http.MethodGet, "/cats/tom", nil},
+			"not allowed method":   {http.MethodPut, "/cats", []string{"POST"}},
+			"nested path mismatch": {http.MethodGet, "/cats/1/friends", nil},
+		}
+
+		for n, c := range cc {
+			t.Run(n, func(t *testing.T) {
+				i, _, allowed := r.match(c.method, c.path)
+				assert.Nil(t, i)
+				assert.Equal(t, c.allowed, allowed)
+			})
+		}
+	})
+}
+
+func Test_httpRouter_matchInvalid(t *testing.T) {
+	jAPI, je := kit.NewJApiFromFile(fs.NewFile("root", []byte(testMockAPI)))
+	require.Nil(t, je)
+
+	r := newHTTPRouter(jAPI.Catalog())
+
+	t.Run("positive", func(t *testing.T) {
+		i, ee, err := r.matchInvalid(http.MethodGet, "/cats/tom")
+		require.NoError(t, err)
+		require.NotNil(t, i)
+		assert.Equal(t, "http GET /cats/{id}", i.Id)
+		assert.Equal(t, []payloadError{{Pointer: "/id", Message: `the value must be of the "integer" type`}}, ee)
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		cc := map[string]struct {
+			method string
+			path   string
+		}{
+			"unknown path":       {http.MethodGet, "/dogs/tom"},
+			"not allowed method": {http.MethodPut, "/cats/tom"},
+		}
+
+		for n, c := range cc {
+			t.Run(n, func(t *testing.T) {
+				i, ee, err := r.matchInvalid(c.method, c.path)
+				require.NoError(t, err)
+				assert.Nil(t, i)
+				assert.Nil(t, ee)
+			})
+		}
+	})
+}
@@ -0,0 +1,789 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	schema "github.com/jsightapi/jsight-schema-core"
+	"github.com/jsightapi/jsight-schema-core/notations/regex"
+
+	"github.com/jsightapi/jsight-api-core/catalog"
+	"github.com/jsightapi/jsight-api-core/notation"
+)
+
+// Kinds of API changes.
+const (
+	changeAdded   = "added"
+	changeRemoved = "removed"
+	changeChanged = "changed"
+)
+
+// Targets which exist only in the diff. The other ones are the payload
+// validation targets.
+const (
+	targetResponse = "response"
+	targetParams   = "params"
+	targetResult   = "result"
+)
+
+// specChange describes a difference between two versions of the API.
+type specChange struct {
+	// Interaction an interaction ID, e.g. "http GET /cats/{id}".
+	Interaction string `json:"interaction"`
+
+	// Target a part of the interaction, e.g. "query" or "response.body". It's
+	// empty if the whole interaction is added or removed.
+	Target string `json:"target,omitempty"`
+
+	// Code a response code for the response targets.
+	Code string `json:"code,omitempty"`
+
+	// Pointer a JSON Pointer (RFC 6901) to the changed value in the payload.
+	// Items of arrays are denoted with "*".
+	Pointer string `json:"pointer,omitempty"`
+
+	// Kind a kind of the change: "added", "removed" or "changed".
+	Kind string `json:"kind"`
+
+	// Breaking is true if the change can break existing clients.
+	Breaking bool `json:"breaking"`
+
+	// Message a human-readable description of the change.
+	Message string `json:"message"`
+}
+
+// diffCatalogs compares the interactions of two versions of the API.
+//
+// The changes are classified from the client's point of view. A client sends
+// requests, so a narrowed request (e.g. a new required property or a removed
+// enum value) is breaking, while a widened one is not. A client receives
+// responses, so a widened response (e.g. a new enum value or a property which
+// becomes optional) is breaking, while a narrowed one is not.
+func diffCatalogs(oldCat, newCat *catalog.Catalog) ([]specChange, error) {
+	d := specDiff{}
+
+	oldIDs, oldInteractions := interactionsByID(oldCat)
+	newIDs, newInteractions := interactionsByID(newCat)
+
+	for _, id := range oldIDs {
+		n, ok := newInteractions[id]
+		if !ok {
+			d.add(specChange{
+				Interaction: id,
+				Kind:        changeRemoved,
+				Breaking:    true,
+				Message:     "the interaction is removed",
+			})
+			continue
+		}
+		d.interaction(id, oldInteractions[id], n)
+	}
+
+	for _, id := range newIDs {
+		if _, ok := oldInteractions[id]; !ok {
+			d.add(specChange{
+				Interaction: id,
+				Kind:        changeAdded,
+				Message:     "the interaction is added",
+			})
+		}
+	}
+
+	return d.changes, d.err
+}
+
+func interactionsByID(c *catalog.Catalog) ([]string, map[string]catalog.Interaction) {
+	var ids []string
+	ii := map[string]catalog.Interaction{}
+	c.Interactions.EachSafe(func(k catalog.InteractionID, v catalog.Interaction) {
+		ids = append(ids, k.String())
+		ii[k.String()] = v
+	})
+	return ids, ii
+}
+
+type specDiff struct {
+	changes []specChange
+
+	// err an error in the schemas, if any.
+	err error
+}
+
+func (d *specDiff) add(c specChange) {
+	d.changes = append(d.changes, c)
+}
+
+// part returns the diff of the interaction part. The request parts are sent by
+// the client, the response parts are received by it.
+func (d *specDiff) part(id, target, code string, request bool) *partDiff {
+	return &partDiff{
+		diff:        d,
+		interaction: id,
+		target:      target,
+		code:        code,
+		request:     request,
+		visited:     map[string]bool{},
+	}
+}
+
+func (d *specDiff) interaction(id string, o, n catalog.Interaction) {
+	switch ov := o.(type) {
+	case *catalog.HTTPInteraction:
+		if nv, ok := n.(*catalog.HTTPInteraction); ok {
+			d.httpInteraction(id, ov, nv)
+		}
+
+	case *catalog.JsonRpcInteraction:
+		if nv, ok := n.(*catalog.JsonRpcInteraction); ok {
+			d.part(id, targetParams, "", true).jsight(rpcParamsSchema(ov), rpcParamsSchema(nv))
+			d.part(id, targetResult, "", false).jsight(rpcResultSchema(ov), rpcResultSchema(nv))
+		}
+	}
+}
+
+func (d *specDiff) httpInteraction(id string, o, n *catalog.HTTPInteraction) {
+	for _, t := range []string{targetPathVariables, targetQuery, targetRequestHeaders} {
+		d.part(id, t, "", true).jsight(httpSchema(o, t), httpSchema(n, t))
+	}
+	d.part(id, targetRequestBody, "", true).exchange(requestBodySchema(o), requestBodySchema(n))
+
+	oldResponses := responsesByCode(o)
+	newResponses := responsesByCode(n)
+	compared := map[string]bool{}
+
+	for _, r := range o.Responses {
+		if compared[r.Code] {
+			continue
+		}
+		compared[r.Code] = true
+
+		nr, ok := newResponses[r.Code]
+		if !ok {
+			d.add(specChange{
+				Interaction: id,
+				Target:      targetResponse,
+				Code:        r.Code,
+				Kind:        changeRemoved,
+				Breaking:    true,
+				Message:     "the response is removed",
+			})
+			continue
+		}
+
+		d.part(id, targetResponseHeaders, r.Code, false).jsight(responseHeadersSchema(r), responseHeadersSchema(nr))
+		d.part(id, targetResponseBody, r.Code, false).exchange(responseBodySchema(r), responseBodySchema(nr))
+	}
+
+	for _, r := range n.Responses {
+		if _, ok := oldResponses[r.Code]; ok || compared[r.Code] {
+			continue
+		}
+		compared[r.Code] = true
+
+		d.add(specChange{
+			Interaction: id,
+			Target:      targetResponse,
+			Code:        r.Code,
+			Kind:        changeAdded,
+			Message:     "the response is added",
+		})
+	}
+}
+
+// responsesByCode returns the first response for each response code.
+func responsesByCode(i *catalog.HTTPInteraction) map[string]catalog.HTTPResponse {
+	rr := make(map[string]catalog.HTTPResponse, len(i.Responses))
+	for _, r := range i.Responses {
+		if _, ok := rr[r.Code]; !ok {
+			rr[r.Code] = r
+		}
+	}
+	return rr
+}
+
+func httpSchema(i *catalog.HTTPInteraction, target string) *catalog.ExchangeJSightSchema {
+	es, _, err := interactionSchema(i, target)
+	if err != nil || es == nil {
+		return nil
+	}
+	s, _ := es.(*catalog.ExchangeJSightSchema)
+	return s
+}
+
+func requestBodySchema(i *catalog.HTTPInteraction) catalog.ExchangeSchema {
+	if i.Request == nil || i.Request.HTTPRequestBody == nil {
+		return nil
+	}
+	return i.Request.HTTPRequestBody.Schema
+}
+
+func responseHeadersSchema(r catalog.HTTPResponse) *catalog.ExchangeJSightSchema {
+	if r.Headers == nil {
+		return nil
+	}
+	return r.Headers.Schema
+}
+
+func responseBodySchema(r catalog.HTTPResponse) catalog.ExchangeSchema {
+	if r.Body == nil {
+		return nil
+	}
+	return r.Body.Schema
+}
+
+func rpcParamsSchema(i *catalog.JsonRpcInteraction) *catalog.ExchangeJSightSchema {
+	if i.Params == nil {
+		return nil
+	}
+	return i.Params.Schema
+}
+
+func rpcResultSchema(i *catalog.JsonRpcInteraction) *catalog.ExchangeJSightSchema {
+	if i.Result == nil {
+		return nil
+	}
+	return i.Result.Schema
+}
+
+// partDiff compares the schemas of one part of the interaction.
+type partDiff struct {
+	diff        *specDiff
+	interaction string
+	target      string
+	code        string
+
+	// request is true for the parts sent by the client.
+	request bool
+
+	// old and new give access to the user types and rules of the schemas.
+	old *payloadValidator
+	new *payloadValidator
+
+	// visited pairs of the compared user types, to stop on recursive types.
+	visited map[string]bool
+}
+
+func (p *partDiff) add(pointer, kind string, breaking bool, format string, args ...any) {
+	p.diff.add(specChange{
+		Interaction: p.interaction,
+		Target:      p.target,
+		Code:        p.code,
+		Pointer:     pointer,
+		Kind:        kind,
+		Breaking:    breaking,
+		Message:     fmt.Sprintf(format, args...),
+	})
+}
+
+// narrowed adds a change which allows fewer values than before.
+func (p *partDiff) narrowed(pointer, kind, format string, args ...any) {
+	p.add(pointer, kind, p.request, format, args...)
+}
+
+// widened adds a change which allows more values than before.
+func (p *partDiff) widened(pointer, kind, format string, args ...any) {
+	p.add(pointer, kind, !p.request, format, args...)
+}
+
+func (p *partDiff) setErr(err error) {
+	if p.diff.err == nil {
+		p.diff.err = err
+	}
+}
+
+// exchange compares the bodies. A missing body is the same as the empty one.
+func (p *partDiff) exchange(o, n catalog.ExchangeSchema) {
+	on, nn := schemaNotation(o), schemaNotation(n)
+	if on != nn {
+		p.add("", changeChanged, true, "the body is changed from %s to %s", on, nn)
+		return
+	}
+
+	switch ov := o.(type) {
+	case *catalog.ExchangeJSightSchema:
+		p.jsight(ov, n.(*catalog.ExchangeJSightSchema))
+
+	case *catalog.ExchangeRegexSchema:
+		p.regex(ov.RSchema, n.(*catalog.ExchangeRegexSchema).RSchema, "")
+	}
+}
+
+func schemaNotation(es catalog.ExchangeSchema) notation.SchemaNotation {
+	if es == nil {
+		return notation.SchemaNotationEmpty
+	}
+	return es.Notation()
+}
+
+// jsight compares the JSight schemas. A missing schema is the same as the
+// empty object.
+func (p *partDiff) jsight(o, n *catalog.ExchangeJSightSchema) {
+	if o == nil && n == nil {
+		return
+	}
+
+	on, ov, err := diffRoot(o)
+	if err != nil {
+		p.setErr(err)
+		return
+	}
+	nn, nv, err := diffRoot(n)
+	if err != nil {
+		p.setErr(err)
+		return
+	}
+
+	p.old, p.new = ov, nv
+	p.node(on, nn, "")
+}
+
+func diffRoot(s *catalog.ExchangeJSightSchema) (schema.ASTNode, *payloadValidator, error) {
+	if s == nil {
+		return typeNode(string(schema.SchemaTypeObject)), &payloadValidator{}, nil
+	}
+
+	node, err := s.GetAST()
+	if err != nil {
+		return schema.ASTNode{}, nil, err
+	}
+	return node, &payloadValidator{userTypes: s.UserTypeCollection, rules: s.Rules}, nil
+}
+
+func (p *partDiff) regex(o, n *regex.RSchema, pointer string) {
+	op, err := o.Pattern()
+	if err != nil {
+		p.setErr(err)
+		return
+	}
+	np, err := n.Pattern()
+	if err != nil {
+		p.setErr(err)
+		return
+	}
+
+	if op != np {
+		p.add(pointer, changeChanged, true, "the regular expression is changed from /%s/ to /%s/", op, np)
+	}
+}
+
+func (p *partDiff) node(o, n schema.ASTNode, pointer string) {
+	p.nullable(o, n, pointer)
+
+	if isUserTypeNode(o) || isUserTypeNode(n) {
+		p.userTypes(o, n, pointer)
+		return
+	}
+
+	_, oOr := rule(o, "or")
+	_, nOr := rule(n, "or")
+	if oOr || nOr {
+		p.types(o, n, pointer)
+		return
+	}
+
+	if o.SchemaType != n.SchemaType {
+		p.add(pointer, changeChanged, true, "the type is changed from %s to %s", o.SchemaType, n.SchemaType)
+		return
+	}
+
+	switch schema.SchemaType(o.SchemaType) { //nolint:exhaustive // Literals have only rules.
+	case schema.SchemaTypeObject:
+		p.properties(o, n, pointer)
+	case schema.SchemaTypeArray:
+		p.items(o, n, pointer)
+	}
+
+	p.constraints(o, n, pointer)
+	p.enum(o, n, pointer)
+}
+
+func isUserTypeNode(node schema.ASTNode) bool {
+	_, isOr := rule(node, "or")
+	return !isOr && strings.HasPrefix(node.SchemaType, "@")
+}
+
+// userTypes compares the nodes after replacing user types with their
+// definitions, so renaming of a type isn't a change.
+func (p *partDiff) userTypes(o, n schema.ASTNode, pointer string) {
+	key := o.SchemaType + " " + n.SchemaType
+	if p.visited[key] {
+		return
+	}
+	p.visited[key] = true
+	defer delete(p.visited, key)
+
+	ro, err := resolveUserType(p.old, o)
+	if err != nil {
+		p.setErr(err)
+		return
+	}
+	rn, err := resolveUserType(p.new, n)
+	if err != nil {
+		p.setErr(err)
+		return
+	}
+	p.node(ro, rn, pointer)
+}
+
+// resolveUserType returns the definition of the user type the node refers to.
+// Regular expressions are returned as strings with the "regex" rule.
+func resolveUserType(v *payloadValidator, node schema.ASTNode) (schema.ASTNode, error) {
+	if !isUserTypeNode(node) {
+		return node, nil
+	}
+
+	ut, ok := v.userTypes[node.SchemaType]
+	if !ok {
+		return schema.ASTNode{}, fmt.Errorf("the type %q not found", node.SchemaType)
+	}
+
+	if rs, ok := ut.(*regex.RSchema); ok {
+		pattern, err := rs.Pattern()
+		if err != nil {
+			return schema.ASTNode{}, err
+		}
+
+		rn := typeNode(string(schema.SchemaTypeString))
+		rn.Rules.Set("regex", schema.RuleASTNode{TokenType: schema.TokenTypeString, Value: pattern})
+		return rn, nil
+	}
+
+	return ut.GetAST()
+}
+
+func (p *partDiff) nullable(o, n schema.ASTNode, pointer string) {
+	on, nn := ruleIsTrue(o, "nullable"), ruleIsTrue(n, "nullable")
+	switch {
+	case !on && nn:
+		p.widened(pointer, changeChanged, "the value becomes nullable")
+	case on && !nn:
+		p.narrowed(pointer, changeChanged, "the value is no longer nullable")
+	}
+}
+
+// types compares the allowed types of the nodes, at least one of which has the
+// "or" rule.
+func (p *partDiff) types(o, n schema.ASTNode, pointer string) {
+	ot, nt := nodeTypes(o), nodeTypes(n)
+
+	for _, t := range ot {
+		if !containsString(nt, t) {
+			p.narrowed(pointer, changeRemoved, "the type %s is no longer allowed", t)
+		}
+	}
+	for _, t := range nt {
+		if !containsString(ot, t) {
+			p.widened(pointer, changeAdded, "the type %s is allowed", t)
+		}
+	}
+}
+
+func nodeTypes(node schema.ASTNode) []string {
+	or, ok := rule(node, "or")
+	if !ok {
+		return []string{node.SchemaType}
+	}
+
+	tt := make([]string, 0, len(or.Items))
+	for _, i := range or.Items {
+		tt = append(tt, orItemNode(i).SchemaType)
+	}
+	return tt
+}
+
+func (p *partDiff) properties(o, n schema.ASTNode, pointer string) {
+	op, err := p.old.objectProperties(o)
+	if err != nil {
+		p.setErr(err)
+		return
+	}
+	np, err := p.new.objectProperties(n)
+	if err != nil {
+		p.setErr(err)
+		return
+	}
+
+	for _, oc := range op {
+		cp := pointer + "/" + escapeJSONPointer(oc.Key)
+		required := !ruleIsTrue(oc, "optional")
+
+		nc, ok := findProperty(np, oc)
+		if !ok {
+			// A client can still send the removed property, and it isn't
+			// allowed anymore.
+			p.add(cp, changeRemoved, p.request || required, "the %s property is removed", requiredness(required))
+			continue
+		}
+
+		switch nr := !ruleIsTrue(nc, "optional"); {
+		case required && !nr:
+			p.widened(cp, changeChanged, "the property becomes optional")
+		case !required && nr:
+			p.narrowed(cp, changeChanged, "the property becomes required")
+		}
+
+		p.node(oc, nc, cp)
+	}
+
+	for _, nc := range np {
+		if _, ok := findProperty(op, nc); ok {
+			continue
+		}
+
+		required := !ruleIsTrue(nc, "optional")
+		p.add(
+			pointer+"/"+escapeJSONPointer(nc.Key),
+			changeAdded,
+			p.request && required,
+			"the %s property is added",
+			requiredness(required),
+		)
+	}
+
+	p.additionalProperties(o, n, pointer)
+}
+
+func findProperty(props []schema.ASTNode, prop schema.ASTNode) (schema.ASTNode, bool) {
+	for _, p := range props {
+		if p.Key == prop.Key && p.IsKeyShortcut == prop.IsKeyShortcut {
+			return p, true
+		}
+	}
+	return schema.ASTNode{}, false
+}
+
+func requiredness(required bool) string {
+	if required {
+		return "required"
+	}
+	return "optional"
+}
+
+func (p *partDiff) additionalProperties(o, n schema.ASTNode, pointer string) {
+	ov, nv := "false", "false"
+	if r, ok := rule(o, "additionalProperties"); ok {
+		ov = r.Value
+	}
+	if r, ok := rule(n, "additionalProperties"); ok {
+		nv = r.Value
+	}
+
+	switch {
+	case ov == nv:
+	case ov == "false":
+		p.widened(pointer, changeChanged, "additional properties are allowed: %s", nv)
+	case nv == "false":
+		p.narrowed(pointer, changeChanged, "additional properties are no longer allowed")
+	default:
+		p.add(pointer, changeChanged, true, "additional properties are changed from %s to %s", ov, nv)
+	}
+}
+
+func (p *partDiff) items(o, n schema.ASTNode, pointer string) {
+	switch {
+	case len(o.Children) == 1 && len(n.Children) == 1:
+		p.node(o.Children[0], n.Children[0], pointer+"/*")
+
+	case len(o.Children) == len(n.Children):
+		for i := range o.Children {
+			p.node(o.Children[i], n.Children[i], pointer+"/"+strconv.Itoa(i))
+		}
+
+	// An empty array example allows only empty arrays.
+	case len(o.Children) == 0:
+		p.widened(pointer, changeChanged, "the array items are allowed")
+	case len(n.Children) == 0:
+		p.narrowed(pointer, changeChanged, "the array must be empty")
+
+	default:
+		p.add(pointer, changeChanged, true, "the array items are changed")
+	}
+}
+
+// Constraints whose greater value allows fewer values.
+var lowerBoundRules = []string{"min", "minLength", "minItems"}
+
+// Constraints whose lower value allows fewer values.
+var upperBoundRules = []string{"max", "maxLength", "maxItems", "precision"}
+
+// Boolean constraints which allow fewer values when they are true.
+var flagRules = []string{"exclusiveMinimum", "exclusiveMaximum", "const"}
+
+func (p *partDiff) constraints(o, n schema.ASTNode, pointer string) {
+	for _, name := range lowerBoundRules {
+		p.bound(o, n, name, math.Inf(-1), pointer)
+	}
+	for _, name := range upperBoundRules {
+		p.bound(o, n, name, math.Inf(1), pointer)
+	}
+
+	for _, name := range flagRules {
+		switch on, nn := ruleIsTrue(o, name), ruleIsTrue(n, name); {
+		case !on && nn:
+			p.narrowed(pointer, changeAdded, "the rule %q is added", name)
+		case on && !nn:
+			p.widened(pointer, changeRemoved, "the rule %q is removed", name)
+		}
+	}
+
+	or, oOk := rule(o, "regex")
+	nr, nOk := rule(n, "regex")
+	switch {
+	case !oOk && nOk:
+		p.narrowed(pointer, changeAdded, "the regular expression /%s/ is added", nr.Value)
+	case oOk && !nOk:
+		p.widened(pointer, changeRemoved, "the regular expression /%s/ is removed", or.Value)
+	case oOk && or.Value != nr.Value:
+		p.add(pointer, changeChanged, true, "the regular expression is changed from /%s/ to /%s/", or.Value, nr.Value)
+	}
+}
+
+// bound compares the numeric constraint. The absent constraint is the same as
+// the unbounded value.
+func (p *partDiff) bound(o, n schema.ASTNode, name string, unbounded float64, pointer string) {
+	ov, oOk := boundValue(o, name, unbounded)
+	nv, nOk := boundValue(n, name, unbounded)
+	if ov == nv || !oOk || !nOk {
+		return
+	}
+
+	msg := fmt.Sprintf("the rule %q is changed from %s to %s", name, ruleValueString(o, name), ruleValueString(n, name))
+
+	// For the upper bounds the lower value is the narrower one.
+	if (nv > ov) == (unbounded < 0) {
+		p.narrowed(pointer, changeChanged, "%s", msg)
+	} else {
+		p.widened(pointer, changeChanged, "%s", msg)
+	}
+}
+
+func boundValue(node schema.ASTNode, name string, unbounded float64) (float64, bool) {
+	r, ok := rule(node, name)
+	if !ok {
+		return unbounded, true
+	}
+
+	f, err := strconv.ParseFloat(r.Value, 64)
+	return f, err == nil
+}
+
+func ruleValueString(node schema.ASTNode, name string) string {
+	r, ok := rule(node, name)
+	if !ok {
+		return "none"
+	}
+	return ruleString(r)
+}
+
+func (p *partDiff) enum(o, n schema.ASTNode, pointer string) {
+	ov, oOk, err := enumValues(p.old, o)
+	if err != nil {
+		p.setErr(err)
+		return
+	}
+	nv, nOk, err := enumValues(p.new, n)
+	if err != nil {
+		p.setErr(err)
+		return
+	}
+
+	switch {
+	case !oOk && !nOk:
+	case !oOk:
+		p.narrowed(pointer, changeAdded, "the enum %s is added", strings.Join(nv, ", "))
+	case !nOk:
+		p.widened(pointer, changeRemoved, "the enum is removed")
+	default:
+		for _, v := range ov {
+			if !containsString(nv, v) {
+				p.narrowed(pointer, changeRemoved, "the enum value %s is removed", v)
+			}
+		}
+		for _, v := range nv {
+			if !containsString(ov, v) {
+				p.widened(pointer, changeAdded, "the enum value %s is added", v)
+			}
+		}
+	}
+}
+
+// enumValues returns JSON literals of the "enum" rule of the node.
+func enumValues(v *payloadValidator, node schema.ASTNode) ([]string, bool, error) {
+	r, ok := rule(node, "enum")
+	if !ok {
+		return nil, false, nil
+	}
+
+	if r.TokenType != schema.TokenTypeArray {
+		ur, ok := v.rules[r.Value]
+		if !ok {
+			return nil, false, fmt.Errorf("the enum %q not found", r.Value)
+		}
+
+		an, err := ur.GetAST()
+		if err != nil {
+			return nil, false, err
+		}
+
+		// Values of the enum rule are already JSON literals.
+		vv := make([]string, 0, len(an.Children))
+		for _, c := range an.Children {
+			vv = append(vv, c.Value)
+		}
+		return vv, true, nil
+	}
+
+	vv := make([]string, 0, len(r.Items))
+	for _, i := range r.Items {
+		vv = append(vv, ruleString(i))
+	}
+	return vv, true, nil
+}
+
+// specChangesMarkdown describes the changes for humans.
+func specChangesMarkdown(cc []specChange) string {
+	var b strings.Builder
+	b.WriteString("# API changes\n\n")
+
+	if len(cc) == 0 {
+		b.WriteString("No changes.\n")
+		return b.String()
+	}
+
+	var breaking, nonBreaking []specChange
+	for _, c := range cc {
+		if c.Breaking {
+			breaking = append(breaking, c)
+		} else {
+			nonBreaking = append(nonBreaking, c)
+		}
+	}
+
+	fmt.Fprintf(&b, "Breaking changes: %d, non-breaking changes: %d.\n", len(breaking), len(nonBreaking))
+	writeSpecChanges(&b, "Breaking changes", breaking)
+	writeSpecChanges(&b, "Non-breaking changes", nonBreaking)
+	return b.String()
+}
+
+func writeSpecChanges(b *strings.Builder, title string, cc []specChange) {
+	if len(cc) == 0 {
+		return
+	}
+
+	fmt.Fprintf(b, "\n## %s\n\n", title)
+	for _, c := range cc {
+		fmt.Fprintf(b, "- `%s`", c.Interaction)
+		if c.Target != "" {
+			fmt.Fprintf(b, " %s", c.Target)
+		}
+		if c.Code != "" {
+			fmt.Fprintf(b, " %s", c.Code)
+		}
+		if c.Pointer != "" {
+			fmt.Fprintf(b, " `%s`", c.Pointer)
+		}
+		fmt.Fprintf(b, ": %s.\n", c.Message)
+	}
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsightapi/jsight-schema-core/fs"
+
+	"github.com/jsightapi/jsight-api-core/catalog"
+	"github.com/jsightapi/jsight-api-core/kit"
+)
+
+const testDiffOldAPI = `JSIGHT 0.3
+
+TYPE @cat
+{
+  "id": 1,
+  "name": "Tom", // {maxLength: 10}
+  "color": "red", // {enum: @color}
+  "tags": [
+    "a"
+  ]
+}
+
+TYPE @node
+{
+  "children": [@node]
+}
+
+ENUM @color
+[
+  "red",
+  "black"
+]
+
+GET /cats/{id}
+  Query
+  {
+    "page": 1 // {optional: true}
+  }
+  200 @cat
+  404 empty
+
+POST /cats
+  Request @cat
+  200 any
+
+GET /nodes
+  200 @node
+
+DELETE /cats/{id}
+`
+
+const testDiffNewAPI = `JSIGHT 0.3
+
+TYPE @kitty
+{
+  "id": 1, // {nullable: true}
+  "name": "Tom", // {maxLength: 5}
+  "color": "red", // {enum: @color}
+  "tags": [ // {optional: true}
+    "a"
+  ],
+  "age": 2 // {min: 0}
+}
+
+TYPE @node
+{
+  "children": [@node],
+  "value": 1 // {optional: true}
+}
+
+ENUM @color
+[
+  "red",
+  "white"
+]
+
+GET /cats/{id}
+  Query
+  {
+    "page": 1,
+    "size": 2 // {optional: true}
+  }
+  200 @kitty
+  500 regex
+    /^error$/
+
+POST /cats
+  Request @kitty
+  200 any
+
+GET /nodes
+  200 @node
+
+PUT /cats/{id}
+`
+
+func Test_diffCatalogs(t *testing.T) {
+	t.Run("positive", func(t *testing.T) {
+		t.Run("no changes", func(t *testing.T) {
+			cc, err := diffCatalogs(diffCatalog(t, testDiffOldAPI), diffCatalog(t, testDiffOldAPI))
+			require.NoError(t, err)
+			assert.Empty(t, cc)
+		})
+
+		t.Run("changes", func(t *testing.T) {
+			cc, err := diffCatalogs(diffCatalog(t, testDiffOldAPI), diffCatalog(t, testDiffNewAPI))
+			require.NoError(t, err)
+
+			const (
+				getCat  = "http GET /cats/{id}"
+				postCat = "http POST /cats"
+			)
+
+			assert.Equal(t, []specChange{
+				{getCat, targetQuery, "", "/page", changeChanged, true, "the property becomes required"},
+				{getCat, targetQuery, "", "/size", changeAdded, false, "the optional property is added"},
+				{getCat, targetResponseBody, "200", "/id", changeChanged, true, "the value becomes nullable"},
+				{getCat, targetResponseBody, "200", "/name", changeChanged, false, `the rule "maxLength" is changed from 10 to 5`},
+				{getCat, targetResponseBody, "200", "/color", changeRemoved, false, `the enum value "black" is removed`},
+				{getCat, targetResponseBody, "200", "/color", changeAdded, true, `the enum value "white" is added`},
+				{getCat, targetResponseBody, "200", "/tags", changeChanged, true, "the property becomes optional"},
+				{getCat, targetResponseBody, "200", "/age", changeAdded, false, "the required property is added"},
+				{getCat, targetResponse, "404", "", changeRemoved, true, "the response is removed"},
+				{getCat, targetResponse, "500", "", changeAdded, false, "the response is added"},
+				{postCat, targetRequestBody, "", "/id", changeChanged, false, "the value becomes nullable"},
+				{postCat, targetRequestBody, "", "/name", changeChanged, true, `the rule "maxLength" is changed from 10 to 5`},
+				{postCat, targetRequestBody, "", "/color", changeRemoved, true, `the enum value "black" is removed`},
+				{postCat, targetRequestBody, "", "/color", changeAdded, false, `the enum value "white" is added`},
+				{postCat, targetRequestBody, "", "/tags", changeChanged, false, "the property becomes optional"},
+				{postCat, targetRequestBody, "", "/age", changeAdded, true, "the required property is added"},
+				{"http GET /nodes", targetResponseBody, "200", "/value", changeAdded, false, "the optional property is added"},
+				{"http DELETE /cats/{id}", "", "", "", changeRemoved, true, "the interaction is removed"},
+				{"http PUT /cats/{id}", "", "", "", changeAdded, false, "the interaction is added"},
+			}, cc)
+		})
+
+		cc := map[string]struct {
+			old      string
+			new      string
+			expected []specChange
+		}{
+			"body notation": {
+				"JSIGHT 0.3\n\nGET /cats\n  200 any\n",
+				"JSIGHT 0.3\n\nGET /cats\n  200 regex\n    /^ok$/\n",
+				[]specChange{
+					{"http GET /cats", targetResponseBody, "200", "", changeChanged, true, "the body is changed from any to regex"},
+				},
+			},
+			"regular expression": {
+				"JSIGHT 0.3\n\nTYPE @id regex\n  /^[0-9]+$/\n\nGET /cats\n  200 [@id]\n",
+				"JSIGHT 0.3\n\nTYPE @id regex\n  /^[a-z]+$/\n\nGET /cats\n  200 [@id]\n",
+				[]specChange{
+					{"http GET /cats", targetResponseBody, "200", "/*", changeChanged, true, "the regular expression is changed from /^[0-9]+$/ to /^[a-z]+$/"},
+				},
+			},
+			"type": {
+				"JSIGHT 0.3\n\nGET /cats\n  200\n  {\n    \"id\": 1\n  }\n",
+				"JSIGHT 0.3\n\nGET /cats\n  200\n  {\n    \"id\": \"1\"\n  }\n",
+				[]specChange{
+					{"http GET /cats", targetResponseBody, "200", "/id", changeChanged, true, "the type is changed from integer to string"},
+				},
+			},
+			"or": {
+				"JSIGHT 0.3\n\nGET /cats\n  200\n  {\n    \"id\": 1 // {or: [\"integer\", \"string\"]}\n  }\n",
+				"JSIGHT 0.3\n\nGET /cats\n  200\n  {\n    \"id\": 1 // {or: [\"integer\", \"boolean\"]}\n  }\n",
+				[]specChange{
+					{"http GET /cats", targetResponseBody, "200", "/id", changeRemoved, false, "the type string is no longer allowed"},
+					{"http GET /cats", targetResponseBody, "200", "/id", changeAdded, true, "the type boolean is allowed"},
+				},
+			},
+			"empty array allows items": {
+				"JSIGHT 0.3\n\nPOST /cats\n  Request\n  {\n    \"ids\": []\n  }\n  200\n  {\n    \"ids\": []\n  }\n",
+				"JSIGHT 0.3\n\nPOST /cats\n  Request\n  {\n    \"ids\": [1]\n  }\n  200\n  {\n    \"ids\": [1]\n  }\n",
+				[]specChange{
+					{"http POST /cats", targetRequestBody, "", "/ids", changeChanged, false, "the array items are allowed"},
+					{"http POST /cats", targetResponseBody, "200", "/ids", changeChanged, true, "the array items are allowed"},
+				},
+			},
+			"array becomes empty": {
+				"JSIGHT 0.3\n\nPOST /cats\n  Request\n  {\n    \"ids\": [1]\n  }\n  200\n  {\n    \"ids\": [1]\n  }\n",
+				"JSIGHT 0.3\n\nPOST /cats\n  Request\n  {\n    \"ids\": []\n  }\n  200\n  {\n    \"ids\": []\n  }\n",
+				[]specChange{
+					{"http POST /cats", targetRequestBody, "", "/ids", changeChanged, true, "the array must be empty"},
+					{"http POST /cats", targetResponseBody, "200", "/ids", changeChanged, false, "the array must be empty"},
+				},
+			},
+			"JSON-RPC": {
+				"JSIGHT 0.3\n\nURL /api\n  Protocol json-rpc-2.0\n  Method foo\n    Params\n    {\n      \"a\": 1\n    }\n    Result\n    {\n      \"b\": 1 // {min: 0}\n    }\n",
+				"JSIGHT 0.3\n\nURL /api\n  Protocol json-rpc-2.0\n  Method foo\n    Params\n    {\n      \"a\": 1, // {min: 0}\n      \"c\": 1\n    }\n    Result\n    {\n      \"b\": 1\n    }\n",
+				[]specChange{
+					{"json-rpc-2.0 foo /api", targetParams, "", "/a", changeChanged, true, `the rule "min" is changed from none to 0`},
+					{"json-rpc-2.0 foo /api", targetParams, "", "/c", changeAdded, true, "the required property is added"},
+					{"json-rpc-2.0 foo /api", targetResult, "", "/b", changeChanged, true, `the rule "min" is changed from 0 to none`},
+				},
+			},
+		}
+
+		for n, c := range cc {
+			t.Run(n, func(t *testing.T) {
+				actual, err := diffCatalogs(diffCatalog(t, c.old), diffCatalog(t, c.new))
+				require.NoError(t, err)
+				assert.Equal(t, c.expected, actual)
+			})
+		}
+	})
+}
+
+func Test_specChangesMarkdown(t *testing.T) {
+	t.Run("no changes", func(t *testing.T) {
+		assert.Equal(t, "# API changes\n\nNo changes.\n", specChangesMarkdown(nil))
+	})
+
+	t.Run("changes", func(t *testing.T) {
+		assert.Equal(t, "# API changes\n\n"+
+			"Breaking changes: 1, non-breaking changes: 1.\n\n"+
+			"## Breaking changes\n\n"+
+			"- `http GET /cats` response.body 200 `/id`: the required property is removed.\n\n"+
+			"## Non-breaking changes\n\n"+
+			"- `http GET /dogs`: the interaction is added.\n",
+			specChangesMarkdown([]specChange{
+				{"http GET /dogs", "", "", "", changeAdded, false, "the interaction is added"},
+				{"http GET /cats", targetResponseBody, "200", "/id", changeRemoved, true, "the required property is removed"},
+			}),
+		)
+	})
+}
+
+func diffCatalog(t *testing.T, jsight string) *catalog.Catalog {
+	jAPI, je := kit.NewJApiFromFile(fs.NewFile("root", []byte(jsight)))
+	require.Nil(t, je)
+	return jAPI.Catalog()
+}
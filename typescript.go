@@ -0,0 +1,663 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	schema "github.com/jsightapi/jsight-schema-core"
+	"github.com/jsightapi/jsight-schema-core/panics"
+
+	"github.com/jsightapi/jsight-api-core/catalog"
+	"github.com/jsightapi/jsight-api-core/kit"
+	"github.com/jsightapi/jsight-api-core/notation"
+)
+
+func typescriptCode(jAPI kit.JApi, client bool) ([]byte, error) {
+	code, err := newTypeScript(jAPI.Catalog(), client)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(code), nil
+}
+
+// newTypeScript generates TypeScript declarations for the API:
+//
+//   - a union of literal types for every enum,
+//   - an interface (or a type alias) for every user type,
+//   - types of path variables, query, request and response bodies for every
+//     HTTP interaction, e.g. GetCatsIdPath, GetCatsIdQuery, GetCatsIdRequest
+//     and GetCatsIdResponse200. The OperationId of the interaction is used as
+//     the prefix if it is specified. A number is appended to the name if it
+//     is already declared, e.g. GetCatsIdResponse2002.
+//
+// Enums and user types get a number too if their names clash with each other,
+// e.g. @cat-info and @cat_info, or with the names of the client code.
+//
+// If client is true, the Operations interface keyed by interaction IDs and the
+// Client class which sends requests with fetch are added as well.
+func newTypeScript(c *catalog.Catalog, client bool) (code string, err error) {
+	defer func() {
+		err = panics.Handle(recover(), err)
+	}()
+
+	g := tsGenerator{
+		declared: map[string]bool{},
+		types:    map[string]string{},
+	}
+	for _, n := range tsFixedNames {
+		g.declared[n] = true
+	}
+
+	// Enums and user types are declared before their code is generated, so
+	// references to types which come later get the declared names too.
+	c.UserEnums.EachSafe(func(k string, _ *catalog.UserRule) {
+		g.types[k] = g.declare(tsName(k))
+	})
+	c.UserTypes.EachSafe(func(k string, _ *catalog.UserType) {
+		g.types[k] = g.declare(tsName(k))
+	})
+
+	g.line("// Code generated by JSight Server. DO NOT EDIT.")
+
+	c.UserEnums.EachSafe(func(k string, v *catalog.UserRule) {
+		g.enum(k, v)
+	})
+
+	err = c.UserTypes.Each(func(k string, v *catalog.UserType) error {
+		if err := g.userType(k, v); err != nil {
+			return fmt.Errorf("type %q: %w", k, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var oo []tsOperation
+	err = c.Interactions.Each(func(_ catalog.InteractionID, v catalog.Interaction) error {
+		h, ok := v.(*catalog.HTTPInteraction)
+		if !ok {
+			return nil
+		}
+
+		o, err := g.interaction(h)
+		if err != nil {
+			return fmt.Errorf("%s: %w", h.Id, err)
+		}
+		oo = append(oo, o)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if client {
+		g.client(oo)
+	}
+
+	return g.b.String(), nil
+}
+
+// tsOperation describes the HTTP interaction for the client.
+type tsOperation struct {
+	id    string
+	path  string
+	query string
+
+	// queryRequired is true if the query has required parameters.
+	queryRequired bool
+
+	request string
+
+	// textRequest is true if the request body is a plain text.
+	textRequest bool
+
+	responses []tsResponse
+}
+
+type tsResponse struct {
+	code string
+	body string
+}
+
+// tsFixedNames are the names which the client code declares. They are
+// reserved even without the client, so the names of types don't depend on it.
+var tsFixedNames = []string{"Operations", "OperationId", "Client", "textRequests"}
+
+type tsGenerator struct {
+	b strings.Builder
+
+	// declared names of the declared types.
+	declared map[string]bool
+
+	// types the TypeScript names of enums and user types by their names with
+	// "@".
+	types map[string]string
+}
+
+func (g *tsGenerator) line(s string) {
+	g.b.WriteString(s)
+	g.b.WriteByte('\n')
+}
+
+// declare returns the unique name of the type, the number is appended to the
+// name if it is already declared.
+func (g *tsGenerator) declare(name string) string {
+	n := name
+	for i := 2; g.declared[n]; i++ {
+		n = name + strconv.Itoa(i)
+	}
+	g.declared[n] = true
+	return n
+}
+
+func (g *tsGenerator) doc(indent, s string) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return
+	}
+	// The text must not close the comment.
+	s = strings.ReplaceAll(s, "*/", "*\\/")
+
+	ll := strings.Split(s, "\n")
+	if len(ll) == 1 {
+		g.line(indent + "/** " + ll[0] + " */")
+		return
+	}
+
+	g.line(indent + "/**")
+	for _, l := range ll {
+		g.line(strings.TrimRight(indent+" * "+strings.TrimSpace(l), " "))
+	}
+	g.line(indent + " */")
+}
+
+func (g *tsGenerator) enum(name string, r *catalog.UserRule) {
+	vv := make([]string, 0, len(r.Value.Children))
+	for _, i := range r.Value.Children {
+		vv = append(vv, tsRuleLiteral(i))
+	}
+
+	g.line("")
+	g.doc("", description(r.Description, r.Annotation))
+	g.line(fmt.Sprintf("export type %s = %s;", g.types[name], tsUnion(vv)))
+}
+
+func (g *tsGenerator) userType(name string, ut *catalog.UserType) error {
+	typeName := g.types[name]
+
+	g.line("")
+	g.doc("", description(ut.Description, ut.Annotation))
+
+	switch s := ut.Schema.(type) {
+	case *catalog.ExchangeJSightSchema:
+		node, err := s.GetAST()
+		if err != nil {
+			return err
+		}
+
+		if node.TokenType == schema.TokenTypeObject && !ruleIsTrue(node, "nullable") {
+			if _, ok := rule(node, "or"); !ok {
+				extends := ""
+				if bases := g.allOf(node); len(bases) != 0 {
+					extends = " extends " + strings.Join(bases, ", ")
+				}
+				g.line(fmt.Sprintf("export interface %s%s %s", typeName, extends, g.object(node, "")))
+				return nil
+			}
+		}
+		g.line(fmt.Sprintf("export type %s = %s;", typeName, g.tsType(node, "")))
+
+	case *catalog.ExchangeRegexSchema:
+		g.line(fmt.Sprintf("export type %s = string;", typeName))
+
+	default:
+		g.line(fmt.Sprintf("export type %s = unknown;", typeName))
+	}
+	return nil
+}
+
+func (g *tsGenerator) interaction(h *catalog.HTTPInteraction) (tsOperation, error) {
+	prefix := tsName(strings.ToLower(h.HttpMethod.String()) + " " + h.Path().String())
+	if h.OperationId != nil && *h.OperationId != "" {
+		prefix = tsName(*h.OperationId)
+	}
+
+	o := tsOperation{id: h.Id}
+
+	declare := func(suffix, typ string) string {
+		name := g.declare(prefix + suffix)
+		g.line(fmt.Sprintf("export type %s = %s;", name, typ))
+		return name
+	}
+
+	g.line("")
+	g.line("// " + h.HttpMethod.String() + " " + h.Path().String())
+
+	if h.PathVariables != nil && h.PathVariables.Schema != nil {
+		node, err := h.PathVariables.Schema.GetAST()
+		if err != nil {
+			return o, err
+		}
+		o.path = declare("Path", g.tsType(node, ""))
+	}
+
+	if h.Query != nil && h.Query.Schema != nil {
+		node, err := h.Query.Schema.GetAST()
+		if err != nil {
+			return o, err
+		}
+		o.query = declare("Query", g.tsType(node, ""))
+		for _, p := range node.Children {
+			if !ruleIsTrue(p, "optional") {
+				o.queryRequired = true
+			}
+		}
+	}
+
+	if h.Request != nil && h.Request.HTTPRequestBody != nil {
+		typ, ok, err := g.body(h.Request.HTTPRequestBody.Schema)
+		if err != nil {
+			return o, err
+		}
+		if ok {
+			o.request = declare("Request", typ)
+			_, o.textRequest = h.Request.HTTPRequestBody.Schema.(*catalog.ExchangeRegexSchema)
+		}
+	}
+
+	for _, r := range h.Responses {
+		resp := tsResponse{code: r.Code, body: "undefined"}
+		if r.Body != nil {
+			typ, ok, err := g.body(r.Body.Schema)
+			if err != nil {
+				return o, err
+			}
+			if ok {
+				resp.body = declare("Response"+r.Code, typ)
+			}
+		}
+		o.responses = append(o.responses, resp)
+	}
+
+	return o, nil
+}
+
+func (g *tsGenerator) client(oo []tsOperation) {
+	g.line("")
+	g.line("export interface Operations {")
+	for _, o := range oo {
+		g.line(fmt.Sprintf("  %s: {", tsKey(o.id)))
+
+		g.line("    params: {")
+		if o.path != "" {
+			g.line("      path: " + o.path + ";")
+		}
+		if o.query != "" {
+			optional := "?"
+			if o.queryRequired {
+				optional = ""
+			}
+			g.line("      query" + optional + ": " + o.query + ";")
+		}
+		if o.request != "" {
+			g.line("      body: " + o.request + ";")
+		}
+		g.line("    };")
+
+		if len(o.responses) == 0 {
+			g.line("    response: { status: number; body: unknown };")
+		} else {
+			g.line("    response:")
+			for i, r := range o.responses {
+				end := ""
+				if i == len(o.responses)-1 {
+					end = ";"
+				}
+				g.line(fmt.Sprintf("      | { status: %s; body: %s }%s", r.code, r.body, end))
+			}
+		}
+
+		g.line("  };")
+	}
+	g.line("}")
+
+	var text []string
+	for _, o := range oo {
+		if o.textRequest {
+			text = append(text, tsKey(o.id))
+		}
+	}
+
+	g.line("")
+	g.line("export type OperationId = keyof Operations;")
+	g.line("")
+	g.line("// textRequests the operations which send the request body as a plain text.")
+	g.line("const textRequests: ReadonlySet<string> = new Set<string>([" + strings.Join(text, ", ") + "]);")
+	g.b.WriteString(tsClient)
+}
+
+const tsClient = `
+export class Client {
+  constructor(
+    private readonly baseUrl: string,
+    private readonly init: RequestInit = {},
+  ) {}
+
+  async request<K extends OperationId>(
+    id: K,
+    params: Operations[K]["params"],
+  ): Promise<Operations[K]["response"]> {
+    const p = params as {
+      path?: Record<string, unknown>;
+      query?: Record<string, unknown>;
+      body?: unknown;
+    };
+    const [, method, template] = id.split(" ");
+
+    const path = template.replace(/{([^}]+)}/g, (_, name: string) =>
+      encodeURIComponent(String(p.path?.[name])),
+    );
+
+    const query = new URLSearchParams();
+    for (const [k, v] of Object.entries(p.query ?? {})) {
+      if (v !== undefined) {
+        query.append(k, String(v));
+      }
+    }
+    const qs = query.toString();
+
+    const headers = new Headers(this.init.headers);
+    let body: string | undefined;
+    if (p.body !== undefined) {
+      const text = textRequests.has(id);
+      headers.set("Content-Type", text ? "text/plain" : "application/json");
+      body = text ? String(p.body) : JSON.stringify(p.body);
+    }
+
+    const res = await fetch(this.baseUrl + path + (qs === "" ? "" : "?" + qs), {
+      ...this.init,
+      method,
+      headers,
+      body,
+    });
+
+    const text = await res.text();
+    const json = (res.headers.get("Content-Type") ?? "").includes("json");
+    return {
+      status: res.status,
+      body: text === "" ? undefined : json ? JSON.parse(text) : text,
+    } as Operations[K]["response"];
+  }
+}
+`
+
+// body returns the TypeScript type of the body. Empty bodies have no type.
+func (g *tsGenerator) body(es catalog.ExchangeSchema) (string, bool, error) {
+	switch s := es.(type) {
+	case *catalog.ExchangeJSightSchema:
+		node, err := s.GetAST()
+		if err != nil {
+			return "", false, err
+		}
+		return g.tsType(node, ""), true, nil
+
+	case *catalog.ExchangeRegexSchema:
+		return "string", true, nil
+
+	default:
+		if es != nil && es.Notation() == notation.SchemaNotationAny {
+			return "unknown", true, nil
+		}
+		return "", false, nil
+	}
+}
+
+// tsType returns the TypeScript type of the AST node. Nested objects are
+// indented with the given indent.
+func (g *tsGenerator) tsType(node schema.ASTNode, indent string) string {
+	t := g.nonNullType(node, indent)
+	if ruleIsTrue(node, "nullable") && t != "null" && t != "unknown" {
+		t += " | null"
+	}
+	return t
+}
+
+func (g *tsGenerator) nonNullType(node schema.ASTNode, indent string) string {
+	if or, ok := rule(node, "or"); ok {
+		tt := make([]string, 0, len(or.Items))
+		for _, i := range or.Items {
+			tt = append(tt, g.tsType(orItemNode(i), indent))
+		}
+		return tsUnion(tt)
+	}
+
+	if enum, ok := rule(node, "enum"); ok {
+		if enum.TokenType != schema.TokenTypeArray {
+			return g.userTypeName(enum.Value)
+		}
+
+		tt := make([]string, 0, len(enum.Items))
+		for _, i := range enum.Items {
+			tt = append(tt, tsLiteral(i.TokenType, i.Value))
+		}
+		return tsUnion(tt)
+	}
+
+	if ruleIsTrue(node, "const") {
+		return tsLiteral(node.TokenType, node.Value)
+	}
+
+	switch schema.SchemaType(node.SchemaType) { //nolint:exhaustive // Other types are unknown.
+	case schema.SchemaTypeObject:
+		o := g.object(node, indent)
+		if bases := g.allOf(node); len(bases) != 0 {
+			return strings.Join(bases, " & ") + " & " + o
+		}
+		return o
+
+	case schema.SchemaTypeArray:
+		return g.array(node, indent)
+
+	case schema.SchemaTypeString, schema.SchemaTypeEmail, schema.SchemaTypeURI, schema.SchemaTypeUUID,
+		schema.SchemaTypeDate, schema.SchemaTypeDateTime:
+		return "string"
+
+	case schema.SchemaTypeInteger, schema.SchemaTypeFloat, schema.SchemaTypeDecimal:
+		return "number"
+
+	case schema.SchemaTypeBoolean:
+		return "boolean"
+
+	case schema.SchemaTypeNull:
+		return "null"
+
+	default:
+		if strings.HasPrefix(node.SchemaType, "@") {
+			return g.userTypeName(node.SchemaType)
+		}
+		return "unknown"
+	}
+}
+
+// object returns the object type. The properties are listed one per line,
+// additional properties are described with the index signature.
+func (g *tsGenerator) object(node schema.ASTNode, indent string) string {
+	inner := indent + "  "
+
+	var b strings.Builder
+	b.WriteString("{\n")
+
+	var index []string
+	hasProps := false
+	for _, p := range node.Children {
+		if p.InheritedFrom != "" {
+			continue
+		}
+		if p.IsKeyShortcut {
+			index = append(index, g.tsType(p, inner))
+			continue
+		}
+		hasProps = true
+
+		writeTSDoc(&b, inner, p.Comment)
+
+		optional := ""
+		if ruleIsTrue(p, "optional") {
+			optional = "?"
+		}
+		fmt.Fprintf(&b, "%s%s%s: %s;\n", inner, tsKey(p.Key), optional, g.tsType(p, inner))
+	}
+
+	if additional, ok := rule(node, "additionalProperties"); ok && additional.Value != "false" {
+		typ := "unknown"
+		if additional.Value != "true" && additional.Value != string(schema.SchemaTypeAny) {
+			typ = g.tsType(typeNode(additional.Value), inner)
+		}
+		index = append(index, typ)
+	}
+
+	if len(index) != 0 {
+		// The declared properties must conform to the index signature.
+		typ := tsUnion(index)
+		if hasProps {
+			typ = "unknown"
+		}
+		fmt.Fprintf(&b, "%s[key: string]: %s;\n", inner, typ)
+	}
+
+	if !hasProps && len(index) == 0 {
+		return "Record<string, never>"
+	}
+
+	b.WriteString(indent + "}")
+	return b.String()
+}
+
+// allOf returns the TypeScript names of the base types of the object.
+func (g *tsGenerator) allOf(node schema.ASTNode) []string {
+	allOf, ok := rule(node, "allOf")
+	if !ok {
+		return nil
+	}
+
+	if allOf.TokenType != schema.TokenTypeArray {
+		return []string{g.userTypeName(allOf.Value)}
+	}
+
+	nn := make([]string, 0, len(allOf.Items))
+	for _, i := range allOf.Items {
+		nn = append(nn, g.userTypeName(i.Value))
+	}
+	return nn
+}
+
+// userTypeName returns the declared TypeScript name of the enum or the user
+// type.
+func (g *tsGenerator) userTypeName(name string) string {
+	if n, ok := g.types[name]; ok {
+		return n
+	}
+	return tsName(name)
+}
+
+func (g *tsGenerator) array(node schema.ASTNode, indent string) string {
+	if len(node.Children) == 0 {
+		return "[]"
+	}
+
+	tt := make([]string, 0, len(node.Children))
+	for _, c := range node.Children {
+		tt = append(tt, g.tsType(c, indent))
+	}
+
+	t := tsUnion(tt)
+	if strings.ContainsAny(t, "|&") {
+		t = "(" + t + ")"
+	}
+	return t + "[]"
+}
+
+func writeTSDoc(b *strings.Builder, indent, s string) {
+	g := tsGenerator{}
+	g.doc(indent, s)
+	b.WriteString(g.b.String())
+}
+
+// tsUnion joins the types skipping the duplicates.
+func tsUnion(tt []string) string {
+	uu := make([]string, 0, len(tt))
+	for _, t := range tt {
+		if !containsString(uu, t) {
+			uu = append(uu, t)
+		}
+	}
+	if len(uu) == 0 {
+		return "never"
+	}
+	return strings.Join(uu, " | ")
+}
+
+func tsLiteral(t schema.TokenType, v string) string {
+	if t == schema.TokenTypeString {
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+	return v
+}
+
+func tsRuleLiteral(r catalog.Rule) string {
+	if r.TokenType == catalog.RuleTokenTypeString {
+		return tsLiteral(schema.TokenTypeString, r.ScalarValue)
+	}
+	return r.ScalarValue
+}
+
+var tsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// tsKey returns the property key, quoted if it isn't an identifier.
+func tsKey(k string) string {
+	if tsIdentifier.MatchString(k) {
+		return k
+	}
+	b, _ := json.Marshal(k)
+	return string(b)
+}
+
+// tsName converts the JSight name to a TypeScript identifier in PascalCase,
+// e.g. "@cat-info" becomes "CatInfo".
+func tsName(s string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+
+	n := b.String()
+	if n == "" || unicode.IsDigit(rune(n[0])) {
+		n = "T" + n
+	}
+	return n
+}
+
+// description returns the description or the annotation if the description is
+// empty.
+func description(d, annotation string) string {
+	if d != "" {
+		return d
+	}
+	return annotation
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jsightapi/jsight-schema-core/fs"
+
+	"github.com/jsightapi/jsight-api-core/kit"
+)
+
+const testTypeScriptAPI = `JSIGHT 0.3
+
+ENUM @colors // Colors.
+[
+  "red",
+  1,
+  null
+]
+
+TYPE @named // A named thing.
+{
+  "name": "Tom", // {minLength: 1}
+  "id": 1 // {optional: true}
+}
+
+TYPE @cat
+{ // {allOf: "@named"}
+  "kind": "cat", // The kind.
+  "color": "red", // {enum: @colors}
+  "size": "s", // {enum: ["s", "m"], nullable: true}
+  "owner": @named, // {nullable: true}
+  "tags": [
+    @named | @cat,
+    "str"
+  ],
+  "extra": {}, // {additionalProperties: "string"}
+  "meta": { // {additionalProperties: true}
+    "a-b": 1
+  },
+  "any": 1 // {type: "any"}
+}
+
+TYPE @code regex
+  /^[A-Z]+$/
+
+GET /cats/{id}
+  Path
+  {
+    "id": 1
+  }
+  Query
+  {
+    "limit": 1 // {optional: true}
+  }
+  200 [@cat]
+  404 regex
+    /^not found$/
+
+POST /cats
+  OperationId "createCat"
+  Request @cat
+  204 empty
+`
+
+func Test_typescriptCode(t *testing.T) {
+	t.Run("positive", func(t *testing.T) {
+		t.Run("types", func(t *testing.T) {
+			code := typescriptOf(t, testTypeScriptAPI, false)
+
+			assert.Equal(t, `// Code generated by JSight Server. DO NOT EDIT.
+
+/** Colors. */
+export type Colors = "red" | 1 | null;
+
+/** A named thing. */
+export interface Named {
+  name: string;
+  id?: number;
+}
+
+export interface Cat extends Named {
+  /** The kind. */
+  kind: string;
+  color: Colors;
+  size: "s" | "m" | null;
+  owner: Named | null;
+  tags: (Named | Cat | string)[];
+  extra: {
+    [key: string]: string;
+  };
+  meta: {
+    "a-b": number;
+    [key: string]: unknown;
+  };
+  any: unknown;
+}
+
+export type Code = string;
+
+// GET /cats/{id}
+export type GetCatsIdPath = {
+  id: number;
+};
+export type GetCatsIdQuery = {
+  limit?: number;
+};
+export type GetCatsIdResponse200 = Cat[];
+export type GetCatsIdResponse404 = string;
+
+// POST /cats
+export type CreateCatRequest = Cat;
+`, code)
+		})
+
+		t.Run("client", func(t *testing.T) {
+			code := typescriptOf(t, testTypeScriptAPI, true)
+
+			assert.Contains(t, code, `
+export interface Operations {
+  "http GET /cats/{id}": {
+    params: {
+      path: GetCatsIdPath;
+      query?: GetCatsIdQuery;
+    };
+    response:
+      | { status: 200; body: GetCatsIdResponse200 }
+      | { status: 404; body: GetCatsIdResponse404 };
+  };
+  "http POST /cats": {
+    params: {
+      body: CreateCatRequest;
+    };
+    response:
+      | { status: 204; body: undefined };
+  };
+}
+`)
+			assert.Contains(t, code, "const textRequests: ReadonlySet<string> = new Set<string>([]);")
+			assert.Contains(t, code, "export class Client {")
+		})
+
+		t.Run("colliding names", func(t *testing.T) {
+			code := typescriptOf(t, `JSIGHT 0.3
+
+TYPE @getCatsIdQuery
+{
+  "id": 1
+}
+
+GET /cats-id
+  200
+    {
+      "id": 1
+    }
+  200 any
+
+GET /cats_id
+  Query
+  {
+    "limit": 10
+  }
+  200
+    {
+      "id": 1
+    }
+`, true)
+
+			declared := map[string]bool{}
+			for _, m := range regexp.MustCompile(`(?m)^export (?:type|interface|class) (\w+)`).FindAllStringSubmatch(code, -1) {
+				assert.False(t, declared[m[1]], "%s is declared twice", m[1])
+				declared[m[1]] = true
+			}
+
+			assert.Contains(t, code, "export type GetCatsIdResponse200 = {")
+			assert.Contains(t, code, "export type GetCatsIdResponse2002 = unknown;")
+			assert.Contains(t, code, "export type GetCatsIdQuery2 = {")
+			assert.Contains(t, code, "export type GetCatsIdResponse2003 = {")
+			assert.Contains(t, code, `      | { status: 200; body: GetCatsIdResponse2002 };`)
+		})
+
+		t.Run("fixed names", func(t *testing.T) {
+			code := typescriptOf(t, `JSIGHT 0.3
+
+TYPE @client
+{
+  "id": 1
+}
+
+TYPE @operations
+{
+  "client": @client
+}
+
+ENUM @operationId
+[
+  "a"
+]
+
+GET /operations
+  200 @operations
+`, true)
+
+			assert.Contains(t, code, "export interface Client2 {")
+			assert.Contains(t, code, "export interface Operations2 {\n  client: Client2;\n}")
+			assert.Contains(t, code, `export type OperationId2 = "a";`)
+			assert.Contains(t, code, "export type GetOperationsResponse200 = Operations2;")
+			assert.Contains(t, code, "export interface Operations {")
+			assert.Contains(t, code, "export type OperationId = keyof Operations;")
+			assert.Contains(t, code, "export class Client {")
+		})
+
+		t.Run("similar type names", func(t *testing.T) {
+			code := typescriptOf(t, `JSIGHT 0.3
+
+TYPE @cat-info
+{
+  "id": 1,
+  "next": @cat_info
+}
+
+TYPE @cat_info
+{
+  "name": "Tom"
+}
+
+GET /cats
+  200 @cat-info
+`, false)
+
+			assert.Contains(t, code, "export interface CatInfo {\n  id: number;\n  next: CatInfo2;\n}")
+			assert.Contains(t, code, "export interface CatInfo2 {\n  name: string;\n}")
+			assert.Contains(t, code, "export type GetCatsResponse200 = CatInfo;")
+		})
+
+		t.Run("comment end in doc", func(t *testing.T) {
+			code := typescriptOf(t, `JSIGHT 0.3
+
+TYPE @cat // The end */ of the comment.
+{
+  "id": 1 // The id */ too.
+}
+`, false)
+
+			assert.Contains(t, code, "/** The end *\\/ of the comment. */")
+			assert.Contains(t, code, "/** The id *\\/ too. */")
+			assert.NotContains(t, code, "end */")
+		})
+
+		t.Run("names", func(t *testing.T) {
+			cc := map[string]string{
+				"@cat":           "Cat",
+				"@cat-info_v2":   "CatInfoV2",
+				"get /cats/{id}": "GetCatsId",
+				"@1st":           "T1st",
+			}
+
+			for given, expected := range cc {
+				t.Run(given, func(t *testing.T) {
+					assert.Equal(t, expected, tsName(given))
+				})
+			}
+		})
+	})
+}
+
+func typescriptOf(t *testing.T, jsight string, client bool) string {
+	jAPI, je := kit.NewJApiFromFile(fs.NewFile("root", []byte(jsight)))
+	require.Nil(t, je)
+
+	b, err := typescriptCode(jAPI, client)
+	require.NoError(t, err)
+	return string(b)
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/jsightapi/jsight-api-core/catalog"
+	"github.com/jsightapi/jsight-api-core/kit"
+)
+
+// Payload validation targets.
+const (
+	targetRequestBody     = "request.body"
+	targetRequestHeaders  = "request.headers"
+	targetQuery           = "query"
+	targetPathVariables   = "path"
+	targetResponseBody    = "response.body"
+	targetResponseHeaders = "response.headers"
+)
+
+// validatePayloadRequest is a body of the /validate-payload request.
+type validatePayloadRequest struct {
+	// JSight the JSight code of the API.
+	JSight string `json:"jsight"`
+
+	// Interaction an interaction ID, e.g. "http GET /cats/{id}".
+	Interaction string `json:"interaction"`
+
+	// Target a part of the interaction the payload is validated against.
+	Target string `json:"target"`
+
+	// Code a response code. Required for the response targets.
+	Code string `json:"code"`
+
+	// Payload a JSON document to validate. For bodies described by the regular
+	// expression, this is a JSON string with the body text.
+	Payload json.RawMessage `json:"payload"`
+}
+
+type validatePayloadResponse struct {
+	Valid  bool           `json:"valid"`
+	Errors []payloadError `json:"errors"`
+}
+
+func validatePayload(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s %s", r.Method, r.URL.Path)
+
+	if getBoolEnv("JSIGHT_SERVER_CORS") {
+		cors(w)
+	}
+
+	wr := httpResponseWriter{writer: w}
+
+	switch r.Method {
+	case http.MethodOptions:
+
+	case http.MethodPost:
+		validatePayloadPOST(wr, r)
+		return
+
+	default:
+		wr.errorStr(errorCodePOSTRequired, "HTTP POST request required")
+		return
+	}
+}
+
+func validatePayloadPOST(wr httpResponseWriter, r *http.Request) {
+	var req validatePayloadRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxProjectSize)).Decode(&req); err != nil {
+		wr.error(newRequestError(errorCodeInvalidRequest, "invalid request: %w", err))
+		return
+	}
+
+	jAPI, jErr := newSingleFileProject([]byte(req.JSight)).build()
+	if jErr != nil {
+		wr.error(jErr)
+		return
+	}
+
+	ee, err := validateInteractionPayload(jAPI, req)
+	if err != nil {
+		wr.error(err)
+		return
+	}
+
+	if ee == nil {
+		ee = []payloadError{}
+	}
+	b, err := json.Marshal(validatePayloadResponse{
+		Valid:  len(ee) == 0,
+		Errors: ee,
+	})
+	if err != nil {
+		wr.internalServerError(err)
+		return
+	}
+
+	wr.json(b)
+}
+
+func validateInteractionPayload(jAPI kit.JApi, req validatePayloadRequest) ([]payloadError, error) {
+	i, err := findHTTPInteraction(jAPI.Catalog(), req.Interaction)
+	if err != nil {
+		return nil, err
+	}
+
+	switch req.Target {
+	case targetResponseBody, targetResponseHeaders:
+		return validateResponsePayload(i, req)
+	}
+
+	es, stringScalars, err := interactionSchema(i, req.Target)
+	if err != nil {
+		return nil, err
+	}
+	if es == nil {
+		return nil, newRequestError(errorCodeInvalidTarget, "the %q target is not described in the interaction", req.Target)
+	}
+
+	payload, err := payloadBytes(es, req.Payload)
+	if err != nil {
+		return nil, err
+	}
+	return validateExchangeSchema(es, payload, stringScalars)
+}
+
+// validateResponsePayload validates the payload against responses with the
+// requested code. The payload is valid if it conforms to any of them, otherwise
+// violations of the first one are returned.
+func validateResponsePayload(i *catalog.HTTPInteraction, req validatePayloadRequest) ([]payloadError, error) {
+	if req.Code == "" {
+		return nil, newRequestError(errorCodeParameterRequired, `you must specify the "code" parameter`)
+	}
+
+	var first []payloadError
+	found := false
+	for _, resp := range i.Responses {
+		if resp.Code != req.Code {
+			continue
+		}
+
+		var es catalog.ExchangeSchema
+		if req.Target == targetResponseHeaders {
+			if resp.Headers == nil {
+				continue
+			}
+			es = resp.Headers.Schema
+		} else if resp.Body != nil {
+			es = resp.Body.Schema
+		}
+		if es == nil {
+			continue
+		}
+
+		payload, err := payloadBytes(es, req.Payload)
+		if err != nil {
+			return nil, err
+		}
+
+		ee, err := validateExchangeSchema(es, payload, req.Target == targetResponseHeaders)
+		if err != nil {
+			return nil, err
+		}
+		if len(ee) == 0 {
+			return nil, nil
+		}
+		if !found {
+			first = ee
+			found = true
+		}
+	}
+
+	if !found {
+		return nil, newRequestError(errorCodeInvalidTarget, "the %q target of the response %s is not described in the interaction", req.Target, req.Code)
+	}
+	return first, nil
+}
+
+func findHTTPInteraction(c *catalog.Catalog, id string) (*catalog.HTTPInteraction, error) {
+	item, ok := c.Interactions.Find(func(k catalog.InteractionID, _ catalog.Interaction) bool {
+		return k.String() == id
+	})
+	if !ok {
+		return nil, newRequestError(errorCodeInteractionNotFound, "the interaction %q not found", id)
+	}
+
+	i, ok := item.Value.(*catalog.HTTPInteraction)
+	if !ok {
+		return nil, newRequestError(errorCodeInteractionNotFound, "the interaction %q is not an HTTP interaction", id)
+	}
+	return i, nil
+}
+
+// interactionSchema returns the schema of the request part of the interaction.
+// Headers, query and path variables are strings on the wire, so their scalars
+// can be passed as strings.
+func interactionSchema(i *catalog.HTTPInteraction, target string) (catalog.ExchangeSchema, bool, error) {
+	switch target {
+	case targetRequestBody:
+		if i.Request == nil || i.Request.HTTPRequestBody == nil {
+			return nil, false, nil
+		}
+		return i.Request.HTTPRequestBody.Schema, false, nil
+
+	case targetRequestHeaders:
+		if i.Request == nil || i.Request.HTTPRequestHeaders == nil || i.Request.HTTPRequestHeaders.Schema == nil {
+			return nil, true, nil
+		}
+		return i.Request.HTTPRequestHeaders.Schema, true, nil
+
+	case targetQuery:
+		if i.Query == nil || i.Query.Schema == nil {
+			return nil, true, nil
+		}
+		return i.Query.Schema, true, nil
+
+	case targetPathVariables:
+		if i.PathVariables == nil || i.PathVariables.Schema == nil {
+			return nil, true, nil
+		}
+		return i.PathVariables.Schema, true, nil
+
+	case "":
+		return nil, false, newRequestError(errorCodeParameterRequired, `you must specify the "target" parameter`)
+
+	default:
+		return nil, false, newRequestError(errorCodeInvalidTarget, "unknown target %q", target)
+	}
+}
+
+// payloadBytes converts the payload from the request into the wire
+// representation expected by the schema.
+func payloadBytes(es catalog.ExchangeSchema, payload json.RawMessage) ([]byte, error) {
+	if _, ok := es.(*catalog.ExchangeRegexSchema); !ok {
+		return payload, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(payload, &s); err != nil {
+		return nil, newRequestError(errorCodeInvalidTarget, "the payload must be a JSON string for the regular expression schema")
+	}
+	return []byte(s), nil
+}
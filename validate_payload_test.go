@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_validatePayload(t *testing.T) {
+	cc := map[string]testCase{
+		http.MethodOptions: {
+			func(t *testing.T) *http.Request {
+				r, err := http.NewRequest(http.MethodOptions, "/", http.NoBody)
+				require.NoError(t, err)
+				return r
+			},
+			func(t *testing.T, r *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, r.Code)
+			},
+		},
+
+		"POST, valid request body": {
+			newValidatePayloadRequest(validatePayloadRequest{
+				Interaction: "http POST /cats/{id}",
+				Target:      targetRequestBody,
+				Payload:     json.RawMessage(`{"id": 1, "name": "Tom", "email": "tom@cats.com", "color": "red", "size": "m", "tags": ["a"], "owner": null, "pet": 1}`),
+			}),
+			func(t *testing.T, r *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, r.Code)
+				assert.Equal(t, "application/json; charset=utf-8", r.Header().Get("Content-Type"))
+				assert.Equal(t, `{"valid":true,"errors":[]}`, r.Body.String())
+			},
+		},
+
+		"POST, invalid query": {
+			newValidatePayloadRequest(validatePayloadRequest{
+				Interaction: "http POST /cats/{id}",
+				Target:      targetQuery,
+				Payload:     json.RawMessage(`{"page": "0", "active": "true", "color": "red"}`),
+			}),
+			func(t *testing.T, r *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, r.Code)
+				assert.Equal(t, `{"valid":false,"errors":[{"pointer":"/page","message":"the value violates the rule \"min\": 1"}]}`, r.Body.String())
+			},
+		},
+
+		"POST, response body": {
+			newValidatePayloadRequest(validatePayloadRequest{
+				Interaction: "http POST /cats/{id}",
+				Target:      targetResponseBody,
+				Code:        "200",
+				Payload:     json.RawMessage(`"ok"`),
+			}),
+			func(t *testing.T, r *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, r.Code)
+				assert.Equal(t, `{"valid":true,"errors":[]}`, r.Body.String())
+			},
+		},
+
+		"POST, response code is not specified": {
+			newValidatePayloadRequest(validatePayloadRequest{
+				Interaction: "http POST /cats/{id}",
+				Target:      targetResponseBody,
+				Payload:     json.RawMessage(`"ok"`),
+			}),
+			func(t *testing.T, r *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, r.Code)
+				assert.Equal(t, `{"Status":"Error","Message":"you must specify the \"code\" parameter","Line":0,"Index":0,"Code":30002,"Layer":"server"}`, r.Body.String())
+			},
+		},
+
+		"POST, response is not described": {
+			newValidatePayloadRequest(validatePayloadRequest{
+				Interaction: "http POST /cats/{id}",
+				Target:      targetResponseHeaders,
+				Code:        "200",
+				Payload:     json.RawMessage(`{}`),
+			}),
+			func(t *testing.T, r *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, r.Code)
+				assert.Equal(t, `{"Status":"Error","Message":"the \"response.headers\" target of the response 200 is not described in the interaction","Line":0,"Index":0,"Code":30014,"Layer":"server"}`, r.Body.String())
+			},
+		},
+
+		"POST, unknown interaction": {
+			newValidatePayloadRequest(validatePayloadRequest{
+				Interaction: "http GET /dogs",
+				Target:      targetRequestBody,
+				Payload:     json.RawMessage(`{}`),
+			}),
+			func(t *testing.T, r *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, r.Code)
+				assert.Equal(t, `{"Status":"Error","Message":"the interaction \"http GET /dogs\" not found","Line":0,"Index":0,"Code":30013,"Layer":"server"}`, r.Body.String())
+			},
+		},
+
+		"POST, unknown target": {
+			newValidatePayloadRequest(validatePayloadRequest{
+				Interaction: "http POST /cats/{id}",
+				Target:      "foo",
+				Payload:     json.RawMessage(`{}`),
+			}),
+			func(t *testing.T, r *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, r.Code)
+				assert.Equal(t, `{"Status":"Error","Message":"unknown target \"foo\"","Line":0,"Index":0,"Code":30014,"Layer":"server"}`, r.Body.String())
+			},
+		},
+
+		"POST, invalid JSight": {
+			func(t *testing.T) *http.Request {
+				r, err := http.NewRequest(http.MethodPost, "/", strings.NewReader(`{"jsight": "JSIGHT 0.3\n\nGET /cats\n  200 @cat\n"}`))
+				require.NoError(t, err)
+				return r
+			},
+			func(t *testing.T, r *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, r.Code)
+				assert.Equal(t, `{"Status":"Error","Message":"Type \"@cat\" not found","Line":4,"Index":24,"Code":1302,"Layer":"schema","File":"root","Column":3}`, r.Body.String())
+			},
+		},
+
+		"POST, invalid request": {
+			func(t *testing.T) *http.Request {
+				r, err := http.NewRequest(http.MethodPost, "/", strings.NewReader(`JSIGHT 0.3`))
+				require.NoError(t, err)
+				return r
+			},
+			func(t *testing.T, r *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, r.Code)
+				assert.Equal(t, `{"Status":"Error","Message":"invalid request: invalid character 'J' looking for beginning of value","Line":0,"Index":0,"Code":30005,"Layer":"server"}`, r.Body.String())
+			},
+		},
+	}
+
+	appendUnhandledMethod(cc)
+	assertHandler(t, validatePayload, cc)
+}
+
+func newValidatePayloadRequest(req validatePayloadRequest) func(*testing.T) *http.Request {
+	return func(t *testing.T) *http.Request {
+		req.JSight = testValidatorAPI
+
+		b, err := json.Marshal(req)
+		require.NoError(t, err)
+
+		r, err := http.NewRequest(http.MethodPost, "/", strings.NewReader(string(b)))
+		require.NoError(t, err)
+		return r
+	}
+}